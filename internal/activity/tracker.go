@@ -0,0 +1,122 @@
+package activity
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+const DefaultFlushInterval = time.Minute
+
+type membership struct {
+	userID  uuid.UUID
+	groupID uuid.UUID
+}
+
+// Tracker collects member activity in memory and writes it to
+// users_groups.last_seen_at in batches, so reads don't cost a write each.
+// A nil Tracker ignores activity.
+type Tracker struct {
+	queries *database.Queries
+	mu      sync.Mutex
+	pending map[membership]time.Time
+	stopped chan struct{} // Closed after the final flush
+}
+
+func NewTracker(db *sql.DB) *Tracker {
+	return &Tracker{
+		queries: database.New(db),
+		pending: make(map[membership]time.Time),
+	}
+}
+
+// Touch records that the user was active in the group just now
+func (t *Tracker) Touch(userID, groupID uuid.UUID) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.pending[membership{userID: userID, groupID: groupID}] = time.Now()
+	t.mu.Unlock()
+}
+
+// Flush writes all pending activity in one statement
+func (t *Tracker) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[membership]time.Time, len(batch))
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	params := database.TouchMembershipsParams{
+		UserIds:  make([]uuid.UUID, 0, len(batch)),
+		GroupIds: make([]uuid.UUID, 0, len(batch)),
+		SeenUnix: make([]int64, 0, len(batch)),
+	}
+	for m, seen := range batch {
+		params.UserIds = append(params.UserIds, m.userID)
+		params.GroupIds = append(params.GroupIds, m.groupID)
+		params.SeenUnix = append(params.SeenUnix, seen.Unix())
+	}
+
+	if err := t.queries.TouchMemberships(ctx, params); err != nil {
+		// Put the batch back unless newer activity has replaced it
+		t.mu.Lock()
+		for m, seen := range batch {
+			if _, ok := t.pending[m]; !ok {
+				t.pending[m] = seen
+			}
+		}
+		t.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Start flushes pending activity every interval until ctx is cancelled,
+// with a final flush on the way out
+func (t *Tracker) Start(ctx context.Context, interval time.Duration) {
+	t.stopped = make(chan struct{})
+	go func() {
+		defer close(t.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := t.Flush(flushCtx); err != nil {
+					log.Printf("activity: error flushing last seen times: %v", err)
+				}
+				cancel()
+				return
+			case <-ticker.C:
+				if err := t.Flush(ctx); err != nil {
+					log.Printf("activity: error flushing last seen times: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until the final flush has run after Start's context was cancelled
+func (t *Tracker) Wait() {
+	if t != nil && t.stopped != nil {
+		<-t.stopped
+	}
+}
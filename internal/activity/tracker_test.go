@@ -0,0 +1,74 @@
+package activity
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+
+	// Tracking is optional, a nil tracker must be safe to use
+	tracker.Touch(uuid.New(), uuid.New())
+	if err := tracker.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() on nil tracker = %v", err)
+	}
+	tracker.Wait()
+}
+
+func TestTrackerFlushesOnStop(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	queries := database.New(db)
+
+	user, err := queries.CreateUser(ctx, database.CreateUserParams{
+		Username:       "tracked",
+		Email:          "tracked@example.com",
+		HashedPassword: "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := queries.CreateGroup(ctx, database.CreateGroupParams{
+		Name:       "Tracked group",
+		OwnerID:    uuid.NullUUID{UUID: user.ID, Valid: true},
+		InviteCode: "TRACKED1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = queries.AddUserToGroup(ctx, database.AddUserToGroupParams{
+		UserID:  user.ID,
+		GroupID: group.ID,
+		Role:    "member",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The interval never fires, only the final flush can write the visit
+	trackerCtx, stop := context.WithCancel(ctx)
+	tracker := NewTracker(db)
+	tracker.Start(trackerCtx, time.Hour)
+	tracker.Touch(user.ID, group.ID)
+	stop()
+	tracker.Wait()
+
+	var lastSeen sql.NullTime
+	err = db.QueryRow(
+		"SELECT last_seen_at FROM users_groups WHERE user_id = $1 AND group_id = $2",
+		user.ID, group.ID,
+	).Scan(&lastSeen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastSeen.Valid || time.Since(lastSeen.Time) > time.Minute {
+		t.Errorf("last_seen_at = %v, want the visit recorded by the final flush", lastSeen)
+	}
+}
@@ -14,30 +14,135 @@ import (
 
 const banUser = `-- name: BanUser :exec
 UPDATE users_groups
-SET is_banned = TRUE, kicked_until = NULL, modded_reason = $3,
-    modded_at = NOW(), modded_by = $4
+SET is_banned = TRUE, kicked_until = NULL, ban_reason = $3,
+    banned_at = NOW(), banned_by = $4
 WHERE user_id = $1 AND group_id = $2
 `
 
 type BanUserParams struct {
-	UserID       uuid.UUID
-	GroupID      uuid.UUID
-	ModdedReason string
-	ModdedBy     uuid.NullUUID
+	UserID    uuid.UUID
+	GroupID   uuid.UUID
+	BanReason string
+	BannedBy  uuid.NullUUID
 }
 
 func (q *Queries) BanUser(ctx context.Context, arg BanUserParams) error {
 	_, err := q.db.ExecContext(ctx, banUser,
 		arg.UserID,
 		arg.GroupID,
-		arg.ModdedReason,
-		arg.ModdedBy,
+		arg.BanReason,
+		arg.BannedBy,
 	)
 	return err
 }
 
+const disableSecondApproval = `-- name: DisableSecondApproval :exec
+UPDATE groups
+SET require_second_approval = FALSE, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DisableSecondApproval(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, disableSecondApproval, id)
+	return err
+}
+
+const expireKicks = `-- name: ExpireKicks :execrows
+UPDATE users_groups
+SET is_kicked = FALSE, kicked_until = NULL,
+    kick_reason = kick_reason || ' - Kick has expired'
+WHERE is_kicked AND kicked_until <= NOW()
+`
+
+func (q *Queries) ExpireKicks(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireKicks)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const expireMutes = `-- name: ExpireMutes :execrows
+UPDATE users_groups
+SET is_muted = FALSE, muted_until = NULL,
+    mute_reason = mute_reason || ' - Mute has expired'
+WHERE is_muted AND muted_until <= NOW()
+`
+
+func (q *Queries) ExpireMutes(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireMutes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getBannedMembers = `-- name: GetBannedMembers :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    users_groups.role,
+    users_groups.ban_reason AS reason,
+    users_groups.banned_at AS moderated_at,
+    users_groups.banned_by AS moderated_by,
+    COALESCE(moderators.username, '')::TEXT AS moderator_username,
+    users_groups.kicked_until
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN users AS moderators ON moderators.id = users_groups.banned_by
+WHERE users_groups.group_id = $1
+AND users_groups.is_banned
+ORDER BY users_groups.banned_at DESC
+`
+
+type GetBannedMembersRow struct {
+	ID                uuid.UUID
+	Username          string
+	Email             string
+	Role              string
+	Reason            string
+	ModeratedAt       sql.NullTime
+	ModeratedBy       uuid.NullUUID
+	ModeratorUsername string
+	KickedUntil       sql.NullTime
+}
+
+func (q *Queries) GetBannedMembers(ctx context.Context, groupID uuid.UUID) ([]GetBannedMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBannedMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBannedMembersRow
+	for rows.Next() {
+		var i GetBannedMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.Role,
+			&i.Reason,
+			&i.ModeratedAt,
+			&i.ModeratedBy,
+			&i.ModeratorUsername,
+			&i.KickedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getKickBanStatus = `-- name: GetKickBanStatus :one
-SELECT is_banned, is_kicked, kicked_until
+SELECT is_banned, is_kicked, kicked_until, is_muted, muted_until
 FROM users_groups
 WHERE user_id = $1 AND group_id = $2
 `
@@ -51,35 +156,203 @@ type GetKickBanStatusRow struct {
 	IsBanned    bool
 	IsKicked    bool
 	KickedUntil sql.NullTime
+	IsMuted     bool
+	MutedUntil  sql.NullTime
 }
 
 func (q *Queries) GetKickBanStatus(ctx context.Context, arg GetKickBanStatusParams) (GetKickBanStatusRow, error) {
 	row := q.db.QueryRowContext(ctx, getKickBanStatus, arg.UserID, arg.GroupID)
 	var i GetKickBanStatusRow
-	err := row.Scan(&i.IsBanned, &i.IsKicked, &i.KickedUntil)
+	err := row.Scan(
+		&i.IsBanned,
+		&i.IsKicked,
+		&i.KickedUntil,
+		&i.IsMuted,
+		&i.MutedUntil,
+	)
 	return i, err
 }
 
+const getKickedMembers = `-- name: GetKickedMembers :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    users_groups.role,
+    users_groups.kick_reason AS reason,
+    users_groups.kicked_at AS moderated_at,
+    users_groups.kicked_by AS moderated_by,
+    COALESCE(moderators.username, '')::TEXT AS moderator_username,
+    users_groups.kicked_until
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN users AS moderators ON moderators.id = users_groups.kicked_by
+WHERE users_groups.group_id = $1
+AND users_groups.is_kicked
+AND users_groups.kicked_until > NOW()
+AND NOT users_groups.is_banned
+ORDER BY users_groups.kicked_until ASC
+`
+
+type GetKickedMembersRow struct {
+	ID                uuid.UUID
+	Username          string
+	Email             string
+	Role              string
+	Reason            string
+	ModeratedAt       sql.NullTime
+	ModeratedBy       uuid.NullUUID
+	ModeratorUsername string
+	KickedUntil       sql.NullTime
+}
+
+func (q *Queries) GetKickedMembers(ctx context.Context, groupID uuid.UUID) ([]GetKickedMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getKickedMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetKickedMembersRow
+	for rows.Next() {
+		var i GetKickedMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.Role,
+			&i.Reason,
+			&i.ModeratedAt,
+			&i.ModeratedBy,
+			&i.ModeratorUsername,
+			&i.KickedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMutedMembers = `-- name: GetMutedMembers :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    users_groups.role,
+    users_groups.mute_reason AS reason,
+    users_groups.muted_at AS moderated_at,
+    users_groups.muted_by AS moderated_by,
+    COALESCE(moderators.username, '')::TEXT AS moderator_username,
+    users_groups.muted_until
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN users AS moderators ON moderators.id = users_groups.muted_by
+WHERE users_groups.group_id = $1
+AND users_groups.is_muted
+AND users_groups.muted_until > NOW()
+AND NOT users_groups.is_banned
+ORDER BY users_groups.muted_until ASC
+`
+
+type GetMutedMembersRow struct {
+	ID                uuid.UUID
+	Username          string
+	Email             string
+	Role              string
+	Reason            string
+	ModeratedAt       sql.NullTime
+	ModeratedBy       uuid.NullUUID
+	ModeratorUsername string
+	MutedUntil        sql.NullTime
+}
+
+func (q *Queries) GetMutedMembers(ctx context.Context, groupID uuid.UUID) ([]GetMutedMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMutedMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMutedMembersRow
+	for rows.Next() {
+		var i GetMutedMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.Role,
+			&i.Reason,
+			&i.ModeratedAt,
+			&i.ModeratedBy,
+			&i.ModeratorUsername,
+			&i.MutedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const kickUser = `-- name: KickUser :exec
 UPDATE users_groups
-SET is_kicked = TRUE, kicked_until = NOW() + INTERVAL '7 days', modded_reason = $3,
-    modded_at = NOW(), modded_by = $4
+SET is_kicked = TRUE, kicked_until = $3, kick_reason = $4,
+    kicked_at = NOW(), kicked_by = $5
 WHERE user_id = $1 AND group_id = $2
 `
 
 type KickUserParams struct {
-	UserID       uuid.UUID
-	GroupID      uuid.UUID
-	ModdedReason string
-	ModdedBy     uuid.NullUUID
+	UserID      uuid.UUID
+	GroupID     uuid.UUID
+	KickedUntil sql.NullTime
+	KickReason  string
+	KickedBy    uuid.NullUUID
 }
 
 func (q *Queries) KickUser(ctx context.Context, arg KickUserParams) error {
 	_, err := q.db.ExecContext(ctx, kickUser,
 		arg.UserID,
 		arg.GroupID,
-		arg.ModdedReason,
-		arg.ModdedBy,
+		arg.KickedUntil,
+		arg.KickReason,
+		arg.KickedBy,
+	)
+	return err
+}
+
+const muteUser = `-- name: MuteUser :exec
+UPDATE users_groups
+SET is_muted = TRUE, muted_until = $3, mute_reason = $4,
+    muted_at = NOW(), muted_by = $5
+WHERE user_id = $1 AND group_id = $2
+`
+
+type MuteUserParams struct {
+	UserID     uuid.UUID
+	GroupID    uuid.UUID
+	MutedUntil sql.NullTime
+	MuteReason string
+	MutedBy    uuid.NullUUID
+}
+
+func (q *Queries) MuteUser(ctx context.Context, arg MuteUserParams) error {
+	_, err := q.db.ExecContext(ctx, muteUser,
+		arg.UserID,
+		arg.GroupID,
+		arg.MutedUntil,
+		arg.MuteReason,
+		arg.MutedBy,
 	)
 	return err
 }
@@ -102,8 +375,8 @@ func (q *Queries) RemovePostsByUser(ctx context.Context, arg RemovePostsByUserPa
 
 const resetKickStatus = `-- name: ResetKickStatus :exec
 UPDATE users_groups
-SET is_kicked = FALSE, kicked_until = NULL, modded_at = NOW(),
-    modded_reason = modded_reason || ' - Kick has expired'
+SET is_kicked = FALSE, kicked_until = NULL,
+    kick_reason = kick_reason || ' - Kick has expired'
 WHERE user_id = $1 AND group_id = $2
 `
 
@@ -117,21 +390,72 @@ func (q *Queries) ResetKickStatus(ctx context.Context, arg ResetKickStatusParams
 	return err
 }
 
+const resetMuteStatus = `-- name: ResetMuteStatus :exec
+UPDATE users_groups
+SET is_muted = FALSE, muted_until = NULL,
+    mute_reason = mute_reason || ' - Mute has expired'
+WHERE user_id = $1 AND group_id = $2
+`
+
+type ResetMuteStatusParams struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
+}
+
+func (q *Queries) ResetMuteStatus(ctx context.Context, arg ResetMuteStatusParams) error {
+	_, err := q.db.ExecContext(ctx, resetMuteStatus, arg.UserID, arg.GroupID)
+	return err
+}
+
 const unbanUser = `-- name: UnbanUser :exec
 UPDATE users_groups
-SET is_banned = FALSE, kicked_until = NULL, modded_reason = '',
-    modded_at = NOW(), modded_by = $3
+SET is_banned = FALSE, ban_reason = '', banned_at = NULL, banned_by = NULL,
+    is_kicked = FALSE, kicked_until = NULL, kick_reason = '', kicked_at = NULL, kicked_by = NULL
 WHERE user_id = $1 AND group_id = $2
 `
 
 type UnbanUserParams struct {
-	UserID   uuid.UUID
-	GroupID  uuid.UUID
-	ModdedBy uuid.NullUUID
+	UserID  uuid.UUID
+	GroupID uuid.UUID
 }
 
+// Also clears any kick so the user is fully restored, mutes are kept
 func (q *Queries) UnbanUser(ctx context.Context, arg UnbanUserParams) error {
-	_, err := q.db.ExecContext(ctx, unbanUser, arg.UserID, arg.GroupID, arg.ModdedBy)
+	_, err := q.db.ExecContext(ctx, unbanUser, arg.UserID, arg.GroupID)
+	return err
+}
+
+const unkickUser = `-- name: UnkickUser :exec
+UPDATE users_groups
+SET is_kicked = FALSE, kicked_until = NULL, kick_reason = '',
+    kicked_at = NULL, kicked_by = NULL
+WHERE user_id = $1 AND group_id = $2
+`
+
+type UnkickUserParams struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
+}
+
+func (q *Queries) UnkickUser(ctx context.Context, arg UnkickUserParams) error {
+	_, err := q.db.ExecContext(ctx, unkickUser, arg.UserID, arg.GroupID)
+	return err
+}
+
+const unmuteUser = `-- name: UnmuteUser :exec
+UPDATE users_groups
+SET is_muted = FALSE, muted_until = NULL, mute_reason = '',
+    muted_at = NULL, muted_by = NULL
+WHERE user_id = $1 AND group_id = $2
+`
+
+type UnmuteUserParams struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
+}
+
+func (q *Queries) UnmuteUser(ctx context.Context, arg UnmuteUserParams) error {
+	_, err := q.db.ExecContext(ctx, unmuteUser, arg.UserID, arg.GroupID)
 	return err
 }
 
@@ -182,3 +506,72 @@ func (q *Queries) UpdateGroupRules(ctx context.Context, arg UpdateGroupRulesPara
 	_, err := q.db.ExecContext(ctx, updateGroupRules, arg.ID, arg.RulesInfo)
 	return err
 }
+
+const updateGroupSettings = `-- name: UpdateGroupSettings :one
+UPDATE groups
+SET name = $2, description = $3, avatar_url = $4, rules_info = $5,
+    post_policy = $6, join_mode = $7, is_listed = $8,
+    require_second_approval = $9, require_rules_acceptance = $10,
+    edit_window_minutes = $11, allow_anonymous = $12,
+    auto_archive_days = $13, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, description, created_at, updated_at, owner_id, invite_code, rules_info, avatar_url, post_policy, join_mode, is_listed, organization_id, deleted_at, deleted_by, require_second_approval, require_rules_acceptance, edit_window_minutes, allow_anonymous, auto_archive_days
+`
+
+type UpdateGroupSettingsParams struct {
+	ID                     uuid.UUID
+	Name                   string
+	Description            sql.NullString
+	AvatarUrl              string
+	RulesInfo              string
+	PostPolicy             string
+	JoinMode               string
+	IsListed               bool
+	RequireSecondApproval  bool
+	RequireRulesAcceptance bool
+	EditWindowMinutes      int32
+	AllowAnonymous         bool
+	AutoArchiveDays        int32
+}
+
+func (q *Queries) UpdateGroupSettings(ctx context.Context, arg UpdateGroupSettingsParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, updateGroupSettings,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.AvatarUrl,
+		arg.RulesInfo,
+		arg.PostPolicy,
+		arg.JoinMode,
+		arg.IsListed,
+		arg.RequireSecondApproval,
+		arg.RequireRulesAcceptance,
+		arg.EditWindowMinutes,
+		arg.AllowAnonymous,
+		arg.AutoArchiveDays,
+	)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.InviteCode,
+		&i.RulesInfo,
+		&i.AvatarUrl,
+		&i.PostPolicy,
+		&i.JoinMode,
+		&i.IsListed,
+		&i.OrganizationID,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.RequireSecondApproval,
+		&i.RequireRulesAcceptance,
+		&i.EditWindowMinutes,
+		&i.AllowAnonymous,
+		&i.AutoArchiveDays,
+	)
+	return i, err
+}
@@ -0,0 +1,167 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: categories.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createGroupCategory = `-- name: CreateGroupCategory :execrows
+INSERT INTO group_categories (group_id, name, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type CreateGroupCategoryParams struct {
+	GroupID   uuid.UUID
+	Name      string
+	CreatedBy uuid.NullUUID
+}
+
+func (q *Queries) CreateGroupCategory(ctx context.Context, arg CreateGroupCategoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createGroupCategory, arg.GroupID, arg.Name, arg.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteGroupCategory = `-- name: DeleteGroupCategory :execrows
+DELETE FROM group_categories
+WHERE group_id = $1 AND name = $2
+`
+
+type DeleteGroupCategoryParams struct {
+	GroupID uuid.UUID
+	Name    string
+}
+
+func (q *Queries) DeleteGroupCategory(ctx context.Context, arg DeleteGroupCategoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteGroupCategory, arg.GroupID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getGroupCategories = `-- name: GetGroupCategories :many
+SELECT name, created_at
+FROM group_categories
+WHERE group_id = $1
+ORDER BY name ASC
+`
+
+type GetGroupCategoriesRow struct {
+	Name      string
+	CreatedAt sql.NullTime
+}
+
+func (q *Queries) GetGroupCategories(ctx context.Context, groupID uuid.UUID) ([]GetGroupCategoriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupCategories, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGroupCategoriesRow
+	for rows.Next() {
+		var i GetGroupCategoriesRow
+		if err := rows.Scan(&i.Name, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostCountsByCategory = `-- name: GetPostCountsByCategory :many
+SELECT posts.category, COUNT(*) AS post_count
+FROM posts
+WHERE posts.group_id = $1
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND post_visible_to(posts.id, $2, $3)
+GROUP BY posts.category
+ORDER BY category ASC
+`
+
+type GetPostCountsByCategoryParams struct {
+	GroupID        uuid.UUID
+	ViewerID       uuid.UUID
+	ViewerIsLeader bool
+}
+
+type GetPostCountsByCategoryRow struct {
+	Category  string
+	PostCount int64
+}
+
+func (q *Queries) GetPostCountsByCategory(ctx context.Context, arg GetPostCountsByCategoryParams) ([]GetPostCountsByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostCountsByCategory, arg.GroupID, arg.ViewerID, arg.ViewerIsLeader)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostCountsByCategoryRow
+	for rows.Next() {
+		var i GetPostCountsByCategoryRow
+		if err := rows.Scan(&i.Category, &i.PostCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const groupCategoryExists = `-- name: GroupCategoryExists :one
+SELECT EXISTS (
+    SELECT 1 FROM group_categories
+    WHERE group_id = $1 AND name = $2
+)
+`
+
+type GroupCategoryExistsParams struct {
+	GroupID uuid.UUID
+	Name    string
+}
+
+func (q *Queries) GroupCategoryExists(ctx context.Context, arg GroupCategoryExistsParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, groupCategoryExists, arg.GroupID, arg.Name)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const resetPostCategory = `-- name: ResetPostCategory :exec
+UPDATE posts
+SET category = 'prayer_request'
+WHERE group_id = $1 AND category = $2
+`
+
+type ResetPostCategoryParams struct {
+	GroupID  uuid.UUID
+	Category string
+}
+
+func (q *Queries) ResetPostCategory(ctx context.Context, arg ResetPostCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, resetPostCategory, arg.GroupID, arg.Category)
+	return err
+}
@@ -10,11 +10,12 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const addUserToGroup = `-- name: AddUserToGroup :exec
-INSERT INTO users_groups (user_id, group_id, role)
-VALUES ($1, $2, $3)
+INSERT INTO users_groups (user_id, group_id, role, joined_at)
+VALUES ($1, $2, $3, NOW())
 ON CONFLICT DO NOTHING
 `
 
@@ -32,7 +33,7 @@ func (q *Queries) AddUserToGroup(ctx context.Context, arg AddUserToGroupParams)
 const createGroup = `-- name: CreateGroup :one
 INSERT INTO groups (name, description, owner_id, invite_code)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, description, created_at, updated_at, owner_id, invite_code, rules_info
+RETURNING id, name, description, created_at, updated_at, owner_id, invite_code, rules_info, avatar_url, post_policy, join_mode, is_listed, organization_id, deleted_at, deleted_by, require_second_approval, require_rules_acceptance, edit_window_minutes, allow_anonymous, auto_archive_days
 `
 
 type CreateGroupParams struct {
@@ -59,10 +60,38 @@ func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) (Group
 		&i.OwnerID,
 		&i.InviteCode,
 		&i.RulesInfo,
+		&i.AvatarUrl,
+		&i.PostPolicy,
+		&i.JoinMode,
+		&i.IsListed,
+		&i.OrganizationID,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.RequireSecondApproval,
+		&i.RequireRulesAcceptance,
+		&i.EditWindowMinutes,
+		&i.AllowAnonymous,
+		&i.AutoArchiveDays,
 	)
 	return i, err
 }
 
+const createJoinRequest = `-- name: CreateJoinRequest :exec
+INSERT INTO group_join_requests (group_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type CreateJoinRequestParams struct {
+	GroupID uuid.UUID
+	UserID  uuid.UUID
+}
+
+func (q *Queries) CreateJoinRequest(ctx context.Context, arg CreateJoinRequestParams) error {
+	_, err := q.db.ExecContext(ctx, createJoinRequest, arg.GroupID, arg.UserID)
+	return err
+}
+
 const deleteGroup = `-- name: DeleteGroup :exec
 DELETE FROM groups
 WHERE id = $1
@@ -73,24 +102,50 @@ func (q *Queries) DeleteGroup(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteJoinRequest = `-- name: DeleteJoinRequest :execrows
+DELETE FROM group_join_requests
+WHERE group_id = $1 AND user_id = $2
+`
+
+type DeleteJoinRequestParams struct {
+	GroupID uuid.UUID
+	UserID  uuid.UUID
+}
+
+func (q *Queries) DeleteJoinRequest(ctx context.Context, arg DeleteJoinRequestParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteJoinRequest, arg.GroupID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getActiveMembers = `-- name: GetActiveMembers :many
 SELECT
     users.id,
     users.username,
     users.email,
-    users_groups.role
+    users.email_visibility,
+    users.hide_from_directory,
+    users_groups.role,
+    users_groups.joined_at,
+    users_groups.last_seen_at
 FROM users_groups
 JOIN users ON users.id = users_groups.user_id
 WHERE users_groups.group_id = $1
 AND NOT users_groups.is_banned
-AND NOT users_groups.is_kicked
+AND (NOT users_groups.is_kicked OR users_groups.kicked_until <= NOW())
 `
 
 type GetActiveMembersRow struct {
-	ID       uuid.UUID
-	Username string
-	Email    string
-	Role     string
+	ID                uuid.UUID
+	Username          string
+	Email             string
+	EmailVisibility   string
+	HideFromDirectory bool
+	Role              string
+	JoinedAt          sql.NullTime
+	LastSeenAt        sql.NullTime
 }
 
 func (q *Queries) GetActiveMembers(ctx context.Context, groupID uuid.UUID) ([]GetActiveMembersRow, error) {
@@ -106,7 +161,60 @@ func (q *Queries) GetActiveMembers(ctx context.Context, groupID uuid.UUID) ([]Ge
 			&i.ID,
 			&i.Username,
 			&i.Email,
+			&i.EmailVisibility,
+			&i.HideFromDirectory,
 			&i.Role,
+			&i.JoinedAt,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedGroupsForOwner = `-- name: GetDeletedGroupsForOwner :many
+SELECT id, name, description, deleted_at
+FROM groups
+WHERE (owner_id = $1 OR (owner_id IS NULL AND is_group_leader($1, id)))
+AND deleted_at > $2
+ORDER BY deleted_at DESC
+`
+
+type GetDeletedGroupsForOwnerParams struct {
+	OwnerID   uuid.NullUUID
+	DeletedAt sql.NullTime
+}
+
+type GetDeletedGroupsForOwnerRow struct {
+	ID          uuid.UUID
+	Name        string
+	Description sql.NullString
+	DeletedAt   sql.NullTime
+}
+
+// Groups without an owner can be restored by their leaders
+func (q *Queries) GetDeletedGroupsForOwner(ctx context.Context, arg GetDeletedGroupsForOwnerParams) ([]GetDeletedGroupsForOwnerRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedGroupsForOwner, arg.OwnerID, arg.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDeletedGroupsForOwnerRow
+	for rows.Next() {
+		var i GetDeletedGroupsForOwnerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -122,7 +230,7 @@ func (q *Queries) GetActiveMembers(ctx context.Context, groupID uuid.UUID) ([]Ge
 }
 
 const getGroupByID = `-- name: GetGroupByID :one
-SELECT id, name, description, created_at, updated_at, owner_id, invite_code, rules_info
+SELECT id, name, description, created_at, updated_at, owner_id, invite_code, rules_info, avatar_url, post_policy, join_mode, is_listed, organization_id, deleted_at, deleted_by, require_second_approval, require_rules_acceptance, edit_window_minutes, allow_anonymous, auto_archive_days
 FROM groups
 WHERE id = $1
 `
@@ -139,13 +247,25 @@ func (q *Queries) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error)
 		&i.OwnerID,
 		&i.InviteCode,
 		&i.RulesInfo,
+		&i.AvatarUrl,
+		&i.PostPolicy,
+		&i.JoinMode,
+		&i.IsListed,
+		&i.OrganizationID,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.RequireSecondApproval,
+		&i.RequireRulesAcceptance,
+		&i.EditWindowMinutes,
+		&i.AllowAnonymous,
+		&i.AutoArchiveDays,
 	)
 	return i, err
 }
 
 const getGroupByInviteCode = `-- name: GetGroupByInviteCode :one
-SELECT id, name, description, created_at, updated_at, owner_id, invite_code, rules_info FROM groups
-WHERE invite_code = $1
+SELECT id, name, description, created_at, updated_at, owner_id, invite_code, rules_info, avatar_url, post_policy, join_mode, is_listed, organization_id, deleted_at, deleted_by, require_second_approval, require_rules_acceptance, edit_window_minutes, allow_anonymous, auto_archive_days FROM groups
+WHERE invite_code = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetGroupByInviteCode(ctx context.Context, inviteCode string) (Group, error) {
@@ -160,14 +280,28 @@ func (q *Queries) GetGroupByInviteCode(ctx context.Context, inviteCode string) (
 		&i.OwnerID,
 		&i.InviteCode,
 		&i.RulesInfo,
+		&i.AvatarUrl,
+		&i.PostPolicy,
+		&i.JoinMode,
+		&i.IsListed,
+		&i.OrganizationID,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.RequireSecondApproval,
+		&i.RequireRulesAcceptance,
+		&i.EditWindowMinutes,
+		&i.AllowAnonymous,
+		&i.AutoArchiveDays,
 	)
 	return i, err
 }
 
 const getGroupMembersIDs = `-- name: GetGroupMembersIDs :many
-SELECT user_id
+SELECT users_groups.user_id
 FROM users_groups
-WHERE group_id = $1
+JOIN groups ON groups.id = users_groups.group_id
+WHERE users_groups.group_id = $1
+AND groups.deleted_at IS NULL
 `
 
 func (q *Queries) GetGroupMembersIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
@@ -232,8 +366,9 @@ SELECT groups.id, groups.name, groups.description, groups.owner_id
 FROM users_groups
 JOIN groups ON users_groups.group_id = groups.id
 WHERE users_groups.user_id = $1
+AND groups.deleted_at IS NULL
 AND users_groups.is_banned = false
-AND users_groups.is_kicked = false
+AND (users_groups.is_kicked = false OR users_groups.kicked_until <= NOW())
 `
 
 type GetGroupsForUserRow struct {
@@ -271,6 +406,151 @@ func (q *Queries) GetGroupsForUser(ctx context.Context, userID uuid.UUID) ([]Get
 	return items, nil
 }
 
+const getJoinRequests = `-- name: GetJoinRequests :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    group_join_requests.created_at
+FROM group_join_requests
+JOIN users ON users.id = group_join_requests.user_id
+WHERE group_join_requests.group_id = $1
+ORDER BY group_join_requests.created_at ASC
+`
+
+type GetJoinRequestsRow struct {
+	ID        uuid.UUID
+	Username  string
+	Email     string
+	CreatedAt sql.NullTime
+}
+
+func (q *Queries) GetJoinRequests(ctx context.Context, groupID uuid.UUID) ([]GetJoinRequestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getJoinRequests, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetJoinRequestsRow
+	for rows.Next() {
+		var i GetJoinRequestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isGroupLeader = `-- name: IsGroupLeader :one
+SELECT is_group_leader($1::UUID, $2::UUID)::BOOLEAN AS is_leader
+`
+
+type IsGroupLeaderParams struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
+}
+
+// Unlike GetUserGroupRole this still answers for deleted groups
+func (q *Queries) IsGroupLeader(ctx context.Context, arg IsGroupLeaderParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isGroupLeader, arg.UserID, arg.GroupID)
+	var is_leader bool
+	err := row.Scan(&is_leader)
+	return is_leader, err
+}
+
+const listListedGroups = `-- name: ListListedGroups :many
+SELECT
+    groups.id,
+    groups.name,
+    groups.description,
+    groups.avatar_url,
+    groups.invite_code,
+    groups.join_mode,
+    (
+        SELECT COUNT(*)
+        FROM users_groups
+        WHERE users_groups.group_id = groups.id
+        AND NOT users_groups.is_banned
+        AND NOT users_groups.is_kicked
+    ) AS member_count
+FROM groups
+WHERE groups.is_listed
+AND groups.deleted_at IS NULL
+ORDER BY member_count DESC, groups.name ASC
+LIMIT $1 OFFSET $2
+`
+
+type ListListedGroupsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type ListListedGroupsRow struct {
+	ID          uuid.UUID
+	Name        string
+	Description sql.NullString
+	AvatarUrl   string
+	InviteCode  string
+	JoinMode    string
+	MemberCount int64
+}
+
+func (q *Queries) ListListedGroups(ctx context.Context, arg ListListedGroupsParams) ([]ListListedGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listListedGroups, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListListedGroupsRow
+	for rows.Next() {
+		var i ListListedGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.AvatarUrl,
+			&i.InviteCode,
+			&i.JoinMode,
+			&i.MemberCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedGroups = `-- name: PurgeDeletedGroups :execrows
+DELETE FROM groups
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedGroups(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedGroups, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const removeUserFromGroup = `-- name: RemoveUserFromGroup :exec
 DELETE FROM users_groups
 WHERE user_id = $1 AND group_id = $2
@@ -294,3 +574,143 @@ func (q *Queries) ResetGroups(ctx context.Context) error {
 	_, err := q.db.ExecContext(ctx, resetGroups)
 	return err
 }
+
+const restoreGroup = `-- name: RestoreGroup :execrows
+UPDATE groups
+SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at > $2
+`
+
+type RestoreGroupParams struct {
+	ID        uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) RestoreGroup(ctx context.Context, arg RestoreGroupParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreGroup, arg.ID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchListedGroups = `-- name: SearchListedGroups :many
+SELECT
+    groups.id,
+    groups.name,
+    groups.description,
+    groups.avatar_url,
+    groups.invite_code,
+    groups.join_mode,
+    (
+        SELECT COUNT(*)
+        FROM users_groups
+        WHERE users_groups.group_id = groups.id
+        AND NOT users_groups.is_banned
+        AND NOT users_groups.is_kicked
+    ) AS member_count
+FROM groups
+WHERE groups.is_listed
+AND groups.deleted_at IS NULL
+AND to_tsvector('english', groups.name || ' ' || COALESCE(groups.description, ''))
+    @@ websearch_to_tsquery('english', $1::TEXT)
+ORDER BY
+    ts_rank(
+        to_tsvector('english', groups.name || ' ' || COALESCE(groups.description, '')),
+        websearch_to_tsquery('english', $1::TEXT)
+    ) DESC,
+    member_count DESC,
+    groups.name ASC
+LIMIT $3 OFFSET $2
+`
+
+type SearchListedGroupsParams struct {
+	Query  string
+	Offset int32
+	Limit  int32
+}
+
+type SearchListedGroupsRow struct {
+	ID          uuid.UUID
+	Name        string
+	Description sql.NullString
+	AvatarUrl   string
+	InviteCode  string
+	JoinMode    string
+	MemberCount int64
+}
+
+func (q *Queries) SearchListedGroups(ctx context.Context, arg SearchListedGroupsParams) ([]SearchListedGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchListedGroups, arg.Query, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchListedGroupsRow
+	for rows.Next() {
+		var i SearchListedGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.AvatarUrl,
+			&i.InviteCode,
+			&i.JoinMode,
+			&i.MemberCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteGroup = `-- name: SoftDeleteGroup :execrows
+UPDATE groups
+SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type SoftDeleteGroupParams struct {
+	ID        uuid.UUID
+	DeletedBy uuid.NullUUID
+}
+
+func (q *Queries) SoftDeleteGroup(ctx context.Context, arg SoftDeleteGroupParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteGroup, arg.ID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchMemberships = `-- name: TouchMemberships :exec
+UPDATE users_groups
+SET last_seen_at = GREATEST(users_groups.last_seen_at, seen.seen_at)
+FROM (
+    SELECT
+        unnest($1::UUID[]) AS user_id,
+        unnest($2::UUID[]) AS group_id,
+        to_timestamp(unnest($3::BIGINT[])) AS seen_at
+) AS seen
+WHERE users_groups.user_id = seen.user_id
+AND users_groups.group_id = seen.group_id
+`
+
+type TouchMembershipsParams struct {
+	UserIds  []uuid.UUID
+	GroupIds []uuid.UUID
+	SeenUnix []int64
+}
+
+// Batched last-activity update, GREATEST ignores the NULL of a first visit
+func (q *Queries) TouchMemberships(ctx context.Context, arg TouchMembershipsParams) error {
+	_, err := q.db.ExecContext(ctx, touchMemberships, pq.Array(arg.UserIds), pq.Array(arg.GroupIds), pq.Array(arg.SeenUnix))
+	return err
+}
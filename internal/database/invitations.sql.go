@@ -0,0 +1,162 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: invitations.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const createGroupInvitation = `-- name: CreateGroupInvitation :one
+INSERT INTO group_invitations (group_id, email, name, role, invited_by, token)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (group_id, email)
+DO UPDATE SET
+    name = EXCLUDED.name,
+    role = EXCLUDED.role,
+    invited_by = EXCLUDED.invited_by,
+    token = EXCLUDED.token,
+    created_at = NOW(),
+    accepted_at = NULL
+RETURNING id, group_id, email, name, role, invited_by, created_at, accepted_at, token
+`
+
+type CreateGroupInvitationParams struct {
+	GroupID   uuid.UUID
+	Email     string
+	Name      string
+	Role      string
+	InvitedBy uuid.NullUUID
+	Token     string
+}
+
+// Inviting the same email again issues a new token
+func (q *Queries) CreateGroupInvitation(ctx context.Context, arg CreateGroupInvitationParams) (GroupInvitation, error) {
+	row := q.db.QueryRowContext(ctx, createGroupInvitation,
+		arg.GroupID,
+		arg.Email,
+		arg.Name,
+		arg.Role,
+		arg.InvitedBy,
+		arg.Token,
+	)
+	var i GroupInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Email,
+		&i.Name,
+		&i.Role,
+		&i.InvitedBy,
+		&i.CreatedAt,
+		&i.AcceptedAt,
+		&i.Token,
+	)
+	return i, err
+}
+
+const getInvitationDetails = `-- name: GetInvitationDetails :one
+SELECT
+    group_invitations.id,
+    group_invitations.email,
+    group_invitations.name,
+    group_invitations.accepted_at,
+    group_invitations.token,
+    groups.name AS group_name,
+    groups.invite_code,
+    COALESCE(users.username, '')::TEXT AS invited_by_username
+FROM group_invitations
+JOIN groups ON groups.id = group_invitations.group_id
+LEFT JOIN users ON users.id = group_invitations.invited_by
+WHERE group_invitations.id = $1
+`
+
+type GetInvitationDetailsRow struct {
+	ID                uuid.UUID
+	Email             string
+	Name              string
+	AcceptedAt        sql.NullTime
+	Token             string
+	GroupName         string
+	InviteCode        string
+	InvitedByUsername string
+}
+
+func (q *Queries) GetInvitationDetails(ctx context.Context, id uuid.UUID) (GetInvitationDetailsRow, error) {
+	row := q.db.QueryRowContext(ctx, getInvitationDetails, id)
+	var i GetInvitationDetailsRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.AcceptedAt,
+		&i.Token,
+		&i.GroupName,
+		&i.InviteCode,
+		&i.InvitedByUsername,
+	)
+	return i, err
+}
+
+const getPendingInvitationByToken = `-- name: GetPendingInvitationByToken :one
+SELECT
+    group_invitations.id, group_invitations.group_id, group_invitations.email, group_invitations.name, group_invitations.role, group_invitations.invited_by, group_invitations.created_at, group_invitations.accepted_at, group_invitations.token,
+    groups.name AS group_name
+FROM group_invitations
+JOIN groups ON groups.id = group_invitations.group_id
+WHERE group_invitations.token = $1
+AND group_invitations.accepted_at IS NULL
+AND groups.deleted_at IS NULL
+`
+
+type GetPendingInvitationByTokenRow struct {
+	ID         uuid.UUID
+	GroupID    uuid.UUID
+	Email      string
+	Name       string
+	Role       string
+	InvitedBy  uuid.NullUUID
+	CreatedAt  sql.NullTime
+	AcceptedAt sql.NullTime
+	Token      string
+	GroupName  string
+}
+
+func (q *Queries) GetPendingInvitationByToken(ctx context.Context, token string) (GetPendingInvitationByTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, getPendingInvitationByToken, token)
+	var i GetPendingInvitationByTokenRow
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Email,
+		&i.Name,
+		&i.Role,
+		&i.InvitedBy,
+		&i.CreatedAt,
+		&i.AcceptedAt,
+		&i.Token,
+		&i.GroupName,
+	)
+	return i, err
+}
+
+const markInvitationAccepted = `-- name: MarkInvitationAccepted :execrows
+UPDATE group_invitations
+SET accepted_at = NOW()
+WHERE id = $1
+AND accepted_at IS NULL
+`
+
+// Only succeeds once, so a token can't be used twice
+func (q *Queries) MarkInvitationAccepted(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markInvitationAccepted, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
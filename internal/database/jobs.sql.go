@@ -0,0 +1,262 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: jobs.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const advanceJobSchedule = `-- name: AdvanceJobSchedule :exec
+UPDATE job_schedules
+SET next_run_at = $2, last_run_at = NOW()
+WHERE name = $1
+`
+
+type AdvanceJobScheduleParams struct {
+	Name      string
+	NextRunAt time.Time
+}
+
+func (q *Queries) AdvanceJobSchedule(ctx context.Context, arg AdvanceJobScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, advanceJobSchedule, arg.Name, arg.NextRunAt)
+	return err
+}
+
+const claimDueSchedules = `-- name: ClaimDueSchedules :many
+SELECT name, kind, cron_spec, next_run_at, last_run_at
+FROM job_schedules
+WHERE next_run_at <= NOW()
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ClaimDueSchedules(ctx context.Context) ([]JobSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueSchedules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobSchedule
+	for rows.Next() {
+		var i JobSchedule
+		if err := rows.Scan(
+			&i.Name,
+			&i.Kind,
+			&i.CronSpec,
+			&i.NextRunAt,
+			&i.LastRunAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimJobs = `-- name: ClaimJobs :many
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1, locked_at = NOW(),
+    locked_by = $1, updated_at = NOW()
+WHERE id IN (
+    SELECT id
+    FROM jobs
+    WHERE status = 'pending' AND run_at <= NOW()
+    ORDER BY run_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, locked_by, last_error, created_at, updated_at
+`
+
+type ClaimJobsParams struct {
+	LockedBy string
+	Limit    int32
+}
+
+func (q *Queries) ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, claimJobs, arg.LockedBy, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedAt,
+			&i.LockedBy,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeJob = `-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'done', locked_at = NULL, last_error = '', updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) CompleteJob(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, completeJob, id)
+	return err
+}
+
+const deleteFinishedJobs = `-- name: DeleteFinishedJobs :execrows
+DELETE FROM jobs
+WHERE status IN ('done', 'failed') AND updated_at < $1
+`
+
+func (q *Queries) DeleteFinishedJobs(ctx context.Context, updatedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFinishedJobs, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const enqueueJob = `-- name: EnqueueJob :one
+INSERT INTO jobs (kind, payload, run_at, max_attempts)
+VALUES ($1, $2, $3, $4)
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, locked_by, last_error, created_at, updated_at
+`
+
+type EnqueueJobParams struct {
+	Kind        string
+	Payload     json.RawMessage
+	RunAt       time.Time
+	MaxAttempts int32
+}
+
+func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, enqueueJob,
+		arg.Kind,
+		arg.Payload,
+		arg.RunAt,
+		arg.MaxAttempts,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.LockedBy,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const failJob = `-- name: FailJob :exec
+UPDATE jobs
+SET status = 'failed', last_error = $2, locked_at = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+type FailJobParams struct {
+	ID        uuid.UUID
+	LastError string
+}
+
+func (q *Queries) FailJob(ctx context.Context, arg FailJobParams) error {
+	_, err := q.db.ExecContext(ctx, failJob, arg.ID, arg.LastError)
+	return err
+}
+
+const requeueStaleJobs = `-- name: RequeueStaleJobs :execrows
+UPDATE jobs
+SET status = 'pending', locked_at = NULL, locked_by = '', updated_at = NOW()
+WHERE status = 'running' AND locked_at < $1
+`
+
+func (q *Queries) RequeueStaleJobs(ctx context.Context, lockedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requeueStaleJobs, lockedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const retryJob = `-- name: RetryJob :exec
+UPDATE jobs
+SET status = 'pending', run_at = $2, last_error = $3, locked_at = NULL,
+    locked_by = '', updated_at = NOW()
+WHERE id = $1
+`
+
+type RetryJobParams struct {
+	ID        uuid.UUID
+	RunAt     time.Time
+	LastError string
+}
+
+func (q *Queries) RetryJob(ctx context.Context, arg RetryJobParams) error {
+	_, err := q.db.ExecContext(ctx, retryJob, arg.ID, arg.RunAt, arg.LastError)
+	return err
+}
+
+const upsertJobSchedule = `-- name: UpsertJobSchedule :exec
+INSERT INTO job_schedules (name, kind, cron_spec, next_run_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (name) DO UPDATE
+SET kind = EXCLUDED.kind,
+    cron_spec = EXCLUDED.cron_spec,
+    next_run_at = CASE
+        WHEN job_schedules.cron_spec = EXCLUDED.cron_spec THEN job_schedules.next_run_at
+        ELSE EXCLUDED.next_run_at
+    END
+`
+
+type UpsertJobScheduleParams struct {
+	Name      string
+	Kind      string
+	CronSpec  string
+	NextRunAt time.Time
+}
+
+func (q *Queries) UpsertJobSchedule(ctx context.Context, arg UpsertJobScheduleParams) error {
+	_, err := q.db.ExecContext(ctx, upsertJobSchedule,
+		arg.Name,
+		arg.Kind,
+		arg.CronSpec,
+		arg.NextRunAt,
+	)
+	return err
+}
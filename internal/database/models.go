@@ -6,31 +6,199 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Group struct {
+	ID                     uuid.UUID
+	Name                   string
+	Description            sql.NullString
+	CreatedAt              sql.NullTime
+	UpdatedAt              sql.NullTime
+	OwnerID                uuid.NullUUID
+	InviteCode             string
+	RulesInfo              string
+	AvatarUrl              string
+	PostPolicy             string
+	JoinMode               string
+	IsListed               bool
+	OrganizationID         uuid.NullUUID
+	DeletedAt              sql.NullTime
+	DeletedBy              uuid.NullUUID
+	RequireSecondApproval  bool
+	RequireRulesAcceptance bool
+	EditWindowMinutes      int32
+	AllowAnonymous         bool
+	AutoArchiveDays        int32
+}
+
+type GroupCategory struct {
+	GroupID   uuid.UUID
+	Name      string
+	CreatedBy uuid.NullUUID
+	CreatedAt sql.NullTime
+}
+
+type GroupInvitation struct {
+	ID         uuid.UUID
+	GroupID    uuid.UUID
+	Email      string
+	Name       string
+	Role       string
+	InvitedBy  uuid.NullUUID
+	CreatedAt  sql.NullTime
+	AcceptedAt sql.NullTime
+	Token      string
+}
+
+type GroupJoinRequest struct {
+	GroupID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt sql.NullTime
+}
+
+type GroupRulesAcceptance struct {
+	GroupID    uuid.UUID
+	UserID     uuid.UUID
+	Version    int32
+	AcceptedAt sql.NullTime
+}
+
+type GroupRulesVersion struct {
+	ID        uuid.UUID
+	GroupID   uuid.UUID
+	Version   int32
+	Rules     string
+	CreatedBy uuid.NullUUID
+	CreatedAt sql.NullTime
+}
+
+type Job struct {
 	ID          uuid.UUID
-	Name        string
-	Description sql.NullString
+	Kind        string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	RunAt       time.Time
+	LockedAt    sql.NullTime
+	LockedBy    string
+	LastError   string
 	CreatedAt   sql.NullTime
 	UpdatedAt   sql.NullTime
-	OwnerID     uuid.NullUUID
-	InviteCode  string
-	RulesInfo   string
 }
 
-type Post struct {
+type JobSchedule struct {
+	Name      string
+	Kind      string
+	CronSpec  string
+	NextRunAt time.Time
+	LastRunAt sql.NullTime
+}
+
+type ModerationLog struct {
+	ID           uuid.UUID
+	GroupID      uuid.UUID
+	ActorID      uuid.NullUUID
+	Action       string
+	TargetUserID uuid.NullUUID
+	TargetPostID uuid.NullUUID
+	Reason       string
+	CreatedAt    sql.NullTime
+}
+
+type Organization struct {
+	ID                uuid.UUID
+	Name              string
+	Description       string
+	OwnerID           uuid.NullUUID
+	DefaultRules      string
+	DefaultJoinMode   string
+	DefaultPostPolicy string
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+}
+
+type OrganizationMember struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+	Role           string
+	CreatedAt      sql.NullTime
+}
+
+type PendingAction struct {
 	ID           uuid.UUID
-	UserID       uuid.UUID
 	GroupID      uuid.UUID
-	Content      string
+	Action       string
+	TargetUserID uuid.NullUUID
+	RequestedBy  uuid.UUID
+	Status       string
+	ResolvedBy   uuid.NullUUID
 	CreatedAt    sql.NullTime
-	UpdatedAt    sql.NullTime
-	ParentPostID uuid.NullUUID
-	IsDeleted    sql.NullBool
+	ExpiresAt    time.Time
+	ResolvedAt   sql.NullTime
+}
+
+type Post struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	GroupID         uuid.UUID
+	Content         string
+	CreatedAt       sql.NullTime
+	UpdatedAt       sql.NullTime
+	ParentPostID    uuid.NullUUID
+	IsDeleted       sql.NullBool
+	PinnedAt        sql.NullTime
+	PinnedUntil     sql.NullTime
+	PinnedBy        uuid.NullUUID
+	PinOrder        int32
+	EditedAt        sql.NullTime
+	Category        string
+	Status          string
+	StatusChangedAt sql.NullTime
+	StatusChangedBy uuid.NullUUID
+	AnsweredAt      sql.NullTime
+	AnswerNote      string
+	IsAnonymous     bool
+	Audience        string
+	PublishAt       sql.NullTime
+	ArchivedAt      sql.NullTime
+}
+
+type PostAudience struct {
+	PostID uuid.UUID
+	UserID uuid.UUID
+}
+
+type PostRevision struct {
+	ID        uuid.UUID
+	PostID    uuid.UUID
+	Content   string
+	EditedBy  uuid.NullUUID
+	CreatedAt sql.NullTime
+}
+
+type PrayerCommitment struct {
+	ID             uuid.UUID
+	PostID         uuid.UUID
+	UserID         uuid.UUID
+	Frequency      string
+	EndsAt         time.Time
+	NextReminderAt time.Time
+	LastRemindedAt sql.NullTime
+	EndedAt        sql.NullTime
+	EndReason      string
+	CreatedAt      sql.NullTime
+}
+
+type PrayerResponse struct {
+	PostID    uuid.UUID
+	UserID    uuid.UUID
+	PrayedOn  time.Time
+	CreatedAt sql.NullTime
 }
 
 type RefreshToken struct {
@@ -43,23 +211,61 @@ type RefreshToken struct {
 }
 
 type User struct {
-	ID             uuid.UUID
-	Username       string
-	Email          string
-	CreatedAt      sql.NullTime
-	UpdatedAt      sql.NullTime
-	HashedPassword string
-	IsActive       sql.NullBool
+	ID                uuid.UUID
+	Username          string
+	Email             string
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	HashedPassword    string
+	IsActive          sql.NullBool
+	EmailVisibility   string
+	HideFromDirectory bool
 }
 
 type UsersGroup struct {
-	UserID       uuid.UUID
-	GroupID      uuid.UUID
-	Role         string
-	IsBanned     bool
-	IsKicked     bool
-	KickedUntil  sql.NullTime
-	ModdedReason string
-	ModdedAt     sql.NullTime
-	ModdedBy     uuid.NullUUID
+	UserID      uuid.UUID
+	GroupID     uuid.UUID
+	Role        string
+	IsBanned    bool
+	IsKicked    bool
+	KickedUntil sql.NullTime
+	BanReason   string
+	BannedAt    sql.NullTime
+	BannedBy    uuid.NullUUID
+	IsMuted     bool
+	MutedUntil  sql.NullTime
+	JoinedAt    sql.NullTime
+	LastSeenAt  sql.NullTime
+	KickReason  string
+	KickedAt    sql.NullTime
+	KickedBy    uuid.NullUUID
+	MuteReason  string
+	MutedAt     sql.NullTime
+	MutedBy     uuid.NullUUID
+}
+
+type Webhook struct {
+	ID        uuid.UUID
+	GroupID   uuid.UUID
+	Url       string
+	Secret    string
+	Events    []string
+	IsActive  bool
+	CreatedBy uuid.NullUUID
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	Event          string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	ResponseStatus sql.NullInt32
+	ResponseBody   string
+	LastError      string
+	CreatedAt      sql.NullTime
+	DeliveredAt    sql.NullTime
 }
@@ -0,0 +1,109 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: moderation_log.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const getModerationLog = `-- name: GetModerationLog :many
+SELECT
+    moderation_log.id,
+    moderation_log.action,
+    moderation_log.actor_id,
+    COALESCE(actors.username, '')::TEXT AS actor_username,
+    moderation_log.target_user_id,
+    COALESCE(targets.username, '')::TEXT AS target_username,
+    moderation_log.target_post_id,
+    moderation_log.reason,
+    moderation_log.created_at
+FROM moderation_log
+LEFT JOIN users AS actors ON actors.id = moderation_log.actor_id
+LEFT JOIN users AS targets ON targets.id = moderation_log.target_user_id
+WHERE moderation_log.group_id = $1
+ORDER BY moderation_log.created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetModerationLogParams struct {
+	GroupID uuid.UUID
+	Limit   int32
+	Offset  int32
+}
+
+type GetModerationLogRow struct {
+	ID             uuid.UUID
+	Action         string
+	ActorID        uuid.NullUUID
+	ActorUsername  string
+	TargetUserID   uuid.NullUUID
+	TargetUsername string
+	TargetPostID   uuid.NullUUID
+	Reason         string
+	CreatedAt      sql.NullTime
+}
+
+func (q *Queries) GetModerationLog(ctx context.Context, arg GetModerationLogParams) ([]GetModerationLogRow, error) {
+	rows, err := q.db.QueryContext(ctx, getModerationLog, arg.GroupID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetModerationLogRow
+	for rows.Next() {
+		var i GetModerationLogRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Action,
+			&i.ActorID,
+			&i.ActorUsername,
+			&i.TargetUserID,
+			&i.TargetUsername,
+			&i.TargetPostID,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const logModeration = `-- name: LogModeration :exec
+INSERT INTO moderation_log (group_id, actor_id, action, target_user_id, target_post_id, reason)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type LogModerationParams struct {
+	GroupID      uuid.UUID
+	ActorID      uuid.NullUUID
+	Action       string
+	TargetUserID uuid.NullUUID
+	TargetPostID uuid.NullUUID
+	Reason       string
+}
+
+func (q *Queries) LogModeration(ctx context.Context, arg LogModerationParams) error {
+	_, err := q.db.ExecContext(ctx, logModeration,
+		arg.GroupID,
+		arg.ActorID,
+		arg.Action,
+		arg.TargetUserID,
+		arg.TargetPostID,
+		arg.Reason,
+	)
+	return err
+}
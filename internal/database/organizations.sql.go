@@ -0,0 +1,434 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: organizations.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const addOrganizationMember = `-- name: AddOrganizationMember :exec
+INSERT INTO organization_members (organization_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, user_id) DO UPDATE
+SET role = EXCLUDED.role
+`
+
+type AddOrganizationMemberParams struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+	Role           string
+}
+
+func (q *Queries) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) error {
+	_, err := q.db.ExecContext(ctx, addOrganizationMember, arg.OrganizationID, arg.UserID, arg.Role)
+	return err
+}
+
+const createOrganization = `-- name: CreateOrganization :one
+INSERT INTO organizations (name, description, owner_id)
+VALUES ($1, $2, $3)
+RETURNING id, name, description, owner_id, default_rules, default_join_mode, default_post_policy, created_at, updated_at
+`
+
+type CreateOrganizationParams struct {
+	Name        string
+	Description string
+	OwnerID     uuid.NullUUID
+}
+
+func (q *Queries) CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, createOrganization, arg.Name, arg.Description, arg.OwnerID)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.DefaultRules,
+		&i.DefaultJoinMode,
+		&i.DefaultPostPolicy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createOrganizationGroup = `-- name: CreateOrganizationGroup :one
+INSERT INTO groups (name, description, owner_id, invite_code, organization_id,
+    rules_info, join_mode, post_policy)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, name, description, created_at, updated_at, owner_id, invite_code, rules_info, avatar_url, post_policy, join_mode, is_listed, organization_id, deleted_at, deleted_by, require_second_approval, require_rules_acceptance, edit_window_minutes, allow_anonymous, auto_archive_days
+`
+
+type CreateOrganizationGroupParams struct {
+	Name           string
+	Description    sql.NullString
+	OwnerID        uuid.NullUUID
+	InviteCode     string
+	OrganizationID uuid.NullUUID
+	RulesInfo      string
+	JoinMode       string
+	PostPolicy     string
+}
+
+func (q *Queries) CreateOrganizationGroup(ctx context.Context, arg CreateOrganizationGroupParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, createOrganizationGroup,
+		arg.Name,
+		arg.Description,
+		arg.OwnerID,
+		arg.InviteCode,
+		arg.OrganizationID,
+		arg.RulesInfo,
+		arg.JoinMode,
+		arg.PostPolicy,
+	)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.InviteCode,
+		&i.RulesInfo,
+		&i.AvatarUrl,
+		&i.PostPolicy,
+		&i.JoinMode,
+		&i.IsListed,
+		&i.OrganizationID,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.RequireSecondApproval,
+		&i.RequireRulesAcceptance,
+		&i.EditWindowMinutes,
+		&i.AllowAnonymous,
+		&i.AutoArchiveDays,
+	)
+	return i, err
+}
+
+const getOrganizationAdmins = `-- name: GetOrganizationAdmins :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    organization_members.role
+FROM organization_members
+JOIN users ON users.id = organization_members.user_id
+WHERE organization_members.organization_id = $1
+AND organization_members.role = 'admin'
+ORDER BY users.username ASC
+`
+
+type GetOrganizationAdminsRow struct {
+	ID       uuid.UUID
+	Username string
+	Email    string
+	Role     string
+}
+
+func (q *Queries) GetOrganizationAdmins(ctx context.Context, organizationID uuid.UUID) ([]GetOrganizationAdminsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationAdmins, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationAdminsRow
+	for rows.Next() {
+		var i GetOrganizationAdminsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationByID = `-- name: GetOrganizationByID :one
+SELECT id, name, description, owner_id, default_rules, default_join_mode, default_post_policy, created_at, updated_at
+FROM organizations
+WHERE id = $1
+`
+
+func (q *Queries) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByID, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.DefaultRules,
+		&i.DefaultJoinMode,
+		&i.DefaultPostPolicy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationGroups = `-- name: GetOrganizationGroups :many
+SELECT
+    groups.id,
+    groups.name,
+    groups.description,
+    groups.owner_id,
+    groups.join_mode,
+    groups.post_policy,
+    (
+        SELECT COUNT(*)
+        FROM users_groups
+        WHERE users_groups.group_id = groups.id
+        AND NOT users_groups.is_banned
+        AND NOT users_groups.is_kicked
+    ) AS member_count
+FROM groups
+WHERE groups.organization_id = $1
+AND groups.deleted_at IS NULL
+ORDER BY groups.name ASC
+`
+
+type GetOrganizationGroupsRow struct {
+	ID          uuid.UUID
+	Name        string
+	Description sql.NullString
+	OwnerID     uuid.NullUUID
+	JoinMode    string
+	PostPolicy  string
+	MemberCount int64
+}
+
+func (q *Queries) GetOrganizationGroups(ctx context.Context, organizationID uuid.NullUUID) ([]GetOrganizationGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationGroups, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationGroupsRow
+	for rows.Next() {
+		var i GetOrganizationGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.OwnerID,
+			&i.JoinMode,
+			&i.PostPolicy,
+			&i.MemberCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationMembershipSummary = `-- name: GetOrganizationMembershipSummary :one
+SELECT
+    COUNT(DISTINCT groups.id) AS group_count,
+    COUNT(users_groups.user_id) AS membership_count,
+    COUNT(DISTINCT users_groups.user_id) AS unique_member_count
+FROM groups
+LEFT JOIN users_groups
+    ON users_groups.group_id = groups.id
+    AND NOT users_groups.is_banned
+    AND NOT users_groups.is_kicked
+WHERE groups.organization_id = $1
+AND groups.deleted_at IS NULL
+`
+
+type GetOrganizationMembershipSummaryRow struct {
+	GroupCount        int64
+	MembershipCount   int64
+	UniqueMemberCount int64
+}
+
+func (q *Queries) GetOrganizationMembershipSummary(ctx context.Context, organizationID uuid.NullUUID) (GetOrganizationMembershipSummaryRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationMembershipSummary, organizationID)
+	var i GetOrganizationMembershipSummaryRow
+	err := row.Scan(&i.GroupCount, &i.MembershipCount, &i.UniqueMemberCount)
+	return i, err
+}
+
+const getOrganizationRole = `-- name: GetOrganizationRole :one
+SELECT role
+FROM organization_members
+WHERE organization_id = $1 AND user_id = $2
+`
+
+type GetOrganizationRoleParams struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+}
+
+func (q *Queries) GetOrganizationRole(ctx context.Context, arg GetOrganizationRoleParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationRole, arg.OrganizationID, arg.UserID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getOrganizationsForUser = `-- name: GetOrganizationsForUser :many
+SELECT organizations.id, organizations.name, organizations.description, organizations.owner_id, organizations.default_rules, organizations.default_join_mode, organizations.default_post_policy, organizations.created_at, organizations.updated_at
+FROM organization_members
+JOIN organizations ON organizations.id = organization_members.organization_id
+WHERE organization_members.user_id = $1
+ORDER BY organizations.name ASC
+`
+
+func (q *Queries) GetOrganizationsForUser(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organization
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.OwnerID,
+			&i.DefaultRules,
+			&i.DefaultJoinMode,
+			&i.DefaultPostPolicy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isOrgAdminForGroup = `-- name: IsOrgAdminForGroup :one
+SELECT EXISTS (
+    SELECT 1
+    FROM groups
+    JOIN organization_members
+        ON organization_members.organization_id = groups.organization_id
+    WHERE groups.id = $1
+    AND groups.deleted_at IS NULL
+    AND organization_members.user_id = $2
+    AND organization_members.role = 'admin'
+) AS is_org_admin
+`
+
+type IsOrgAdminForGroupParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) IsOrgAdminForGroup(ctx context.Context, arg IsOrgAdminForGroupParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isOrgAdminForGroup, arg.ID, arg.UserID)
+	var is_org_admin bool
+	err := row.Scan(&is_org_admin)
+	return is_org_admin, err
+}
+
+const removeOrganizationMember = `-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2
+`
+
+type RemoveOrganizationMemberParams struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+}
+
+func (q *Queries) RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeOrganizationMember, arg.OrganizationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setGroupOrganization = `-- name: SetGroupOrganization :exec
+UPDATE groups
+SET organization_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetGroupOrganizationParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.NullUUID
+}
+
+func (q *Queries) SetGroupOrganization(ctx context.Context, arg SetGroupOrganizationParams) error {
+	_, err := q.db.ExecContext(ctx, setGroupOrganization, arg.ID, arg.OrganizationID)
+	return err
+}
+
+const updateOrganization = `-- name: UpdateOrganization :one
+UPDATE organizations
+SET name = $2, description = $3, default_rules = $4, default_join_mode = $5,
+    default_post_policy = $6, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, description, owner_id, default_rules, default_join_mode, default_post_policy, created_at, updated_at
+`
+
+type UpdateOrganizationParams struct {
+	ID                uuid.UUID
+	Name              string
+	Description       string
+	DefaultRules      string
+	DefaultJoinMode   string
+	DefaultPostPolicy string
+}
+
+func (q *Queries) UpdateOrganization(ctx context.Context, arg UpdateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, updateOrganization,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.DefaultRules,
+		arg.DefaultJoinMode,
+		arg.DefaultPostPolicy,
+	)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.DefaultRules,
+		&i.DefaultJoinMode,
+		&i.DefaultPostPolicy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
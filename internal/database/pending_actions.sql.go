@@ -0,0 +1,198 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: pending_actions.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createPendingAction = `-- name: CreatePendingAction :one
+INSERT INTO pending_actions (group_id, action, target_user_id, requested_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, group_id, action, target_user_id, requested_by, status, resolved_by, created_at, expires_at, resolved_at
+`
+
+type CreatePendingActionParams struct {
+	GroupID      uuid.UUID
+	Action       string
+	TargetUserID uuid.NullUUID
+	RequestedBy  uuid.UUID
+	ExpiresAt    time.Time
+}
+
+func (q *Queries) CreatePendingAction(ctx context.Context, arg CreatePendingActionParams) (PendingAction, error) {
+	row := q.db.QueryRowContext(ctx, createPendingAction,
+		arg.GroupID,
+		arg.Action,
+		arg.TargetUserID,
+		arg.RequestedBy,
+		arg.ExpiresAt,
+	)
+	var i PendingAction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Action,
+		&i.TargetUserID,
+		&i.RequestedBy,
+		&i.Status,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const expirePendingActions = `-- name: ExpirePendingActions :execrows
+UPDATE pending_actions
+SET status = 'expired', resolved_at = NOW()
+WHERE status = 'pending' AND expires_at <= NOW()
+`
+
+func (q *Queries) ExpirePendingActions(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expirePendingActions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPendingAction = `-- name: GetPendingAction :one
+SELECT id, group_id, action, target_user_id, requested_by, status, resolved_by, created_at, expires_at, resolved_at
+FROM pending_actions
+WHERE id = $1 AND group_id = $2
+`
+
+type GetPendingActionParams struct {
+	ID      uuid.UUID
+	GroupID uuid.UUID
+}
+
+func (q *Queries) GetPendingAction(ctx context.Context, arg GetPendingActionParams) (PendingAction, error) {
+	row := q.db.QueryRowContext(ctx, getPendingAction, arg.ID, arg.GroupID)
+	var i PendingAction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Action,
+		&i.TargetUserID,
+		&i.RequestedBy,
+		&i.Status,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const getPendingActions = `-- name: GetPendingActions :many
+SELECT
+    pending_actions.id, pending_actions.group_id, pending_actions.action, pending_actions.target_user_id, pending_actions.requested_by, pending_actions.status, pending_actions.resolved_by, pending_actions.created_at, pending_actions.expires_at, pending_actions.resolved_at,
+    requesters.username AS requested_by_username,
+    COALESCE(targets.username, '')::TEXT AS target_username
+FROM pending_actions
+JOIN users AS requesters ON requesters.id = pending_actions.requested_by
+LEFT JOIN users AS targets ON targets.id = pending_actions.target_user_id
+WHERE pending_actions.group_id = $1
+AND pending_actions.status = 'pending'
+AND pending_actions.expires_at > NOW()
+ORDER BY pending_actions.created_at ASC
+`
+
+type GetPendingActionsRow struct {
+	ID                  uuid.UUID
+	GroupID             uuid.UUID
+	Action              string
+	TargetUserID        uuid.NullUUID
+	RequestedBy         uuid.UUID
+	Status              string
+	ResolvedBy          uuid.NullUUID
+	CreatedAt           sql.NullTime
+	ExpiresAt           time.Time
+	ResolvedAt          sql.NullTime
+	RequestedByUsername string
+	TargetUsername      string
+}
+
+func (q *Queries) GetPendingActions(ctx context.Context, groupID uuid.UUID) ([]GetPendingActionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingActions, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPendingActionsRow
+	for rows.Next() {
+		var i GetPendingActionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Action,
+			&i.TargetUserID,
+			&i.RequestedBy,
+			&i.Status,
+			&i.ResolvedBy,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.ResolvedAt,
+			&i.RequestedByUsername,
+			&i.TargetUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolvePendingAction = `-- name: ResolvePendingAction :one
+UPDATE pending_actions
+SET status = $3, resolved_by = $4, resolved_at = NOW()
+WHERE id = $1 AND group_id = $2
+AND status = 'pending' AND expires_at > NOW()
+RETURNING id, group_id, action, target_user_id, requested_by, status, resolved_by, created_at, expires_at, resolved_at
+`
+
+type ResolvePendingActionParams struct {
+	ID         uuid.UUID
+	GroupID    uuid.UUID
+	Status     string
+	ResolvedBy uuid.NullUUID
+}
+
+func (q *Queries) ResolvePendingAction(ctx context.Context, arg ResolvePendingActionParams) (PendingAction, error) {
+	row := q.db.QueryRowContext(ctx, resolvePendingAction,
+		arg.ID,
+		arg.GroupID,
+		arg.Status,
+		arg.ResolvedBy,
+	)
+	var i PendingAction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Action,
+		&i.TargetUserID,
+		&i.RequestedBy,
+		&i.Status,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
@@ -8,14 +8,73 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const addPostAudienceMembers = `-- name: AddPostAudienceMembers :execrows
+INSERT INTO post_audience (post_id, user_id)
+SELECT $1, users_groups.user_id
+FROM users_groups
+WHERE users_groups.group_id = $2
+AND users_groups.user_id = ANY($3::UUID[])
+`
+
+type AddPostAudienceMembersParams struct {
+	PostID  uuid.UUID
+	GroupID uuid.UUID
+	UserIds []uuid.UUID
+}
+
+// Only current members of the group are added, callers compare the row count
+func (q *Queries) AddPostAudienceMembers(ctx context.Context, arg AddPostAudienceMembersParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addPostAudienceMembers, arg.PostID, arg.GroupID, pq.Array(arg.UserIds))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const archiveStalePosts = `-- name: ArchiveStalePosts :execrows
+UPDATE posts
+SET archived_at = NOW()
+FROM groups
+WHERE groups.id = posts.group_id
+AND groups.auto_archive_days > 0
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND posts.archived_at IS NULL
+AND NOT (posts.pinned_at IS NOT NULL AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW()))
+AND GREATEST(
+    posts.created_at,
+    posts.edited_at,
+    posts.status_changed_at,
+    (
+        SELECT MAX(thread.created_at) FROM comment_thread(posts.id) AS thread
+    ),
+    (
+        SELECT MAX(prayer_responses.created_at) FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+    )
+) < NOW() - make_interval(days => groups.auto_archive_days)
+`
+
+// A post is stale once nothing has happened on it for the group's auto_archive_days
+func (q *Queries) ArchiveStalePosts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveStalePosts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createComment = `-- name: CreateComment :one
-INSERT INTO posts (user_id, group_id, content, parent_post_id)
-VALUES ($1, $2, $3, $4)
-RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted
+INSERT INTO posts (user_id, group_id, content, parent_post_id, is_anonymous)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted, pinned_at, pinned_until, pinned_by, pin_order, edited_at, category, status, status_changed_at, status_changed_by, answered_at, answer_note, is_anonymous, audience, publish_at, archived_at
 `
 
 type CreateCommentParams struct {
@@ -23,6 +82,7 @@ type CreateCommentParams struct {
 	GroupID      uuid.UUID
 	Content      string
 	ParentPostID uuid.NullUUID
+	IsAnonymous  bool
 }
 
 func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Post, error) {
@@ -31,6 +91,7 @@ func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (P
 		arg.GroupID,
 		arg.Content,
 		arg.ParentPostID,
+		arg.IsAnonymous,
 	)
 	var i Post
 	err := row.Scan(
@@ -42,24 +103,51 @@ func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (P
 		&i.UpdatedAt,
 		&i.ParentPostID,
 		&i.IsDeleted,
+		&i.PinnedAt,
+		&i.PinnedUntil,
+		&i.PinnedBy,
+		&i.PinOrder,
+		&i.EditedAt,
+		&i.Category,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+		&i.AnsweredAt,
+		&i.AnswerNote,
+		&i.IsAnonymous,
+		&i.Audience,
+		&i.PublishAt,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (user_id, group_id, content)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted
+INSERT INTO posts (user_id, group_id, content, category, is_anonymous, audience, publish_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted, pinned_at, pinned_until, pinned_by, pin_order, edited_at, category, status, status_changed_at, status_changed_by, answered_at, answer_note, is_anonymous, audience, publish_at, archived_at
 `
 
 type CreatePostParams struct {
-	UserID  uuid.UUID
-	GroupID uuid.UUID
-	Content string
+	UserID      uuid.UUID
+	GroupID     uuid.UUID
+	Content     string
+	Category    string
+	IsAnonymous bool
+	Audience    string
+	PublishAt   sql.NullTime
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost, arg.UserID, arg.GroupID, arg.Content)
+	row := q.db.QueryRowContext(ctx, createPost,
+		arg.UserID,
+		arg.GroupID,
+		arg.Content,
+		arg.Category,
+		arg.IsAnonymous,
+		arg.Audience,
+		arg.PublishAt,
+	)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -70,16 +158,55 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.UpdatedAt,
 		&i.ParentPostID,
 		&i.IsDeleted,
+		&i.PinnedAt,
+		&i.PinnedUntil,
+		&i.PinnedBy,
+		&i.PinOrder,
+		&i.EditedAt,
+		&i.Category,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+		&i.AnsweredAt,
+		&i.AnswerNote,
+		&i.IsAnonymous,
+		&i.Audience,
+		&i.PublishAt,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
+const createPostRevision = `-- name: CreatePostRevision :exec
+INSERT INTO post_revisions (post_id, content, edited_by)
+VALUES ($1, $2, $3)
+`
+
+type CreatePostRevisionParams struct {
+	PostID   uuid.UUID
+	Content  string
+	EditedBy uuid.NullUUID
+}
+
+func (q *Queries) CreatePostRevision(ctx context.Context, arg CreatePostRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createPostRevision, arg.PostID, arg.Content, arg.EditedBy)
+	return err
+}
+
 const deleteCommentsFromPost = `-- name: DeleteCommentsFromPost :exec
+WITH RECURSIVE thread AS (
+    SELECT posts.id FROM posts
+    WHERE posts.parent_post_id = $1
+    UNION ALL
+    SELECT replies.id FROM posts AS replies
+    JOIN thread ON replies.parent_post_id = thread.id
+)
 UPDATE posts
 SET updated_at = CURRENT_TIMESTAMP, is_deleted = TRUE
-WHERE parent_post_id = $1
+WHERE posts.id IN (SELECT thread.id FROM thread)
 `
 
+// Replies to comments are removed along with the rest of the thread
 func (q *Queries) DeleteCommentsFromPost(ctx context.Context, parentPostID uuid.NullUUID) error {
 	_, err := q.db.ExecContext(ctx, deleteCommentsFromPost, parentPostID)
 	return err
@@ -96,32 +223,211 @@ func (q *Queries) DeletePost(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-const getCommentsByPostID = `-- name: GetCommentsByPostID :many
+const expirePins = `-- name: ExpirePins :execrows
+UPDATE posts
+SET pinned_at = NULL, pinned_until = NULL, pinned_by = NULL, pin_order = 0
+WHERE pinned_until <= NOW()
+`
+
+func (q *Queries) ExpirePins(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expirePins)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAnsweredPosts = `-- name: GetAnsweredPosts :many
 SELECT
     posts.id,
     posts.content,
     posts.user_id,
     posts.group_id,
     posts.created_at,
-    users.username
+    posts.edited_at,
+    posts.category,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    users.username,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count
 FROM posts
 LEFT JOIN users ON posts.user_id = users.id
-WHERE posts.parent_post_id = $1
+WHERE posts.group_id = $1
+AND posts.parent_post_id IS NULL
 AND posts.is_deleted = FALSE
-ORDER BY posts.created_at DESC
+AND posts.status = 'answered'
+AND posts.publish_at IS NULL
+AND post_visible_to(posts.id, $2, $3)
+ORDER BY posts.answered_at DESC
+LIMIT $5 OFFSET $4
+`
+
+type GetAnsweredPostsParams struct {
+	GroupID        uuid.UUID
+	ViewerID       uuid.UUID
+	ViewerIsLeader bool
+	Offset         int32
+	Limit          int32
+}
+
+type GetAnsweredPostsRow struct {
+	ID           uuid.UUID
+	Content      string
+	UserID       uuid.UUID
+	GroupID      uuid.UUID
+	CreatedAt    sql.NullTime
+	EditedAt     sql.NullTime
+	Category     string
+	AnsweredAt   sql.NullTime
+	AnswerNote   string
+	IsAnonymous  bool
+	Audience     string
+	Username     sql.NullString
+	CommentCount int64
+}
+
+func (q *Queries) GetAnsweredPosts(ctx context.Context, arg GetAnsweredPostsParams) ([]GetAnsweredPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAnsweredPosts,
+		arg.GroupID,
+		arg.ViewerID,
+		arg.ViewerIsLeader,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAnsweredPostsRow
+	for rows.Next() {
+		var i GetAnsweredPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.Category,
+			&i.AnsweredAt,
+			&i.AnswerNote,
+			&i.IsAnonymous,
+			&i.Audience,
+			&i.Username,
+			&i.CommentCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentsByPostID = `-- name: GetCommentsByPostID :many
+WITH RECURSIVE top_level AS (
+    -- Pages cover top-level comments, replies are always included
+    SELECT posts.id
+    FROM posts
+    WHERE posts.parent_post_id = $1::UUID
+    AND posts.is_deleted = FALSE
+    AND (
+        $4::TIMESTAMPTZ IS NULL
+        OR (
+            $5::BOOLEAN
+            AND (posts.created_at, posts.id) > ($4::TIMESTAMPTZ, $6::UUID)
+        )
+        OR (
+            NOT $5::BOOLEAN
+            AND (posts.created_at, posts.id) < ($4::TIMESTAMPTZ, $6::UUID)
+        )
+    )
+    ORDER BY
+        CASE WHEN $5::BOOLEAN THEN posts.created_at END ASC,
+        CASE WHEN $5::BOOLEAN THEN posts.id END ASC,
+        posts.created_at DESC,
+        posts.id DESC
+    LIMIT $8 OFFSET $7
+),
+thread AS (
+    SELECT top_level.id, 1 AS depth
+    FROM top_level
+    UNION ALL
+    SELECT replies.id, thread.depth + 1
+    FROM posts AS replies
+    JOIN thread ON replies.parent_post_id = thread.id
+    WHERE replies.is_deleted = FALSE
+)
+SELECT
+    posts.id,
+    posts.parent_post_id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.is_anonymous,
+    thread.depth::INTEGER AS depth,
+    (
+        SELECT COUNT(*) FROM posts AS replies
+        WHERE replies.parent_post_id = posts.id
+        AND replies.is_deleted = FALSE
+    )::BIGINT AS reply_count,
+    users.username
+FROM thread
+JOIN posts ON posts.id = thread.id
+LEFT JOIN users ON posts.user_id = users.id
+WHERE post_visible_to($1::UUID, $2, $3)
+ORDER BY thread.depth DESC, posts.created_at DESC, posts.id DESC
 `
 
+type GetCommentsByPostIDParams struct {
+	PostID         uuid.UUID
+	ViewerID       uuid.UUID
+	ViewerIsLeader bool
+	CursorTime     sql.NullTime
+	Newer          bool
+	CursorID       uuid.NullUUID
+	Offset         int32
+	Limit          sql.NullInt32
+}
+
 type GetCommentsByPostIDRow struct {
-	ID        uuid.UUID
-	Content   string
-	UserID    uuid.UUID
-	GroupID   uuid.UUID
-	CreatedAt sql.NullTime
-	Username  sql.NullString
+	ID           uuid.UUID
+	ParentPostID uuid.NullUUID
+	Content      string
+	UserID       uuid.UUID
+	GroupID      uuid.UUID
+	CreatedAt    sql.NullTime
+	EditedAt     sql.NullTime
+	IsAnonymous  bool
+	Depth        int32
+	ReplyCount   int64
+	Username     sql.NullString
 }
 
-func (q *Queries) GetCommentsByPostID(ctx context.Context, parentPostID uuid.NullUUID) ([]GetCommentsByPostIDRow, error) {
-	rows, err := q.db.QueryContext(ctx, getCommentsByPostID, parentPostID)
+// Walks the reply tree under a post. Deepest replies come first so each one
+// can be attached to its parent in a single pass.
+func (q *Queries) GetCommentsByPostID(ctx context.Context, arg GetCommentsByPostIDParams) ([]GetCommentsByPostIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCommentsByPostID,
+		arg.PostID,
+		arg.ViewerID,
+		arg.ViewerIsLeader,
+		arg.CursorTime,
+		arg.Newer,
+		arg.CursorID,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -129,13 +435,118 @@ func (q *Queries) GetCommentsByPostID(ctx context.Context, parentPostID uuid.Nul
 	var items []GetCommentsByPostIDRow
 	for rows.Next() {
 		var i GetCommentsByPostIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentPostID,
+			&i.Content,
+			&i.UserID,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.IsAnonymous,
+			&i.Depth,
+			&i.ReplyCount,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPinnedPosts = `-- name: GetPinnedPosts :many
+SELECT
+    posts.id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.pinned_at,
+    posts.pinned_until,
+    posts.pin_order,
+    posts.is_anonymous,
+    posts.audience,
+    users.username,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count
+FROM posts
+LEFT JOIN users ON posts.user_id = users.id
+WHERE posts.group_id = $1
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND posts.pinned_at IS NOT NULL
+AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW())
+AND post_visible_to(posts.id, $2, $3)
+ORDER BY posts.pin_order ASC, posts.pinned_at DESC
+`
+
+type GetPinnedPostsParams struct {
+	GroupID        uuid.UUID
+	ViewerID       uuid.UUID
+	ViewerIsLeader bool
+}
+
+type GetPinnedPostsRow struct {
+	ID           uuid.UUID
+	Content      string
+	UserID       uuid.UUID
+	GroupID      uuid.UUID
+	CreatedAt    sql.NullTime
+	EditedAt     sql.NullTime
+	Category     string
+	Status       string
+	AnsweredAt   sql.NullTime
+	AnswerNote   string
+	PinnedAt     sql.NullTime
+	PinnedUntil  sql.NullTime
+	PinOrder     int32
+	IsAnonymous  bool
+	Audience     string
+	Username     sql.NullString
+	CommentCount int64
+}
+
+func (q *Queries) GetPinnedPosts(ctx context.Context, arg GetPinnedPostsParams) ([]GetPinnedPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPinnedPosts, arg.GroupID, arg.ViewerID, arg.ViewerIsLeader)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPinnedPostsRow
+	for rows.Next() {
+		var i GetPinnedPostsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Content,
 			&i.UserID,
 			&i.GroupID,
 			&i.CreatedAt,
+			&i.EditedAt,
+			&i.Category,
+			&i.Status,
+			&i.AnsweredAt,
+			&i.AnswerNote,
+			&i.PinnedAt,
+			&i.PinnedUntil,
+			&i.PinOrder,
+			&i.IsAnonymous,
+			&i.Audience,
 			&i.Username,
+			&i.CommentCount,
 		); err != nil {
 			return nil, err
 		}
@@ -150,6 +561,36 @@ func (q *Queries) GetCommentsByPostID(ctx context.Context, parentPostID uuid.Nul
 	return items, nil
 }
 
+const getPostAudience = `-- name: GetPostAudience :many
+SELECT user_id
+FROM post_audience
+WHERE post_id = $1
+ORDER BY user_id
+`
+
+func (q *Queries) GetPostAudience(ctx context.Context, postID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getPostAudience, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostByID = `-- name: GetPostByID :one
 SELECT 
     posts.id,
@@ -157,6 +598,16 @@ SELECT
     posts.user_id,
     posts.group_id,
     posts.created_at,
+    posts.edited_at,
+    posts.parent_post_id,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    posts.publish_at,
+    posts.archived_at,
     users.username
 FROM posts
 LEFT JOIN users ON posts.user_id = users.id
@@ -165,12 +616,22 @@ AND posts.is_deleted = FALSE
 `
 
 type GetPostByIDRow struct {
-	ID        uuid.UUID
-	Content   string
-	UserID    uuid.UUID
-	GroupID   uuid.UUID
-	CreatedAt sql.NullTime
-	Username  sql.NullString
+	ID           uuid.UUID
+	Content      string
+	UserID       uuid.UUID
+	GroupID      uuid.UUID
+	CreatedAt    sql.NullTime
+	EditedAt     sql.NullTime
+	ParentPostID uuid.NullUUID
+	Category     string
+	Status       string
+	AnsweredAt   sql.NullTime
+	AnswerNote   string
+	IsAnonymous  bool
+	Audience     string
+	PublishAt    sql.NullTime
+	ArchivedAt   sql.NullTime
+	Username     sql.NullString
 }
 
 func (q *Queries) GetPostByID(ctx context.Context, id uuid.UUID) (GetPostByIDRow, error) {
@@ -182,28 +643,134 @@ func (q *Queries) GetPostByID(ctx context.Context, id uuid.UUID) (GetPostByIDRow
 		&i.UserID,
 		&i.GroupID,
 		&i.CreatedAt,
+		&i.EditedAt,
+		&i.ParentPostID,
+		&i.Category,
+		&i.Status,
+		&i.AnsweredAt,
+		&i.AnswerNote,
+		&i.IsAnonymous,
+		&i.Audience,
+		&i.PublishAt,
+		&i.ArchivedAt,
 		&i.Username,
 	)
 	return i, err
 }
 
+const getPostContentForUpdate = `-- name: GetPostContentForUpdate :one
+SELECT content
+FROM posts
+WHERE id = $1
+AND is_deleted = FALSE
+FOR UPDATE
+`
+
+// Locks the post so concurrent edits record their revisions one after another
+func (q *Queries) GetPostContentForUpdate(ctx context.Context, id uuid.UUID) (string, error) {
+	row := q.db.QueryRowContext(ctx, getPostContentForUpdate, id)
+	var content string
+	err := row.Scan(&content)
+	return content, err
+}
+
 const getPostCountByGroupID = `-- name: GetPostCountByGroupID :one
 SELECT COUNT(*) AS post_count
 FROM posts
-WHERE group_id = $1
-AND parent_post_id IS NULL
-AND is_deleted = FALSE
+WHERE posts.group_id = $1
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND post_visible_to(posts.id, $2, $3)
 `
 
-func (q *Queries) GetPostCountByGroupID(ctx context.Context, groupID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getPostCountByGroupID, groupID)
+type GetPostCountByGroupIDParams struct {
+	GroupID        uuid.UUID
+	ViewerID       uuid.UUID
+	ViewerIsLeader bool
+}
+
+func (q *Queries) GetPostCountByGroupID(ctx context.Context, arg GetPostCountByGroupIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getPostCountByGroupID, arg.GroupID, arg.ViewerID, arg.ViewerIsLeader)
 	var post_count int64
 	err := row.Scan(&post_count)
 	return post_count, err
 }
 
+const getPostDepth = `-- name: GetPostDepth :one
+WITH RECURSIVE ancestors AS (
+    SELECT posts.id, posts.parent_post_id, 0 AS depth
+    FROM posts
+    WHERE posts.id = $1
+    UNION ALL
+    SELECT parents.id, parents.parent_post_id, ancestors.depth + 1
+    FROM posts AS parents
+    JOIN ancestors ON parents.id = ancestors.parent_post_id
+)
+SELECT MAX(ancestors.depth)::INTEGER AS depth
+FROM ancestors
+`
+
+// Top-level posts are depth 0, comments on them depth 1 and so on
+func (q *Queries) GetPostDepth(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getPostDepth, id)
+	var depth int32
+	err := row.Scan(&depth)
+	return depth, err
+}
+
+const getPostRevisions = `-- name: GetPostRevisions :many
+SELECT
+    post_revisions.id,
+    post_revisions.content,
+    post_revisions.edited_by,
+    COALESCE(users.username, '')::TEXT AS editor,
+    post_revisions.created_at
+FROM post_revisions
+LEFT JOIN users ON users.id = post_revisions.edited_by
+WHERE post_revisions.post_id = $1
+ORDER BY post_revisions.created_at ASC
+`
+
+type GetPostRevisionsRow struct {
+	ID        uuid.UUID
+	Content   string
+	EditedBy  uuid.NullUUID
+	Editor    string
+	CreatedAt sql.NullTime
+}
+
+func (q *Queries) GetPostRevisions(ctx context.Context, postID uuid.UUID) ([]GetPostRevisionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostRevisions, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostRevisionsRow
+	for rows.Next() {
+		var i GetPostRevisionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.EditedBy,
+			&i.Editor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsByGroupID = `-- name: GetPostsByGroupID :many
-SELECT id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted FROM posts
+SELECT id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted, pinned_at, pinned_until, pinned_by, pin_order, edited_at, category, status, status_changed_at, status_changed_by, answered_at, answer_note, is_anonymous, audience, publish_at, archived_at FROM posts
 WHERE group_id = $1
 ORDER BY created_at DESC
 `
@@ -226,6 +793,21 @@ func (q *Queries) GetPostsByGroupID(ctx context.Context, groupID uuid.UUID) ([]P
 			&i.UpdatedAt,
 			&i.ParentPostID,
 			&i.IsDeleted,
+			&i.PinnedAt,
+			&i.PinnedUntil,
+			&i.PinnedBy,
+			&i.PinOrder,
+			&i.EditedAt,
+			&i.Category,
+			&i.Status,
+			&i.StatusChangedAt,
+			&i.StatusChangedBy,
+			&i.AnsweredAt,
+			&i.AnswerNote,
+			&i.IsAnonymous,
+			&i.Audience,
+			&i.PublishAt,
+			&i.ArchivedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -247,25 +829,58 @@ SELECT
     posts.user_id,
     posts.group_id,
     posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    posts.archived_at,
     users.username,
-    COUNT(comments.id) AS comment_count
+    (posts.pinned_at IS NOT NULL AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW()))::BOOLEAN AS is_pinned,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count,
+    (
+        SELECT COUNT(DISTINCT prayer_responses.user_id)
+        FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+    )::BIGINT AS prayed_count,
+    EXISTS (
+        SELECT 1 FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+        AND prayer_responses.user_id = $1
+    )::BOOLEAN AS prayed_by_me
 FROM posts
 LEFT JOIN users ON posts.user_id = users.id
-LEFT JOIN posts AS comments
-    ON posts.id = comments.parent_post_id
-    AND comments.is_deleted = FALSE
-WHERE posts.group_id = $1
+WHERE posts.group_id = $2
 AND posts.parent_post_id IS NULL
 AND posts.is_deleted = FALSE
-GROUP BY posts.id, posts.content, posts.user_id, posts.group_id, posts.created_at, users.username
-ORDER BY posts.created_at DESC
-LIMIT $2 OFFSET $3
+AND posts.publish_at IS NULL
+AND (posts.archived_at IS NOT NULL) = $3::BOOLEAN
+AND (posts.category = $4 OR $4 = '')
+AND (posts.status = $5 OR $5 = '')
+AND (
+    $6::TIMESTAMPTZ IS NULL
+    OR (posts.created_at, posts.id) < ($6::TIMESTAMPTZ, $7::UUID)
+)
+AND post_visible_to(posts.id, $1, $8)
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT $10 OFFSET $9
 `
 
 type GetPostsForFeedParams struct {
-	GroupID uuid.UUID
-	Limit   int32
-	Offset  int32
+	ViewerID       uuid.UUID
+	GroupID        uuid.UUID
+	Archived       bool
+	Category       string
+	Status         string
+	BeforeTime     sql.NullTime
+	BeforeID       uuid.NullUUID
+	ViewerIsLeader bool
+	Offset         int32
+	Limit          int32
 }
 
 type GetPostsForFeedRow struct {
@@ -274,12 +889,34 @@ type GetPostsForFeedRow struct {
 	UserID       uuid.UUID
 	GroupID      uuid.UUID
 	CreatedAt    sql.NullTime
+	EditedAt     sql.NullTime
+	Category     string
+	Status       string
+	AnsweredAt   sql.NullTime
+	AnswerNote   string
+	IsAnonymous  bool
+	Audience     string
+	ArchivedAt   sql.NullTime
 	Username     sql.NullString
+	IsPinned     bool
 	CommentCount int64
+	PrayedCount  int64
+	PrayedByMe   bool
 }
 
 func (q *Queries) GetPostsForFeed(ctx context.Context, arg GetPostsForFeedParams) ([]GetPostsForFeedRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForFeed, arg.GroupID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, getPostsForFeed,
+		arg.ViewerID,
+		arg.GroupID,
+		arg.Archived,
+		arg.Category,
+		arg.Status,
+		arg.BeforeTime,
+		arg.BeforeID,
+		arg.ViewerIsLeader,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -293,8 +930,150 @@ func (q *Queries) GetPostsForFeed(ctx context.Context, arg GetPostsForFeedParams
 			&i.UserID,
 			&i.GroupID,
 			&i.CreatedAt,
+			&i.EditedAt,
+			&i.Category,
+			&i.Status,
+			&i.AnsweredAt,
+			&i.AnswerNote,
+			&i.IsAnonymous,
+			&i.Audience,
+			&i.ArchivedAt,
+			&i.Username,
+			&i.IsPinned,
+			&i.CommentCount,
+			&i.PrayedCount,
+			&i.PrayedByMe,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForFeedNewer = `-- name: GetPostsForFeedNewer :many
+SELECT
+    posts.id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    posts.archived_at,
+    users.username,
+    (posts.pinned_at IS NOT NULL AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW()))::BOOLEAN AS is_pinned,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count,
+    (
+        SELECT COUNT(DISTINCT prayer_responses.user_id)
+        FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+    )::BIGINT AS prayed_count,
+    EXISTS (
+        SELECT 1 FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+        AND prayer_responses.user_id = $1
+    )::BOOLEAN AS prayed_by_me
+FROM posts
+LEFT JOIN users ON posts.user_id = users.id
+WHERE posts.group_id = $2
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND (posts.archived_at IS NOT NULL) = $3::BOOLEAN
+AND (posts.category = $4 OR $4 = '')
+AND (posts.status = $5 OR $5 = '')
+AND (posts.created_at, posts.id) > ($6::TIMESTAMPTZ, $7::UUID)
+AND post_visible_to(posts.id, $1, $8)
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT $9
+`
+
+type GetPostsForFeedNewerParams struct {
+	ViewerID       uuid.UUID
+	GroupID        uuid.UUID
+	Archived       bool
+	Category       string
+	Status         string
+	AfterTime      time.Time
+	AfterID        uuid.UUID
+	ViewerIsLeader bool
+	Limit          int32
+}
+
+type GetPostsForFeedNewerRow struct {
+	ID           uuid.UUID
+	Content      string
+	UserID       uuid.UUID
+	GroupID      uuid.UUID
+	CreatedAt    sql.NullTime
+	EditedAt     sql.NullTime
+	Category     string
+	Status       string
+	AnsweredAt   sql.NullTime
+	AnswerNote   string
+	IsAnonymous  bool
+	Audience     string
+	ArchivedAt   sql.NullTime
+	Username     sql.NullString
+	IsPinned     bool
+	CommentCount int64
+	PrayedCount  int64
+	PrayedByMe   bool
+}
+
+// Walks the feed towards newer posts from a cursor, oldest first
+func (q *Queries) GetPostsForFeedNewer(ctx context.Context, arg GetPostsForFeedNewerParams) ([]GetPostsForFeedNewerRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForFeedNewer,
+		arg.ViewerID,
+		arg.GroupID,
+		arg.Archived,
+		arg.Category,
+		arg.Status,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.ViewerIsLeader,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForFeedNewerRow
+	for rows.Next() {
+		var i GetPostsForFeedNewerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.Category,
+			&i.Status,
+			&i.AnsweredAt,
+			&i.AnswerNote,
+			&i.IsAnonymous,
+			&i.Audience,
+			&i.ArchivedAt,
 			&i.Username,
+			&i.IsPinned,
 			&i.CommentCount,
+			&i.PrayedCount,
+			&i.PrayedByMe,
 		); err != nil {
 			return nil, err
 		}
@@ -309,6 +1088,197 @@ func (q *Queries) GetPostsForFeed(ctx context.Context, arg GetPostsForFeedParams
 	return items, nil
 }
 
+const getScheduledPosts = `-- name: GetScheduledPosts :many
+SELECT
+    posts.id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.is_anonymous,
+    posts.audience,
+    posts.publish_at,
+    users.username
+FROM posts
+LEFT JOIN users ON posts.user_id = users.id
+WHERE posts.group_id = $1
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NOT NULL
+ORDER BY posts.publish_at ASC
+`
+
+type GetScheduledPostsRow struct {
+	ID          uuid.UUID
+	Content     string
+	UserID      uuid.UUID
+	GroupID     uuid.UUID
+	CreatedAt   sql.NullTime
+	EditedAt    sql.NullTime
+	Category    string
+	IsAnonymous bool
+	Audience    string
+	PublishAt   sql.NullTime
+	Username    sql.NullString
+}
+
+func (q *Queries) GetScheduledPosts(ctx context.Context, groupID uuid.UUID) ([]GetScheduledPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getScheduledPosts, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetScheduledPostsRow
+	for rows.Next() {
+		var i GetScheduledPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.GroupID,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.Category,
+			&i.IsAnonymous,
+			&i.Audience,
+			&i.PublishAt,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isPostVisibleTo = `-- name: IsPostVisibleTo :one
+SELECT post_visible_to($1, $2, $3)::BOOLEAN AS visible
+`
+
+type IsPostVisibleToParams struct {
+	PostID         uuid.UUID
+	ViewerID       uuid.UUID
+	ViewerIsLeader bool
+}
+
+func (q *Queries) IsPostVisibleTo(ctx context.Context, arg IsPostVisibleToParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isPostVisibleTo, arg.PostID, arg.ViewerID, arg.ViewerIsLeader)
+	var visible bool
+	err := row.Scan(&visible)
+	return visible, err
+}
+
+const pinPost = `-- name: PinPost :execrows
+UPDATE posts
+SET pinned_at = NOW(), pinned_until = $3, pinned_by = $4, pin_order = $5
+WHERE id = $1 AND group_id = $2
+AND parent_post_id IS NULL
+AND is_deleted = FALSE
+AND publish_at IS NULL
+`
+
+type PinPostParams struct {
+	ID          uuid.UUID
+	GroupID     uuid.UUID
+	PinnedUntil sql.NullTime
+	PinnedBy    uuid.NullUUID
+	PinOrder    int32
+}
+
+func (q *Queries) PinPost(ctx context.Context, arg PinPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pinPost,
+		arg.ID,
+		arg.GroupID,
+		arg.PinnedUntil,
+		arg.PinnedBy,
+		arg.PinOrder,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const publishDuePosts = `-- name: PublishDuePosts :many
+UPDATE posts
+SET created_at = NOW(), publish_at = NULL, updated_at = NOW()
+WHERE publish_at <= NOW()
+AND is_deleted = FALSE
+RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted, pinned_at, pinned_until, pinned_by, pin_order, edited_at, category, status, status_changed_at, status_changed_by, answered_at, answer_note, is_anonymous, audience, publish_at, archived_at
+`
+
+// created_at moves to the moment of publishing so the post lands at the top
+// of the feed. Using publish_at would back-date late runs behind cursors
+// clients already hold, and they would never see the post.
+func (q *Queries) PublishDuePosts(ctx context.Context) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, publishDuePosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.GroupID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ParentPostID,
+			&i.IsDeleted,
+			&i.PinnedAt,
+			&i.PinnedUntil,
+			&i.PinnedBy,
+			&i.PinOrder,
+			&i.EditedAt,
+			&i.Category,
+			&i.Status,
+			&i.StatusChangedAt,
+			&i.StatusChangedBy,
+			&i.AnsweredAt,
+			&i.AnswerNote,
+			&i.IsAnonymous,
+			&i.Audience,
+			&i.PublishAt,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedPosts = `-- name: PurgeDeletedPosts :execrows
+DELETE FROM posts
+WHERE is_deleted = TRUE
+AND updated_at < $1
+`
+
+func (q *Queries) PurgeDeletedPosts(ctx context.Context, updatedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedPosts, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const resetPosts = `-- name: ResetPosts :exec
 TRUNCATE TABLE posts CASCADE
 `
@@ -322,7 +1292,7 @@ const restorePost = `-- name: RestorePost :one
 UPDATE posts
 SET is_deleted = FALSE, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted
+RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted, pinned_at, pinned_until, pinned_by, pin_order, edited_at, category, status, status_changed_at, status_changed_by, answered_at, answer_note, is_anonymous, audience, publish_at, archived_at
 `
 
 func (q *Queries) RestorePost(ctx context.Context, id uuid.UUID) (Post, error) {
@@ -337,16 +1307,126 @@ func (q *Queries) RestorePost(ctx context.Context, id uuid.UUID) (Post, error) {
 		&i.UpdatedAt,
 		&i.ParentPostID,
 		&i.IsDeleted,
+		&i.PinnedAt,
+		&i.PinnedUntil,
+		&i.PinnedBy,
+		&i.PinOrder,
+		&i.EditedAt,
+		&i.Category,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+		&i.AnsweredAt,
+		&i.AnswerNote,
+		&i.IsAnonymous,
+		&i.Audience,
+		&i.PublishAt,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
 
+const setPostStatus = `-- name: SetPostStatus :one
+UPDATE posts
+SET status = $1,
+    answer_note = $2,
+    answered_at = CASE
+        WHEN $1 = 'answered' THEN COALESCE(answered_at, NOW())
+        ELSE NULL
+    END,
+    status_changed_at = NOW(),
+    status_changed_by = $3
+WHERE id = $4
+AND group_id = $5
+AND parent_post_id IS NULL
+AND is_deleted = FALSE
+RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted, pinned_at, pinned_until, pinned_by, pin_order, edited_at, category, status, status_changed_at, status_changed_by, answered_at, answer_note, is_anonymous, audience, publish_at, archived_at
+`
+
+type SetPostStatusParams struct {
+	Status     string
+	AnswerNote string
+	ChangedBy  uuid.NullUUID
+	ID         uuid.UUID
+	GroupID    uuid.UUID
+}
+
+// answered_at keeps the first time a request was answered if the note is updated
+func (q *Queries) SetPostStatus(ctx context.Context, arg SetPostStatusParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, setPostStatus,
+		arg.Status,
+		arg.AnswerNote,
+		arg.ChangedBy,
+		arg.ID,
+		arg.GroupID,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.GroupID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ParentPostID,
+		&i.IsDeleted,
+		&i.PinnedAt,
+		&i.PinnedUntil,
+		&i.PinnedBy,
+		&i.PinOrder,
+		&i.EditedAt,
+		&i.Category,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+		&i.AnsweredAt,
+		&i.AnswerNote,
+		&i.IsAnonymous,
+		&i.Audience,
+		&i.PublishAt,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const unarchivePost = `-- name: UnarchivePost :exec
+UPDATE posts
+SET archived_at = NULL
+WHERE id = $1
+AND archived_at IS NOT NULL
+`
+
+func (q *Queries) UnarchivePost(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, unarchivePost, id)
+	return err
+}
+
+const unpinPost = `-- name: UnpinPost :execrows
+UPDATE posts
+SET pinned_at = NULL, pinned_until = NULL, pinned_by = NULL, pin_order = 0
+WHERE id = $1 AND group_id = $2
+AND pinned_at IS NOT NULL
+`
+
+type UnpinPostParams struct {
+	ID      uuid.UUID
+	GroupID uuid.UUID
+}
+
+func (q *Queries) UnpinPost(ctx context.Context, arg UnpinPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unpinPost, arg.ID, arg.GroupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updatePost = `-- name: UpdatePost :one
 UPDATE posts
-SET content = $1, updated_at = CURRENT_TIMESTAMP
+SET content = $1, edited_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
 WHERE id = $2
 AND is_deleted = FALSE
-RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted
+RETURNING id, user_id, group_id, content, created_at, updated_at, parent_post_id, is_deleted, pinned_at, pinned_until, pinned_by, pin_order, edited_at, category, status, status_changed_at, status_changed_by, answered_at, answer_note, is_anonymous, audience, publish_at, archived_at
 `
 
 type UpdatePostParams struct {
@@ -366,6 +1446,21 @@ func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, e
 		&i.UpdatedAt,
 		&i.ParentPostID,
 		&i.IsDeleted,
+		&i.PinnedAt,
+		&i.PinnedUntil,
+		&i.PinnedBy,
+		&i.PinOrder,
+		&i.EditedAt,
+		&i.Category,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+		&i.AnsweredAt,
+		&i.AnswerNote,
+		&i.IsAnonymous,
+		&i.Audience,
+		&i.PublishAt,
+		&i.ArchivedAt,
 	)
 	return i, err
 }
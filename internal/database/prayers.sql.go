@@ -0,0 +1,126 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: prayers.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getPrayerRoster = `-- name: GetPrayerRoster :many
+SELECT
+    prayer_responses.user_id,
+    COALESCE(users.username, '')::TEXT AS username,
+    COUNT(*)::BIGINT AS times_prayed,
+    MAX(prayer_responses.created_at)::TIMESTAMPTZ AS last_prayed_at
+FROM prayer_responses
+LEFT JOIN users ON users.id = prayer_responses.user_id
+WHERE prayer_responses.post_id = $1
+GROUP BY prayer_responses.user_id, users.username
+ORDER BY last_prayed_at DESC
+`
+
+type GetPrayerRosterRow struct {
+	UserID       uuid.UUID
+	Username     string
+	TimesPrayed  int64
+	LastPrayedAt time.Time
+}
+
+func (q *Queries) GetPrayerRoster(ctx context.Context, postID uuid.UUID) ([]GetPrayerRosterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPrayerRoster, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPrayerRosterRow
+	for rows.Next() {
+		var i GetPrayerRosterRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.TimesPrayed,
+			&i.LastPrayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPrayerSummary = `-- name: GetPrayerSummary :one
+SELECT
+    COUNT(DISTINCT user_id)::BIGINT AS prayed_count,
+    COUNT(*)::BIGINT AS prayer_count,
+    COALESCE(BOOL_OR(user_id = $1), FALSE)::BOOLEAN AS prayed_by_me
+FROM prayer_responses
+WHERE post_id = $2
+`
+
+type GetPrayerSummaryParams struct {
+	ViewerID uuid.UUID
+	PostID   uuid.UUID
+}
+
+type GetPrayerSummaryRow struct {
+	PrayedCount int64
+	PrayerCount int64
+	PrayedByMe  bool
+}
+
+func (q *Queries) GetPrayerSummary(ctx context.Context, arg GetPrayerSummaryParams) (GetPrayerSummaryRow, error) {
+	row := q.db.QueryRowContext(ctx, getPrayerSummary, arg.ViewerID, arg.PostID)
+	var i GetPrayerSummaryRow
+	err := row.Scan(&i.PrayedCount, &i.PrayerCount, &i.PrayedByMe)
+	return i, err
+}
+
+const recordPrayerResponse = `-- name: RecordPrayerResponse :execrows
+INSERT INTO prayer_responses (post_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type RecordPrayerResponseParams struct {
+	PostID uuid.UUID
+	UserID uuid.UUID
+}
+
+// One response per user per post each day, praying again tomorrow adds a row
+func (q *Queries) RecordPrayerResponse(ctx context.Context, arg RecordPrayerResponseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recordPrayerResponse, arg.PostID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const withdrawPrayerResponses = `-- name: WithdrawPrayerResponses :execrows
+DELETE FROM prayer_responses
+WHERE post_id = $1 AND user_id = $2
+`
+
+type WithdrawPrayerResponsesParams struct {
+	PostID uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) WithdrawPrayerResponses(ctx context.Context, arg WithdrawPrayerResponsesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, withdrawPrayerResponses, arg.PostID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
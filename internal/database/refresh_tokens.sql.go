@@ -36,6 +36,20 @@ func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams
 	return i, err
 }
 
+const deleteExpiredTokens = `-- name: DeleteExpiredTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at <= NOW()
+OR revoked_at IS NOT NULL
+`
+
+func (q *Queries) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getUserByToken = `-- name: GetUserByToken :one
 SELECT token, user_id, created_at, updated_at, expires_at, revoked_at FROM refresh_tokens
 WHERE token = $1
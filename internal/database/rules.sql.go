@@ -0,0 +1,237 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: rules.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const acceptRules = `-- name: AcceptRules :exec
+INSERT INTO group_rules_acceptances (group_id, user_id, version)
+VALUES ($1, $2, $3)
+ON CONFLICT (group_id, user_id)
+DO UPDATE SET version = EXCLUDED.version, accepted_at = NOW()
+`
+
+type AcceptRulesParams struct {
+	GroupID uuid.UUID
+	UserID  uuid.UUID
+	Version int32
+}
+
+func (q *Queries) AcceptRules(ctx context.Context, arg AcceptRulesParams) error {
+	_, err := q.db.ExecContext(ctx, acceptRules, arg.GroupID, arg.UserID, arg.Version)
+	return err
+}
+
+const createRulesVersion = `-- name: CreateRulesVersion :one
+INSERT INTO group_rules_versions (group_id, version, rules, created_by)
+SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3
+FROM group_rules_versions
+WHERE group_id = $1
+RETURNING id, group_id, version, rules, created_by, created_at
+`
+
+type CreateRulesVersionParams struct {
+	GroupID   uuid.UUID
+	Rules     string
+	CreatedBy uuid.NullUUID
+}
+
+func (q *Queries) CreateRulesVersion(ctx context.Context, arg CreateRulesVersionParams) (GroupRulesVersion, error) {
+	row := q.db.QueryRowContext(ctx, createRulesVersion, arg.GroupID, arg.Rules, arg.CreatedBy)
+	var i GroupRulesVersion
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Version,
+		&i.Rules,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAcceptedRulesVersion = `-- name: GetAcceptedRulesVersion :one
+SELECT version
+FROM group_rules_acceptances
+WHERE group_id = $1 AND user_id = $2
+`
+
+type GetAcceptedRulesVersionParams struct {
+	GroupID uuid.UUID
+	UserID  uuid.UUID
+}
+
+func (q *Queries) GetAcceptedRulesVersion(ctx context.Context, arg GetAcceptedRulesVersionParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getAcceptedRulesVersion, arg.GroupID, arg.UserID)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const getLatestRulesVersion = `-- name: GetLatestRulesVersion :one
+SELECT id, group_id, version, rules, created_by, created_at
+FROM group_rules_versions
+WHERE group_id = $1
+ORDER BY version DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestRulesVersion(ctx context.Context, groupID uuid.UUID) (GroupRulesVersion, error) {
+	row := q.db.QueryRowContext(ctx, getLatestRulesVersion, groupID)
+	var i GroupRulesVersion
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Version,
+		&i.Rules,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRulesAcceptances = `-- name: GetRulesAcceptances :many
+SELECT
+    users.id,
+    users.username,
+    COALESCE(group_rules_acceptances.version, 0)::INTEGER AS accepted_version,
+    group_rules_acceptances.accepted_at
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN group_rules_acceptances
+    ON group_rules_acceptances.group_id = users_groups.group_id
+    AND group_rules_acceptances.user_id = users_groups.user_id
+WHERE users_groups.group_id = $1
+AND NOT users_groups.is_banned
+ORDER BY users.username ASC
+`
+
+type GetRulesAcceptancesRow struct {
+	ID              uuid.UUID
+	Username        string
+	AcceptedVersion int32
+	AcceptedAt      sql.NullTime
+}
+
+func (q *Queries) GetRulesAcceptances(ctx context.Context, groupID uuid.UUID) ([]GetRulesAcceptancesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRulesAcceptances, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRulesAcceptancesRow
+	for rows.Next() {
+		var i GetRulesAcceptancesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.AcceptedVersion,
+			&i.AcceptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRulesVersion = `-- name: GetRulesVersion :one
+SELECT id, group_id, version, rules, created_by, created_at
+FROM group_rules_versions
+WHERE group_id = $1 AND version = $2
+`
+
+type GetRulesVersionParams struct {
+	GroupID uuid.UUID
+	Version int32
+}
+
+func (q *Queries) GetRulesVersion(ctx context.Context, arg GetRulesVersionParams) (GroupRulesVersion, error) {
+	row := q.db.QueryRowContext(ctx, getRulesVersion, arg.GroupID, arg.Version)
+	var i GroupRulesVersion
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Version,
+		&i.Rules,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRulesVersions = `-- name: GetRulesVersions :many
+SELECT
+    group_rules_versions.id,
+    group_rules_versions.version,
+    group_rules_versions.rules,
+    group_rules_versions.created_at,
+    COALESCE(users.username, '')::TEXT AS created_by_username
+FROM group_rules_versions
+LEFT JOIN users ON users.id = group_rules_versions.created_by
+WHERE group_rules_versions.group_id = $1
+ORDER BY group_rules_versions.version DESC
+`
+
+type GetRulesVersionsRow struct {
+	ID                uuid.UUID
+	Version           int32
+	Rules             string
+	CreatedAt         sql.NullTime
+	CreatedByUsername string
+}
+
+func (q *Queries) GetRulesVersions(ctx context.Context, groupID uuid.UUID) ([]GetRulesVersionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRulesVersions, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRulesVersionsRow
+	for rows.Next() {
+		var i GetRulesVersionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Version,
+			&i.Rules,
+			&i.CreatedAt,
+			&i.CreatedByUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockGroupRules = `-- name: LockGroupRules :exec
+SELECT id FROM groups
+WHERE id = $1
+FOR UPDATE
+`
+
+// Serializes rules saves for a group so each one gets the next version number
+func (q *Queries) LockGroupRules(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockGroupRules, id)
+	return err
+}
@@ -63,8 +63,26 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getPrivacySettings = `-- name: GetPrivacySettings :one
+SELECT email_visibility, hide_from_directory
+FROM users
+WHERE id = $1
+`
+
+type GetPrivacySettingsRow struct {
+	EmailVisibility   string
+	HideFromDirectory bool
+}
+
+func (q *Queries) GetPrivacySettings(ctx context.Context, id uuid.UUID) (GetPrivacySettingsRow, error) {
+	row := q.db.QueryRowContext(ctx, getPrivacySettings, id)
+	var i GetPrivacySettingsRow
+	err := row.Scan(&i.EmailVisibility, &i.HideFromDirectory)
+	return i, err
+}
+
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, created_at, updated_at, hashed_password, is_active
+SELECT id, username, email, created_at, updated_at, hashed_password, is_active, email_visibility, hide_from_directory
 FROM users
 WHERE id = $1
 `
@@ -80,6 +98,8 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.UpdatedAt,
 		&i.HashedPassword,
 		&i.IsActive,
+		&i.EmailVisibility,
+		&i.HideFromDirectory,
 	)
 	return i, err
 }
@@ -114,9 +134,11 @@ func (q *Queries) GetUserGroupIDs(ctx context.Context, userID uuid.UUID) ([]uuid
 }
 
 const getUserGroupRole = `-- name: GetUserGroupRole :one
-SELECT role
+SELECT users_groups.role
 FROM users_groups
-WHERE user_id = $1 AND group_id = $2
+JOIN groups ON groups.id = users_groups.group_id
+WHERE users_groups.user_id = $1 AND users_groups.group_id = $2
+AND groups.deleted_at IS NULL
 `
 
 type GetUserGroupRoleParams struct {
@@ -132,7 +154,7 @@ func (q *Queries) GetUserGroupRole(ctx context.Context, arg GetUserGroupRolePara
 }
 
 const getUserIDByEmail = `-- name: GetUserIDByEmail :one
-SELECT id, username, email, created_at, updated_at, hashed_password, is_active
+SELECT id, username, email, created_at, updated_at, hashed_password, is_active, email_visibility, hide_from_directory
 FROM users
 WHERE email = $1
 `
@@ -148,6 +170,8 @@ func (q *Queries) GetUserIDByEmail(ctx context.Context, email string) (User, err
 		&i.UpdatedAt,
 		&i.HashedPassword,
 		&i.IsActive,
+		&i.EmailVisibility,
+		&i.HideFromDirectory,
 	)
 	return i, err
 }
@@ -161,6 +185,23 @@ func (q *Queries) ResetUsers(ctx context.Context) error {
 	return err
 }
 
+const updatePrivacySettings = `-- name: UpdatePrivacySettings :exec
+UPDATE users
+SET email_visibility = $2, hide_from_directory = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdatePrivacySettingsParams struct {
+	ID                uuid.UUID
+	EmailVisibility   string
+	HideFromDirectory bool
+}
+
+func (q *Queries) UpdatePrivacySettings(ctx context.Context, arg UpdatePrivacySettingsParams) error {
+	_, err := q.db.ExecContext(ctx, updatePrivacySettings, arg.ID, arg.EmailVisibility, arg.HideFromDirectory)
+	return err
+}
+
 const updateUserPassword = `-- name: UpdateUserPassword :exec
 UPDATE users
 SET hashed_password = $1
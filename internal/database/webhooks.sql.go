@@ -0,0 +1,391 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: webhooks.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (group_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, group_id, url, secret, events, is_active, created_by, created_at, updated_at
+`
+
+type CreateWebhookParams struct {
+	GroupID   uuid.UUID
+	Url       string
+	Secret    string
+	Events    []string
+	CreatedBy uuid.NullUUID
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, createWebhook,
+		arg.GroupID,
+		arg.Url,
+		arg.Secret,
+		pq.Array(arg.Events),
+		arg.CreatedBy,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+VALUES ($1, $2, $3)
+RETURNING id, webhook_id, event, payload, status, attempts, response_status, response_body, last_error, created_at, delivered_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	WebhookID uuid.UUID
+	Event     string
+	Payload   json.RawMessage
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookDelivery, arg.WebhookID, arg.Event, arg.Payload)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND group_id = $2
+`
+
+type DeleteWebhookParams struct {
+	ID      uuid.UUID
+	GroupID uuid.UUID
+}
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhook, arg.ID, arg.GroupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDeliveryForSend = `-- name: GetDeliveryForSend :one
+SELECT
+    webhook_deliveries.id,
+    webhook_deliveries.event,
+    webhook_deliveries.payload,
+    webhook_deliveries.attempts,
+    webhooks.url,
+    webhooks.secret,
+    webhooks.is_active
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+WHERE webhook_deliveries.id = $1
+`
+
+type GetDeliveryForSendRow struct {
+	ID       uuid.UUID
+	Event    string
+	Payload  json.RawMessage
+	Attempts int32
+	Url      string
+	Secret   string
+	IsActive bool
+}
+
+func (q *Queries) GetDeliveryForSend(ctx context.Context, id uuid.UUID) (GetDeliveryForSendRow, error) {
+	row := q.db.QueryRowContext(ctx, getDeliveryForSend, id)
+	var i GetDeliveryForSendRow
+	err := row.Scan(
+		&i.ID,
+		&i.Event,
+		&i.Payload,
+		&i.Attempts,
+		&i.Url,
+		&i.Secret,
+		&i.IsActive,
+	)
+	return i, err
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, group_id, url, secret, events, is_active, created_by, created_at, updated_at
+FROM webhooks
+WHERE id = $1 AND group_id = $2
+`
+
+type GetWebhookParams struct {
+	ID      uuid.UUID
+	GroupID uuid.UUID
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhook, arg.ID, arg.GroupID)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
+SELECT id, webhook_id, event, payload, status, attempts, response_status, response_body, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetWebhookDeliveriesParams struct {
+	WebhookID uuid.UUID
+	Limit     int32
+	Offset    int32
+}
+
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveries, arg.WebhookID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.Event,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.ResponseBody,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, webhook_id, event, payload, status, attempts, response_status, response_body, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2
+`
+
+type GetWebhookDeliveryParams struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookDelivery, arg.ID, arg.WebhookID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const getWebhooks = `-- name: GetWebhooks :many
+SELECT id, group_id, url, secret, events, is_active, created_by, created_at, updated_at
+FROM webhooks
+WHERE group_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetWebhooks(ctx context.Context, groupID uuid.UUID) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooks, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhooksForEvent = `-- name: GetWebhooksForEvent :many
+SELECT id
+FROM webhooks
+WHERE group_id = $1
+AND is_active
+AND $2::TEXT = ANY(events)
+`
+
+type GetWebhooksForEventParams struct {
+	GroupID uuid.UUID
+	Event   string
+}
+
+func (q *Queries) GetWebhooksForEvent(ctx context.Context, arg GetWebhooksForEventParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooksForEvent, arg.GroupID, arg.Event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordWebhookAttempt = `-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    response_body = $4,
+    last_error = $5,
+    delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END
+WHERE id = $1
+`
+
+type RecordWebhookAttemptParams struct {
+	ID             uuid.UUID
+	Status         string
+	ResponseStatus sql.NullInt32
+	ResponseBody   string
+	LastError      string
+}
+
+func (q *Queries) RecordWebhookAttempt(ctx context.Context, arg RecordWebhookAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, recordWebhookAttempt,
+		arg.ID,
+		arg.Status,
+		arg.ResponseStatus,
+		arg.ResponseBody,
+		arg.LastError,
+	)
+	return err
+}
+
+const updateWebhook = `-- name: UpdateWebhook :one
+UPDATE webhooks
+SET url = $3, events = $4, is_active = $5, updated_at = NOW()
+WHERE id = $1 AND group_id = $2
+RETURNING id, group_id, url, secret, events, is_active, created_by, created_at, updated_at
+`
+
+type UpdateWebhookParams struct {
+	ID       uuid.UUID
+	GroupID  uuid.UUID
+	Url      string
+	Events   []string
+	IsActive bool
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhook,
+		arg.ID,
+		arg.GroupID,
+		arg.Url,
+		pq.Array(arg.Events),
+		arg.IsActive,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
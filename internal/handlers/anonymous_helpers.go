@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrAnonymousNotAllowed = errors.New("this group does not allow anonymous posts")
+
+const anonymousAuthor = "Anonymous"
+
+// verifyAnonymousAllowed checks the group permits hiding the author's name
+func (a *APIConfig) verifyAnonymousAllowed(ctx context.Context, groupID uuid.UUID) error {
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if !group.AllowAnonymous {
+		return ErrAnonymousNotAllowed
+	}
+	return nil
+}
+
+// maskPostAuthor hides the author of an anonymous post unless the viewer
+// wrote it or can see anonymous authors. Comments on the post are masked too.
+func maskPostAuthor(post *Post, viewerID uuid.UUID, canSeeAuthors bool) {
+	if post.Anonymous && !canSeeAuthors && post.UserID != viewerID {
+		post.UserID = uuid.Nil
+		post.Author = anonymousAuthor
+	}
+	for i := range post.Comments {
+		maskCommentAuthor(&post.Comments[i], viewerID, canSeeAuthors)
+	}
+}
+
+func maskCommentAuthor(comment *Comment, viewerID uuid.UUID, canSeeAuthors bool) {
+	if comment.Anonymous && !canSeeAuthors && comment.UserID != viewerID {
+		comment.UserID = uuid.Nil
+		comment.Author = anonymousAuthor
+	}
+	for i := range comment.Replies {
+		maskCommentAuthor(&comment.Replies[i], viewerID, canSeeAuthors)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidAudience = errors.New("audience must be 'members', 'leaders' or 'custom'")
+var ErrInvalidAudienceMembers = errors.New("a custom audience needs between 1 and 50 current members of the group")
+
+const (
+	audienceMembers = "members"
+	audienceLeaders = "leaders"
+	audienceCustom  = "custom"
+
+	maxAudienceMembers = 50
+)
+
+var validAudiences = []string{audienceMembers, audienceLeaders, audienceCustom}
+
+// resolvePostAudience validates the requested audience and returns the
+// de-duplicated member list for custom audiences
+func resolvePostAudience(req PostRequest) (string, []uuid.UUID, error) {
+	audience := strings.ToLower(strings.TrimSpace(req.Audience))
+	if audience == "" {
+		audience = audienceMembers
+	}
+	if !slices.Contains(validAudiences, audience) {
+		return "", nil, ErrInvalidAudience
+	}
+	if audience != audienceCustom {
+		return audience, nil, nil
+	}
+
+	var userIDs []uuid.UUID
+	for _, id := range req.AudienceUserIDs {
+		if id != uuid.Nil && !slices.Contains(userIDs, id) {
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 || len(userIDs) > maxAudienceMembers {
+		return "", nil, ErrInvalidAudienceMembers
+	}
+
+	return audience, userIDs, nil
+}
+
+// getAudienceRoot returns the top-level post whose audience applies to a
+// post or comment
+func (a *APIConfig) getAudienceRoot(ctx context.Context, post database.GetPostByIDRow) (database.GetPostByIDRow, error) {
+	for post.ParentPostID.Valid {
+		parent, err := a.DBQueries.GetPostByID(ctx, post.ParentPostID.UUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return database.GetPostByIDRow{}, ErrPostNotFound
+			}
+			return database.GetPostByIDRow{}, err
+		}
+		post = parent
+	}
+	return post, nil
+}
+
+// canViewPost checks the viewer is in the audience of a post, or of the post
+// a comment belongs to. Hidden posts are reported as not found so their
+// existence isn't revealed. Callers check group membership separately.
+func (a *APIConfig) canViewPost(ctx context.Context, viewerID uuid.UUID, post database.GetPostByIDRow) error {
+	post, err := a.getAudienceRoot(ctx, post)
+	if err != nil {
+		return err
+	}
+
+	// The audience rules live in post_visible_to so every read path agrees
+	isLeader, err := a.isGroupLeader(ctx, viewerID, post.GroupID)
+	if err != nil {
+		return err
+	}
+	visible, err := a.DBQueries.IsPostVisibleTo(ctx, database.IsPostVisibleToParams{
+		PostID:         post.ID,
+		ViewerID:       viewerID,
+		ViewerIsLeader: isLeader,
+	})
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrPostNotFound
+	}
+
+	return nil
+}
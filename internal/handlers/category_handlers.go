@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (a *APIConfig) GetGroupCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := a.getGroupCategories(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving group categories: %v", err)
+		http.Error(w, "Error retrieving group categories", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(categories, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) CreateGroupCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := ParseJSON[PostCategoryRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	category, err := a.createGroupCategory(r.Context(), userID, groupID, req.Name)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrCategoryExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrInvalidCategoryName) || errors.Is(err, ErrTooManyCategories) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error creating group category: %v", err)
+		http.Error(w, "Error creating group category", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(category, w, http.StatusCreated); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Category %s added to group %v by user %v", category.Name, groupID, userID)
+}
+
+func (a *APIConfig) DeleteGroupCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID and category name from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	name := mux.Vars(r)["category"]
+
+	if err := a.deleteGroupCategory(r.Context(), userID, groupID, name); err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrCategoryNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error deleting group category: %v", err)
+		http.Error(w, "Error deleting group category", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Category %s removed from group %v by user %v", name, groupID, userID)
+}
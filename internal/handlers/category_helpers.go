@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCategory       = errors.New("unknown post category")
+	ErrInvalidCategoryName   = errors.New("category names must be 2-32 lowercase letters, numbers or underscores")
+	ErrCategoryExists        = errors.New("category already exists")
+	ErrCategoryNotFound      = errors.New("custom category not found")
+	ErrTooManyCategories     = errors.New("group has reached the custom category limit")
+	ErrAnnouncementAdminOnly = errors.New("only admins can post announcements")
+)
+
+const (
+	categoryPrayerRequest = "prayer_request"
+	categoryPraiseReport  = "praise_report"
+	categoryAnnouncement  = "announcement"
+	categoryTestimony     = "testimony"
+
+	maxCustomCategories = 20
+)
+
+// Every group has these, custom categories are added per group
+var builtInCategories = []string{
+	categoryPrayerRequest,
+	categoryPraiseReport,
+	categoryAnnouncement,
+	categoryTestimony,
+}
+
+var categoryNamePattern = regexp.MustCompile(`^[a-z0-9_]{2,32}$`)
+
+// normalizeCategoryName lowercases a name and turns spaces into underscores,
+// so "Missions Update" becomes "missions_update"
+func normalizeCategoryName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// resolvePostCategory checks the category exists in the group and the user
+// may post in it. An empty category means a prayer request.
+func (a *APIConfig) resolvePostCategory(ctx context.Context, userID, groupID uuid.UUID, category string) (string, error) {
+	category = normalizeCategoryName(category)
+	if category == "" {
+		return categoryPrayerRequest, nil
+	}
+
+	if category == categoryAnnouncement {
+		if err := a.isAdmin(ctx, userID, groupID); err != nil {
+			if errors.Is(err, ErrUserNotAdmin) {
+				return "", ErrAnnouncementAdminOnly
+			}
+			return "", err
+		}
+		return category, nil
+	}
+
+	if err := a.verifyCategoryExists(ctx, groupID, category); err != nil {
+		return "", err
+	}
+
+	return category, nil
+}
+
+// verifyCategoryExists checks a normalized category is built in or was added
+// by the group
+func (a *APIConfig) verifyCategoryExists(ctx context.Context, groupID uuid.UUID, category string) error {
+	if slices.Contains(builtInCategories, category) {
+		return nil
+	}
+
+	exists, err := a.DBQueries.GroupCategoryExists(ctx, database.GroupCategoryExistsParams{
+		GroupID: groupID,
+		Name:    category,
+	})
+	if err != nil {
+		return fmt.Errorf("error checking group category: %w", err)
+	}
+	if !exists {
+		return ErrInvalidCategory
+	}
+
+	return nil
+}
+
+func (a *APIConfig) getGroupCategories(ctx context.Context, userID, groupID uuid.UUID) ([]PostCategory, error) {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrUserNotMember
+	}
+
+	custom, err := a.DBQueries.GetGroupCategories(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving group categories: %w", err)
+	}
+
+	categories := make([]PostCategory, 0, len(builtInCategories)+len(custom))
+	for _, name := range builtInCategories {
+		categories = append(categories, PostCategory{
+			Name:      name,
+			BuiltIn:   true,
+			AdminOnly: name == categoryAnnouncement,
+		})
+	}
+	for _, category := range custom {
+		categories = append(categories, PostCategory{Name: category.Name})
+	}
+
+	return categories, nil
+}
+
+func (a *APIConfig) createGroupCategory(ctx context.Context, userID, groupID uuid.UUID, name string) (PostCategory, error) {
+	// Only admins can add categories
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return PostCategory{}, err
+	}
+
+	name = normalizeCategoryName(name)
+	if !categoryNamePattern.MatchString(name) {
+		return PostCategory{}, ErrInvalidCategoryName
+	}
+	if slices.Contains(builtInCategories, name) {
+		return PostCategory{}, ErrCategoryExists
+	}
+
+	existing, err := a.DBQueries.GetGroupCategories(ctx, groupID)
+	if err != nil {
+		return PostCategory{}, fmt.Errorf("error retrieving group categories: %w", err)
+	}
+	if len(existing) >= maxCustomCategories {
+		return PostCategory{}, ErrTooManyCategories
+	}
+
+	created, err := a.DBQueries.CreateGroupCategory(ctx, database.CreateGroupCategoryParams{
+		GroupID:   groupID,
+		Name:      name,
+		CreatedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return PostCategory{}, fmt.Errorf("error creating group category: %w", err)
+	}
+	if created == 0 {
+		return PostCategory{}, ErrCategoryExists
+	}
+
+	return PostCategory{Name: name}, nil
+}
+
+// deleteGroupCategory removes a custom category, its posts go back to
+// being prayer requests
+func (a *APIConfig) deleteGroupCategory(ctx context.Context, userID, groupID uuid.UUID, name string) error {
+	// Only admins can remove categories
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return err
+	}
+
+	name = normalizeCategoryName(name)
+
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	deleted, err := qtx.DeleteGroupCategory(ctx, database.DeleteGroupCategoryParams{
+		GroupID: groupID,
+		Name:    name,
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting group category: %w", err)
+	}
+	if deleted == 0 {
+		return ErrCategoryNotFound
+	}
+
+	err = qtx.ResetPostCategory(ctx, database.ResetPostCategoryParams{
+		GroupID:  groupID,
+		Category: name,
+	})
+	if err != nil {
+		return fmt.Errorf("error moving posts out of category: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+func (a *APIConfig) CommitToPrayHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := ParseJSON[CommitmentRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	commitment, err := a.commitToPray(r.Context(), userID, groupID, postID, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidFrequency) || errors.Is(err, ErrInvalidCommitmentEnd) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrRequestAnswered) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error saving prayer commitment: %v", err)
+		http.Error(w, "Failed to save prayer commitment", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(commitment, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("User %v committed to pray %s for post %v until %s", userID, commitment.Frequency, postID, commitment.Until)
+}
+
+func (a *APIConfig) CancelCommitmentHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	err = a.cancelCommitment(r.Context(), userID, groupID, postID)
+	if err != nil {
+		if errors.Is(err, ErrPostNotFound) || errors.Is(err, ErrCommitmentNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error cancelling prayer commitment: %v", err)
+		http.Error(w, "Failed to cancel prayer commitment", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("User %v cancelled their prayer commitment for post %v", userID, postID)
+}
+
+func (a *APIConfig) GetMyCommitmentsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	commitments, err := a.getMyCommitments(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error retrieving prayer commitments: %v", err)
+		http.Error(w, "Failed to retrieve prayer commitments", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(commitments, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/jobs"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidFrequency = errors.New("frequency must be daily or weekly")
+var ErrInvalidCommitmentEnd = errors.New("until must be an RFC3339 time in the future and at most a year away")
+var ErrRequestAnswered = errors.New("this request has already been answered")
+var ErrCommitmentNotFound = errors.New("no active commitment for this request")
+
+const maxCommitmentDuration = 365 * 24 * time.Hour
+
+// Reasons recorded when a commitment ends
+const (
+	commitmentEndedAnswered = "answered"
+	commitmentEndedDeleted  = "deleted"
+)
+
+// commitToPray starts a commitment to pray for a request, or replaces the
+// frequency and end date of an existing one
+func (a *APIConfig) commitToPray(ctx context.Context, userID, groupID, postID uuid.UUID, req CommitmentRequest) (Commitment, error) {
+	frequency := strings.ToLower(strings.TrimSpace(req.Frequency))
+	if frequency == "" {
+		frequency = jobs.CommitmentDaily
+	}
+	interval, ok := jobs.CommitmentInterval(frequency)
+	if !ok {
+		return Commitment{}, ErrInvalidFrequency
+	}
+
+	now := time.Now()
+	until, err := time.Parse(time.RFC3339, req.Until)
+	if err != nil || !until.After(now) || until.After(now.Add(maxCommitmentDuration)) {
+		return Commitment{}, ErrInvalidCommitmentEnd
+	}
+
+	post, err := a.getPrayablePost(ctx, userID, groupID, postID)
+	if err != nil {
+		return Commitment{}, err
+	}
+	if post.Status == statusAnswered {
+		return Commitment{}, ErrRequestAnswered
+	}
+
+	commitment, err := a.DBQueries.UpsertPrayerCommitment(ctx, database.UpsertPrayerCommitmentParams{
+		PostID:         postID,
+		UserID:         userID,
+		Frequency:      frequency,
+		EndsAt:         until,
+		NextReminderAt: now.Add(interval),
+	})
+	if err != nil {
+		return Commitment{}, fmt.Errorf("error saving prayer commitment: %w", err)
+	}
+	a.Activity.Touch(userID, groupID)
+
+	return Commitment{
+		ID:             commitment.ID,
+		PostID:         commitment.PostID,
+		GroupID:        groupID,
+		Frequency:      commitment.Frequency,
+		Until:          commitment.EndsAt.Format(time.RFC3339),
+		NextReminderAt: commitment.NextReminderAt.Format(time.RFC3339),
+		LastRemindedAt: formatNullTime(commitment.LastRemindedAt),
+		CreatedAt:      formatNullTime(commitment.CreatedAt),
+	}, nil
+}
+
+func (a *APIConfig) cancelCommitment(ctx context.Context, userID, groupID, postID uuid.UUID) error {
+	if _, err := a.getPrayablePost(ctx, userID, groupID, postID); err != nil {
+		return err
+	}
+
+	count, err := a.DBQueries.CancelPrayerCommitment(ctx, database.CancelPrayerCommitmentParams{
+		PostID: postID,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("error cancelling prayer commitment: %w", err)
+	}
+	if count == 0 {
+		return ErrCommitmentNotFound
+	}
+
+	return nil
+}
+
+func (a *APIConfig) getMyCommitments(ctx context.Context, userID uuid.UUID) ([]Commitment, error) {
+	rows, err := a.DBQueries.GetActiveCommitmentsForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving prayer commitments: %w", err)
+	}
+
+	commitments := make([]Commitment, 0, len(rows))
+	for _, row := range rows {
+		// Spans groups, so anonymous requests stay anonymous unless they're the member's own
+		author := row.AuthorUsername
+		if row.IsAnonymous && row.AuthorID != userID {
+			author = anonymousAuthor
+		}
+
+		commitments = append(commitments, Commitment{
+			ID:             row.ID,
+			PostID:         row.PostID,
+			GroupID:        row.GroupID,
+			GroupName:      row.GroupName,
+			Author:         author,
+			Content:        row.Content,
+			Frequency:      row.Frequency,
+			Until:          row.EndsAt.Format(time.RFC3339),
+			NextReminderAt: row.NextReminderAt.Format(time.RFC3339),
+			LastRemindedAt: formatNullTime(row.LastRemindedAt),
+			CreatedAt:      formatNullTime(row.CreatedAt),
+		})
+	}
+
+	return commitments, nil
+}
+
+// endCommitmentsForPost stops reminders for a request. Failures are only
+// logged because the reminder sweep also ends these commitments.
+func (a *APIConfig) endCommitmentsForPost(ctx context.Context, postID uuid.UUID, reason string) {
+	count, err := a.DBQueries.EndCommitmentsForPost(ctx, database.EndCommitmentsForPostParams{
+		PostID:    postID,
+		EndReason: reason,
+	})
+	if err != nil {
+		log.Printf("Error ending prayer commitments for post %v: %v", postID, err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Ended %d prayer commitments for post %v (%s)", count, postID, reason)
+	}
+}
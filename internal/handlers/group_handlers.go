@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net/http"
 	"strings"
@@ -37,6 +39,14 @@ func (a *APIConfig) CreateGroupHandler(w http.ResponseWriter, r *http.Request) {
 	group, err := a.createGroup(r.Context(), userID, groupReq)
 	if err != nil {
 		log.Printf("Error creating group: %v", err)
+		if errors.Is(err, ErrGroupNameTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrDescriptionTooLong) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Error creating group", http.StatusInternalServerError)
 		return
 	}
@@ -104,20 +114,32 @@ func (a *APIConfig) PromoteUserHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Perform checks and promote user
 	role := strings.ToLower(promoteReq.Role)
-	err = a.promoteUser(r.Context(), groupID, targetUserID, role)
+	pending, err := a.promoteUser(r.Context(), userID, groupID, targetUserID, role)
 	if err != nil {
 		if errors.Is(err, ErrUserNotMember) {
 			http.Error(w, "Target user is not a member of the group", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrActionAlreadyPending) || errors.Is(err, ErrNoSecondAdmin) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, "Failed to promote user", http.StatusInternalServerError)
 		return
 	}
 
+	// Demoting an admin waits for another admin under the approval policy
+	if pending != nil {
+		if err := CreateJSONResponse(pending, w, http.StatusAccepted); err != nil {
+			log.Printf("Error creating JSON response: %v", err)
+		}
+		log.Printf("Demotion of user %v in group %v awaiting approval", targetUserID, groupID)
+		return
+	}
+
 	// Respond with success
 	w.WriteHeader(http.StatusNoContent)
 	log.Printf("User %v promoted to %s in group %v", targetUserID, role, groupID)
-
 }
 
 func (a *APIConfig) GetPostFeedHandler(w http.ResponseWriter, r *http.Request) {
@@ -147,13 +169,35 @@ func (a *APIConfig) GetPostFeedHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Perform checks and get posts for the group
-	posts, err := a.getPostFeed(r.Context(), userID, groupID, limit, offset)
+	// Perform checks and get posts for the group, optionally filtered.
+	// A cursor switches to keyset pagination and returns a page object.
+	filter := postFeedFilter{
+		Category: r.URL.Query().Get("category"),
+		Status:   r.URL.Query().Get("status"),
+	}
+	var posts any
+	if wantsCursorPage(r) {
+		posts, err = a.getPostFeedPage(r.Context(), userID, groupID, limit, r.URL.Query().Get("cursor"), filter)
+	} else {
+		posts, err = a.getPostFeed(r.Context(), userID, groupID, limit, offset, filter)
+	}
 	if err != nil {
 		if errors.Is(err, ErrUserNotMember) {
 			http.Error(w, "User is not a member of the group", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrInvalidCategory) {
+			http.Error(w, "Invalid category parameter", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrInvalidPostStatus) {
+			http.Error(w, "Invalid status parameter", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrInvalidCursor) || errors.Is(err, ErrInvalidPageSize) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to get post feed", http.StatusInternalServerError)
 		return
 	}
@@ -180,8 +224,8 @@ func (a *APIConfig) GetPostCountHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get the post count for the group
-	count, err := a.getGroupPostCount(r.Context(), userID, groupID)
+	// Get the post counts for the group
+	response, err := a.getGroupPostCount(r.Context(), userID, groupID)
 	if err != nil {
 		if errors.Is(err, ErrUserNotMember) {
 			http.Error(w, "User is not a member of the group", http.StatusForbidden)
@@ -192,9 +236,6 @@ func (a *APIConfig) GetPostCountHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Create JSON response
-	response := PostCountResponse{
-		PostCount: count,
-	}
 	if err := CreateJSONResponse(response, w, http.StatusOK); err != nil {
 		log.Printf("Error creating JSON response: %v", err)
 		return
@@ -217,20 +258,39 @@ func (a *APIConfig) DeleteGroupHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the user is an admin of the group
-	if err = a.isAdmin(r.Context(), userID, groupID); err != nil {
+	// Parse the optional name confirmation, an empty body skips it
+	var deleteReq DeleteGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&deleteReq); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Archive the group, the owner can restore it within the restore window
+	pending, err := a.softDeleteGroup(r.Context(), userID, groupID, deleteReq.ConfirmName)
+	if err != nil {
 		if errors.Is(err, ErrUserNotAdmin) {
 			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
 			return
 		}
-		http.Error(w, "Failed to check admin status", http.StatusInternalServerError)
+		if errors.Is(err, ErrConfirmMismatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrActionAlreadyPending) || errors.Is(err, ErrNoSecondAdmin) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Error deleting group: %v", err)
+		http.Error(w, "Failed to delete group", http.StatusInternalServerError)
 		return
 	}
 
-	// Delete the group from the database
-	err = a.DBQueries.DeleteGroup(r.Context(), groupID)
-	if err != nil {
-		http.Error(w, "Failed to delete group", http.StatusInternalServerError)
+	// Another admin has to approve before the group is deleted
+	if pending != nil {
+		if err := CreateJSONResponse(pending, w, http.StatusAccepted); err != nil {
+			log.Printf("Error creating JSON response: %v", err)
+		}
+		log.Printf("Deletion of group %v requested by user %v, awaiting approval", groupID, userID)
 		return
 	}
 
@@ -238,6 +298,73 @@ func (a *APIConfig) DeleteGroupHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Group %v deleted successfully by user %v", groupID, userID)
 }
 
+func (a *APIConfig) RestoreGroupHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	group, err := a.restoreGroup(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrNotGroupOwner) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrGroupNotDeleted) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrRestoreExpired) {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		}
+		if errors.Is(err, ErrRestoreNameTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Error restoring group: %v", err)
+		http.Error(w, "Failed to restore group", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(group, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Group %v restored by user %v", groupID, userID)
+}
+
+func (a *APIConfig) GetDeletedGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	groups, err := a.getDeletedGroups(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error retrieving deleted groups: %v", err)
+		http.Error(w, "Failed to retrieve deleted groups", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(groups, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
 func (a *APIConfig) ModerateUserHandler(w http.ResponseWriter, r *http.Request) {
 	// Validate JWT and extract user ID
 	userID, err := a.getUserIDFromToken(r)
@@ -273,7 +400,7 @@ func (a *APIConfig) ModerateUserHandler(w http.ResponseWriter, r *http.Request)
 		targetUserID,
 		userID,
 		strings.ToLower(moderateReq.Action),
-		moderateReq.Reason,
+		moderateReq,
 	)
 	if err != nil {
 		if errors.Is(err, ErrUserNotAdmin) {
@@ -284,6 +411,22 @@ func (a *APIConfig) ModerateUserHandler(w http.ResponseWriter, r *http.Request)
 			http.Error(w, "Target user is not a member of the group", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrCannotModAdmin) {
+			http.Error(w, "Cannot moderate an admin", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidModAction) {
+			http.Error(w, "Invalid moderation action", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrNotModerated) {
+			http.Error(w, "Target user does not have an active kick, mute or ban", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrInvalidDuration) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to moderate user", http.StatusInternalServerError)
 		return
 	}
@@ -385,9 +528,51 @@ func (a *APIConfig) GetGroupMembersHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Kicked/muted/banned members are listed separately for admins
+	status := strings.ToLower(r.URL.Query().Get("status"))
+	if status != "" && status != "active" {
+		moderated, err := a.getModeratedMembers(r.Context(), userID, groupID, status)
+		if err != nil {
+			if errors.Is(err, ErrUserNotAdmin) {
+				http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+				return
+			}
+			if errors.Is(err, ErrInvalidStatus) {
+				http.Error(w, "Invalid status parameter", http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Error retrieving group members", http.StatusInternalServerError)
+			return
+		}
+
+		if err := CreateJSONResponse(moderated, w, http.StatusOK); err != nil {
+			log.Printf("Error creating JSON response: %v", err)
+			return
+		}
+
+		log.Printf("%s members retrieved successfully for group ID: %s", status, groupID)
+		return
+	}
+
+	// Admins can filter the directory by recent activity
+	activity := strings.ToLower(r.URL.Query().Get("activity"))
+	inactiveDays, err := parseIntQueryParam(r, "inactive_days", defaultInactiveDays)
+	if err != nil || inactiveDays < 1 || inactiveDays > 3650 {
+		http.Error(w, "Invalid inactive_days parameter", http.StatusBadRequest)
+		return
+	}
+
 	// Fetch group members
-	members, err := a.getGroupMembers(r.Context(), groupID)
+	members, err := a.getGroupMembers(r.Context(), userID, groupID, activity, inactiveDays)
 	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidActivity) {
+			http.Error(w, "Invalid activity parameter", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Error retrieving group members", http.StatusInternalServerError)
 		return
 	}
@@ -526,4 +711,89 @@ func (a *APIConfig) ChangeGroupRulesHandler(w http.ResponseWriter, r *http.Reque
 	log.Printf("Group rules updated successfully for group %v by user %v", groupID, userID)
 }
 
-func (a *APIConfig) ChangeGroupDescriptionHandler(w http.ResponseWriter, r *http.Request) {}
+func (a *APIConfig) ChangeGroupDescriptionHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse the new description from the request body
+	descriptionReq, err := ParseJSON[UpdateGroupDescriptionRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Update the group description in the database
+	_, _, err = a.updateGroupSettings(r.Context(), userID, groupID, GroupSettingsRequest{
+		Description: &descriptionReq.Description,
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrDescriptionTooLong) {
+			http.Error(w, "Description text is too long", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Error updating group description", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Group description updated successfully for group %v by user %v", groupID, userID)
+}
+
+func (a *APIConfig) GetModerationLogHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse query parameters for pagination
+	limit, err := parseIntQueryParam(r, "limit", 50)
+	if err != nil || limit < 1 || limit > 200 {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := a.getModerationLog(r.Context(), userID, groupID, limit, offset)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving moderation history: %v", err)
+		http.Error(w, "Error retrieving moderation history", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(entries, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/jobs"
 	"github.com/TheJa750/PrayerPals/internal/validation"
 	"github.com/google/uuid"
 )
@@ -26,6 +27,24 @@ var (
 	ErrCannotModAdmin   = errors.New("cannot moderate an admin")
 	ErrInvalidJWT       = errors.New("invalid JWT token")
 	ErrRulesTooLong     = errors.New("group rules cannot exceed 1500 characters")
+	ErrInvalidModAction = errors.New("invalid moderation action")
+	ErrNotModerated     = errors.New("user does not have an active kick, mute or ban")
+	ErrInvalidStatus    = errors.New("invalid member status filter")
+	ErrInvalidDuration  = errors.New("moderation duration must be between 1 hour and 365 days")
+	ErrConfirmMismatch  = errors.New("confirmation does not match the group name")
+	ErrGroupNotDeleted  = errors.New("group is not deleted")
+	ErrNotGroupOwner    = errors.New("only the group owner can restore the group")
+	ErrRestoreExpired   = errors.New("the restore window for this group has passed")
+	ErrRestoreNameTaken = errors.New("the owner has another group with this name, rename it before restoring")
+	ErrInvalidActivity  = errors.New("activity filter must be active or inactive")
+)
+
+const (
+	defaultKickDuration   = 7 * 24 * time.Hour
+	defaultMuteDuration   = 24 * time.Hour
+	maxModerationDuration = 365 * 24 * time.Hour
+
+	defaultInactiveDays = 30
 )
 
 func (a *APIConfig) leaveGroupChecks(ctx context.Context, userID, groupID uuid.UUID) error {
@@ -92,66 +111,332 @@ func (a *APIConfig) promoteUserChecks(ctx context.Context, userID, groupID uuid.
 	return nil
 }
 
-func (a *APIConfig) getPostFeed(ctx context.Context, userID, groupID uuid.UUID, limit, offset int) ([]Post, error) {
-	// Verify if the user is a member of the group
-	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+// postFeedFilter narrows the feed, empty fields match every post
+type postFeedFilter struct {
+	Category string
+	Status   string
+	Archived bool // Show archived posts instead of the active feed
+}
+
+func (a *APIConfig) getPostFeed(ctx context.Context, userID, groupID uuid.UUID, limit, offset int, filter postFeedFilter) ([]Post, error) {
+	params, err := a.feedParams(ctx, userID, groupID, filter)
 	if err != nil {
 		return nil, err
 	}
-	if !isMember {
-		return nil, ErrUserNotMember
-	}
+	params.Limit = int32(limit)
+	params.Offset = int32(offset)
 
 	// Fetch posts for the group
-	posts, err := a.DBQueries.GetPostsForFeed(ctx, database.GetPostsForFeedParams{
-		GroupID: groupID,
-		Limit:   int32(limit),
-		Offset:  int32(offset),
-	})
+	posts, err := a.DBQueries.GetPostsForFeed(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert database posts to API Post structs
 	jsonPosts := make([]Post, len(posts))
 	for i, post := range posts {
-		jsonPosts[i] = Post{
-			ID:           post.ID,
-			GroupID:      post.GroupID,
-			UserID:       post.UserID,
-			Content:      post.Content,
-			CreatedAt:    post.CreatedAt.Time.Format(time.RFC3339),
-			Author:       post.Username.String,
-			CommentCount: post.CommentCount,
-		}
+		jsonPosts[i] = toJSONFeedPost(post, userID, params.ViewerIsLeader)
 	}
 
 	return jsonPosts, nil
 }
 
+// getPostFeedPage returns a page of the feed before or after a cursor, which
+// stays stable while new posts arrive
+func (a *APIConfig) getPostFeedPage(ctx context.Context, userID, groupID uuid.UUID, limit int, cursorParam string, filter postFeedFilter) (PostPage, error) {
+	if err := validatePageSize(limit); err != nil {
+		return PostPage{}, err
+	}
+	cursor, err := decodeCursor(cursorParam)
+	if err != nil {
+		return PostPage{}, err
+	}
+
+	params, err := a.feedParams(ctx, userID, groupID, filter)
+	if err != nil {
+		return PostPage{}, err
+	}
+
+	// Fetch one extra post to tell whether there's another page
+	var posts []database.GetPostsForFeedRow
+	if cursor != nil && cursor.Newer {
+		newer, err := a.DBQueries.GetPostsForFeedNewer(ctx, database.GetPostsForFeedNewerParams{
+			ViewerID:       params.ViewerID,
+			GroupID:        params.GroupID,
+			Archived:       params.Archived,
+			Category:       params.Category,
+			Status:         params.Status,
+			AfterTime:      cursor.CreatedAt,
+			AfterID:        cursor.ID,
+			ViewerIsLeader: params.ViewerIsLeader,
+			Limit:          int32(limit + 1),
+		})
+		if err != nil {
+			return PostPage{}, err
+		}
+		for i := len(newer) - 1; i >= 0; i-- {
+			posts = append(posts, database.GetPostsForFeedRow(newer[i]))
+		}
+	} else {
+		if cursor != nil {
+			params.BeforeTime = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+			params.BeforeID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+		}
+		params.Limit = int32(limit + 1)
+		posts, err = a.DBQueries.GetPostsForFeed(ctx, params)
+		if err != nil {
+			return PostPage{}, err
+		}
+	}
+
+	posts, next, prev := paginate(posts, limit, cursor, func(post database.GetPostsForFeedRow) (time.Time, uuid.UUID) {
+		return post.CreatedAt.Time, post.ID
+	})
+
+	page := PostPage{
+		Posts:      make([]Post, len(posts)),
+		NextCursor: next,
+		PrevCursor: prev,
+	}
+	for i, post := range posts {
+		page.Posts[i] = toJSONFeedPost(post, userID, params.ViewerIsLeader)
+	}
+
+	return page, nil
+}
+
+// feedParams checks the user can read the feed and validates the filter,
+// leaving the caller to fill in the page
+func (a *APIConfig) feedParams(ctx context.Context, userID, groupID uuid.UUID, filter postFeedFilter) (database.GetPostsForFeedParams, error) {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return database.GetPostsForFeedParams{}, err
+	}
+	if !isMember {
+		return database.GetPostsForFeedParams{}, ErrUserNotMember
+	}
+	a.Activity.Touch(userID, groupID)
+
+	category := normalizeCategoryName(filter.Category)
+	if category != "" {
+		if err := a.verifyCategoryExists(ctx, groupID, category); err != nil {
+			return database.GetPostsForFeedParams{}, err
+		}
+	}
+	status := strings.ToLower(strings.TrimSpace(filter.Status))
+	if status != "" && !slices.Contains(validPostStatuses, status) {
+		return database.GetPostsForFeedParams{}, ErrInvalidPostStatus
+	}
+
+	// Leaders see every post, others only posts whose audience includes them
+	isLeader, err := a.isGroupLeader(ctx, userID, groupID)
+	if err != nil {
+		return database.GetPostsForFeedParams{}, err
+	}
+
+	return database.GetPostsForFeedParams{
+		GroupID:        groupID,
+		Category:       category,
+		Status:         status,
+		ViewerID:       userID,
+		ViewerIsLeader: isLeader,
+		Archived:       filter.Archived,
+	}, nil
+}
+
+// toJSONFeedPost converts a feed row to an API Post, hiding anonymous authors
+// from the viewer
+func toJSONFeedPost(post database.GetPostsForFeedRow, viewerID uuid.UUID, isLeader bool) Post {
+	jsonPost := Post{
+		ID:           post.ID,
+		GroupID:      post.GroupID,
+		UserID:       post.UserID,
+		Content:      post.Content,
+		Category:     post.Category,
+		Status:       post.Status,
+		AnsweredAt:   formatNullTime(post.AnsweredAt),
+		AnswerNote:   post.AnswerNote,
+		PrayedCount:  post.PrayedCount,
+		PrayedByMe:   post.PrayedByMe,
+		CreatedAt:    post.CreatedAt.Time.Format(time.RFC3339),
+		Author:       post.Username.String,
+		Anonymous:    post.IsAnonymous,
+		Audience:     post.Audience,
+		CommentCount: post.CommentCount,
+		Pinned:       post.IsPinned,
+		Edited:       post.EditedAt.Valid,
+		EditedAt:     formatNullTime(post.EditedAt),
+		Archived:     post.ArchivedAt.Valid,
+		ArchivedAt:   formatNullTime(post.ArchivedAt),
+	}
+	maskPostAuthor(&jsonPost, viewerID, isLeader)
+	return jsonPost
+}
+
+// isGroupLeader reports whether the user is a group or organization admin,
+// for read paths that show leaders more than regular members
+func (a *APIConfig) isGroupLeader(ctx context.Context, userID, groupID uuid.UUID) (bool, error) {
+	err := a.isAdmin(ctx, userID, groupID)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ErrUserNotAdmin) {
+		return false, nil
+	}
+	return false, err
+}
+
 func (a *APIConfig) isAdmin(ctx context.Context, userID, groupID uuid.UUID) error {
 	// Check if the user is an admin of the group
 	userRole, err := a.DBQueries.GetUserGroupRole(ctx, database.GetUserGroupRoleParams{
 		UserID:  userID,
 		GroupID: groupID,
 	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil && userRole == "admin" {
+		return nil
+	}
+
+	// Admins of the group's organization can manage it without being members
+	isOrgAdmin, err := a.DBQueries.IsOrgAdminForGroup(ctx, database.IsOrgAdminForGroupParams{
+		ID:     groupID,
+		UserID: userID,
+	})
 	if err != nil {
 		return err
 	}
+	if isOrgAdmin {
+		return nil
+	}
+
+	return ErrUserNotAdmin
+}
 
-	if userRole != "admin" {
-		return ErrUserNotAdmin
+func moderationExpiry(action string, req ModerateUserRequest) (time.Time, error) {
+	now := time.Now()
+
+	// An explicit end time takes priority over a duration
+	if req.Until != "" {
+		until, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil {
+			return time.Time{}, ErrInvalidDuration
+		}
+		if until.Before(now.Add(time.Hour)) || until.After(now.Add(maxModerationDuration)) {
+			return time.Time{}, ErrInvalidDuration
+		}
+		return until, nil
+	}
+
+	if req.DurationHours == 0 {
+		if action == "mute" {
+			return now.Add(defaultMuteDuration), nil
+		}
+		return now.Add(defaultKickDuration), nil
+	}
+
+	duration := time.Duration(req.DurationHours) * time.Hour
+	if duration < time.Hour || duration > maxModerationDuration {
+		return time.Time{}, ErrInvalidDuration
+	}
+
+	return now.Add(duration), nil
+}
+
+func (a *APIConfig) moderateUser(ctx context.Context, groupID, targetID, adminID uuid.UUID, action string, req ModerateUserRequest) error {
+	// Apply the action and record it together so the history can't miss one
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	if err := a.applyModeration(ctx, qtx, groupID, targetID, adminID, action, req); err != nil {
+		return err
+	}
+
+	err = logModeration(ctx, qtx, database.LogModerationParams{
+		GroupID:      groupID,
+		ActorID:      uuid.NullUUID{UUID: adminID, Valid: true},
+		Action:       action,
+		TargetUserID: uuid.NullUUID{UUID: targetID, Valid: true},
+		Reason:       req.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	a.dispatchWebhookEvent(ctx, groupID, eventMemberModerated, webhookMember{
+		UserID:  targetID,
+		Action:  action,
+		Reason:  req.Reason,
+		ActorID: &adminID,
+	})
+
+	return nil
+}
 
+// logModeration records an admin action in the group's moderation history,
+// q may be bound to a transaction
+func logModeration(ctx context.Context, q *database.Queries, entry database.LogModerationParams) error {
+	if err := q.LogModeration(ctx, entry); err != nil {
+		return fmt.Errorf("error recording moderation history: %w", err)
+	}
 	return nil
 }
 
-func (a *APIConfig) moderateUser(ctx context.Context, groupID, targetID, adminID uuid.UUID, action, reason string) error {
+func (a *APIConfig) getModerationLog(ctx context.Context, userID, groupID uuid.UUID, limit, offset int) ([]ModerationLogEntry, error) {
+	// Only admins can see the moderation history
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	entries, err := a.DBQueries.GetModerationLog(ctx, database.GetModerationLogParams{
+		GroupID: groupID,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving moderation history: %w", err)
+	}
+
+	jsonEntries := make([]ModerationLogEntry, len(entries))
+	for i, entry := range entries {
+		jsonEntries[i] = ModerationLogEntry{
+			ID:             entry.ID,
+			Action:         entry.Action,
+			ActorID:        entry.ActorID.UUID,
+			ActorUsername:  entry.ActorUsername,
+			TargetUserID:   entry.TargetUserID.UUID,
+			TargetUsername: entry.TargetUsername,
+			TargetPostID:   entry.TargetPostID.UUID,
+			Reason:         entry.Reason,
+			CreatedAt:      formatNullTime(entry.CreatedAt),
+		}
+	}
+
+	return jsonEntries, nil
+}
+
+// applyModeration writes the sanction through q, which may be bound to a
+// transaction
+func (a *APIConfig) applyModeration(ctx context.Context, q *database.Queries, groupID, targetID, adminID uuid.UUID, action string, req ModerateUserRequest) error {
 	// Check if the admin is an admin of the group
 	if err := a.isAdmin(ctx, adminID, groupID); err != nil {
 		return err
 	}
 
+	// Lifting a kick/ban targets users who fail the active member checks below
+	if action == "unban" || action == "unkick" || action == "unmute" {
+		return a.liftModeration(ctx, q, groupID, targetID, action)
+	}
+
 	// Verify if the target user is a member of the group
 	isMember, err := a.verifyUserInGroup(ctx, targetID, groupID)
 	if err != nil {
@@ -175,37 +460,119 @@ func (a *APIConfig) moderateUser(ctx context.Context, groupID, targetID, adminID
 
 	// Perform the moderation action
 	switch action {
-	case "kick": // Kick has a length of 7 days
-		return a.DBQueries.KickUser(ctx, database.KickUserParams{
-			GroupID:      groupID,
-			UserID:       targetID,
-			ModdedReason: reason,
-			ModdedBy:     uuid.NullUUID{UUID: adminID, Valid: true},
+	case "kick": // Kick defaults to 7 days
+		until, err := moderationExpiry(action, req)
+		if err != nil {
+			return err
+		}
+		return q.KickUser(ctx, database.KickUserParams{
+			GroupID:     groupID,
+			UserID:      targetID,
+			KickedUntil: sql.NullTime{Time: until, Valid: true},
+			KickReason:  req.Reason,
+			KickedBy:    uuid.NullUUID{UUID: adminID, Valid: true},
+		})
+	case "mute": // Muted users can read but not post, defaults to 24 hours
+		until, err := moderationExpiry(action, req)
+		if err != nil {
+			return err
+		}
+		return q.MuteUser(ctx, database.MuteUserParams{
+			GroupID:    groupID,
+			UserID:     targetID,
+			MutedUntil: sql.NullTime{Time: until, Valid: true},
+			MuteReason: req.Reason,
+			MutedBy:    uuid.NullUUID{UUID: adminID, Valid: true},
 		})
 	case "ban": // Ban is permanent
-		return a.DBQueries.BanUser(ctx, database.BanUserParams{
-			GroupID:      groupID,
-			UserID:       targetID,
-			ModdedReason: reason,
-			ModdedBy:     uuid.NullUUID{UUID: adminID, Valid: true},
+		return q.BanUser(ctx, database.BanUserParams{
+			GroupID:   groupID,
+			UserID:    targetID,
+			BanReason: req.Reason,
+			BannedBy:  uuid.NullUUID{UUID: adminID, Valid: true},
+		})
+	default:
+		return ErrInvalidModAction
+	}
+}
+
+func (a *APIConfig) liftModeration(ctx context.Context, q *database.Queries, groupID, targetID uuid.UUID, action string) error {
+	// Fetch the target's current kick/ban/mute status
+	modStatus, err := q.GetKickBanStatus(ctx, database.GetKickBanStatusParams{
+		UserID:  targetID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotMember
+		}
+		return err
+	}
+
+	switch action {
+	case "unban": // Also clears any kick so the user is fully restored
+		if !modStatus.IsBanned {
+			return ErrNotModerated
+		}
+		return q.UnbanUser(ctx, database.UnbanUserParams{
+			GroupID: groupID,
+			UserID:  targetID,
+		})
+	case "unkick":
+		if !modStatus.IsKicked || modStatus.IsBanned {
+			return ErrNotModerated
+		}
+		return q.UnkickUser(ctx, database.UnkickUserParams{
+			GroupID: groupID,
+			UserID:  targetID,
+		})
+	case "unmute":
+		if !modStatus.IsMuted || modStatus.IsBanned {
+			return ErrNotModerated
+		}
+		return q.UnmuteUser(ctx, database.UnmuteUserParams{
+			GroupID: groupID,
+			UserID:  targetID,
 		})
 	default:
-		return errors.New("invalid moderation action")
+		return ErrInvalidModAction
 	}
 }
 
-func (a *APIConfig) promoteUser(ctx context.Context, groupID, userID uuid.UUID, role string) error {
+// promoteUser changes a member's role. Under the approval policy demoting
+// an admin waits for another admin, so one admin can't strip the rest.
+func (a *APIConfig) promoteUser(ctx context.Context, adminID, groupID, userID uuid.UUID, role string) (*PendingAction, error) {
 	// Perform checks before promoting user
 	if err := a.promoteUserChecks(ctx, userID, groupID, role); err != nil {
-		return err
+		return nil, err
+	}
+
+	// The target already differs from role, so anything but admin is a demotion
+	if role != "admin" {
+		needsApproval, err := a.requiresSecondApproval(ctx, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if needsApproval {
+			pending, err := a.requestPendingAction(ctx, adminID, groupID, actionDemoteAdmin, uuid.NullUUID{UUID: userID, Valid: true})
+			if err != nil {
+				return nil, err
+			}
+			return &pending, nil
+		}
 	}
 
 	// Update user role in the database
-	return a.DBQueries.AdjustUserGroupRole(ctx, database.AdjustUserGroupRoleParams{
+	err := a.DBQueries.AdjustUserGroupRole(ctx, database.AdjustUserGroupRoleParams{
 		UserID:  userID,
 		GroupID: groupID,
 		Role:    role,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
 }
 
 func generateInviteCode(customPrefix string) string {
@@ -238,6 +605,10 @@ func generateInviteCode(customPrefix string) string {
 }
 
 func (a *APIConfig) createGroup(ctx context.Context, userID uuid.UUID, req GroupRequest) (Group, error) {
+	if len(req.Description) > 1000 {
+		return Group{}, ErrDescriptionTooLong
+	}
+
 	// Setting up query parameters
 	// Description can be null, so we use sql.NullString
 	valid := true
@@ -262,6 +633,9 @@ func (a *APIConfig) createGroup(ctx context.Context, userID uuid.UUID, req Group
 		if strings.Contains(err.Error(), "invite_code") && strings.Contains(err.Error(), "unique") {
 			return Group{}, fmt.Errorf("invite code collision, please try again")
 		}
+		if isGroupNameConflict(err) {
+			return Group{}, ErrGroupNameTaken
+		}
 		return Group{}, err
 	}
 
@@ -286,6 +660,125 @@ func (a *APIConfig) deleteGroup(ctx context.Context, groupID uuid.UUID) error {
 	return nil
 }
 
+// softDeleteGroup hides the group until it is restored or purged by the
+// background job once the restore window has passed. When the group requires
+// a second approval the returned pending action is waiting on another admin.
+func (a *APIConfig) softDeleteGroup(ctx context.Context, userID, groupID uuid.UUID, confirmName string) (*PendingAction, error) {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving group: %w", err)
+	}
+
+	// Guard against misclicks, a typed out name has to match when given
+	if confirm := strings.TrimSpace(confirmName); confirm != "" && confirm != group.Name {
+		return nil, ErrConfirmMismatch
+	}
+
+	needsApproval, err := a.requiresSecondApproval(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking approval policy: %w", err)
+	}
+	if needsApproval {
+		pending, err := a.requestPendingAction(ctx, userID, groupID, actionDeleteGroup, uuid.NullUUID{})
+		if err != nil {
+			return nil, err
+		}
+		return &pending, nil
+	}
+
+	_, err = a.DBQueries.SoftDeleteGroup(ctx, database.SoftDeleteGroupParams{
+		ID:        groupID,
+		DeletedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error deleting group: %w", err)
+	}
+
+	return nil, nil
+}
+
+func (a *APIConfig) restoreGroup(ctx context.Context, userID, groupID uuid.UUID) (Group, error) {
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Group{}, ErrGroupNotDeleted
+		}
+		return Group{}, fmt.Errorf("error retrieving group: %w", err)
+	}
+	if !group.DeletedAt.Valid {
+		return Group{}, ErrGroupNotDeleted
+	}
+	if group.OwnerID.Valid && group.OwnerID.UUID != userID {
+		return Group{}, ErrNotGroupOwner
+	}
+
+	// Without an owner the group and organization admins can restore it
+	if !group.OwnerID.Valid {
+		isLeader, err := a.DBQueries.IsGroupLeader(ctx, database.IsGroupLeaderParams{
+			UserID:  userID,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return Group{}, fmt.Errorf("error checking group leadership: %w", err)
+		}
+		if !isLeader {
+			return Group{}, ErrNotGroupOwner
+		}
+	}
+
+	restored, err := a.DBQueries.RestoreGroup(ctx, database.RestoreGroupParams{
+		ID: groupID,
+		DeletedAt: sql.NullTime{
+			Time:  time.Now().Add(-jobs.DeletedGroupRetention),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		// The name was freed when the group was deleted and may be in use again
+		if isGroupNameConflict(err) {
+			return Group{}, ErrRestoreNameTaken
+		}
+		return Group{}, fmt.Errorf("error restoring group: %w", err)
+	}
+	if restored == 0 {
+		return Group{}, ErrRestoreExpired
+	}
+
+	group.DeletedAt = sql.NullTime{}
+	return toJSONGroup(group), nil
+}
+
+func (a *APIConfig) getDeletedGroups(ctx context.Context, userID uuid.UUID) ([]DeletedGroup, error) {
+	groups, err := a.DBQueries.GetDeletedGroupsForOwner(ctx, database.GetDeletedGroupsForOwnerParams{
+		OwnerID: uuid.NullUUID{UUID: userID, Valid: true},
+		DeletedAt: sql.NullTime{
+			Time:  time.Now().Add(-jobs.DeletedGroupRetention),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deleted groups: %w", err)
+	}
+
+	jsonGroups := make([]DeletedGroup, len(groups))
+	for i, group := range groups {
+		jsonGroups[i] = DeletedGroup{
+			ID:           group.ID,
+			Name:         group.Name,
+			Description:  group.Description.String,
+			DeletedAt:    formatNullTime(group.DeletedAt),
+			RestoreUntil: group.DeletedAt.Time.Add(jobs.DeletedGroupRetention).Format(time.RFC3339),
+		}
+	}
+
+	return jsonGroups, nil
+}
+
 func (a *APIConfig) getGroupByInviteCode(ctx context.Context, inviteCode string) (Group, error) {
 	// Fetch the group by invite code
 	group, err := a.DBQueries.GetGroupByInviteCode(ctx, inviteCode)
@@ -302,6 +795,8 @@ func (a *APIConfig) getGroupByInviteCode(ctx context.Context, inviteCode string)
 		Description: group.Description.String,
 		OwnerID:     group.OwnerID.UUID,
 		InviteCode:  group.InviteCode,
+		AvatarURL:   group.AvatarUrl,
+		JoinMode:    group.JoinMode,
 	}
 
 	return jsonGroup, nil
@@ -347,6 +842,7 @@ func (a *APIConfig) getGroupByID(ctx context.Context, userID, groupID uuid.UUID)
 	if !isMember {
 		return Group{}, ErrUserNotMember
 	}
+	a.Activity.Touch(userID, groupID)
 
 	// Fetch the group by ID
 	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
@@ -354,19 +850,29 @@ func (a *APIConfig) getGroupByID(ctx context.Context, userID, groupID uuid.UUID)
 		return Group{}, fmt.Errorf("error retrieving group by ID: %w", err)
 	}
 
-	jsonGroup := Group{
-		ID:          group.ID,
-		Name:        group.Name,
-		Description: group.Description.String,
-		OwnerID:     group.OwnerID.UUID,
-		InviteCode:  group.InviteCode,
-		RulesInfo:   group.RulesInfo,
+	return toJSONGroup(group), nil
+}
+
+// getGroupMembers lists the member directory. Admins can narrow it to
+// members seen within, or not since, the last inactiveDays days.
+func (a *APIConfig) getGroupMembers(ctx context.Context, viewerID, groupID uuid.UUID, activity string, inactiveDays int) ([]GroupMember, error) {
+	// Admins see every member regardless of their privacy settings
+	err := a.isAdmin(ctx, viewerID, groupID)
+	if err != nil && !errors.Is(err, ErrUserNotAdmin) {
+		return nil, err
 	}
+	viewer := profileViewer{userID: viewerID, isAdmin: err == nil}
 
-	return jsonGroup, nil
-}
+	if activity != "" {
+		if !viewer.isAdmin {
+			return nil, ErrUserNotAdmin
+		}
+		if activity != "active" && activity != "inactive" {
+			return nil, ErrInvalidActivity
+		}
+	}
+	cutoff := time.Now().AddDate(0, 0, -inactiveDays)
 
-func (a *APIConfig) getGroupMembers(ctx context.Context, groupID uuid.UUID) ([]GroupMember, error) {
 	// Fetch group members
 	members, err := a.DBQueries.GetActiveMembers(ctx, groupID)
 	if err != nil {
@@ -374,14 +880,105 @@ func (a *APIConfig) getGroupMembers(ctx context.Context, groupID uuid.UUID) ([]G
 	}
 
 	// Convert database members to API GroupMember structs
-	jsonMembers := make([]GroupMember, len(members))
-	for i, member := range members {
-		jsonMembers[i] = GroupMember{
+	jsonMembers := make([]GroupMember, 0, len(members))
+	for _, member := range members {
+		if member.HideFromDirectory && !viewer.canSee(member.ID, visibilityAdmins) {
+			continue
+		}
+
+		// Members never seen count as inactive
+		recentlySeen := member.LastSeenAt.Valid && member.LastSeenAt.Time.After(cutoff)
+		if (activity == "active" && !recentlySeen) || (activity == "inactive" && recentlySeen) {
+			continue
+		}
+
+		jsonMember := GroupMember{
 			UserID:   member.ID,
 			Role:     member.Role,
-			Email:    member.Email,
 			Username: member.Username,
+			JoinedAt: formatNullTime(member.JoinedAt),
+		}
+		if viewer.canSee(member.ID, member.EmailVisibility) {
+			jsonMember.Email = member.Email
+		}
+		if viewer.isAdmin {
+			jsonMember.LastSeenAt = formatNullTime(member.LastSeenAt)
+		}
+		jsonMembers = append(jsonMembers, jsonMember)
+	}
+
+	return jsonMembers, nil
+}
+
+func (a *APIConfig) getModeratedMembers(ctx context.Context, userID, groupID uuid.UUID, status string) ([]ModeratedMember, error) {
+	// Only admins can see who has been kicked, muted or banned
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	var jsonMembers []ModeratedMember
+	switch status {
+	case "banned":
+		members, err := a.DBQueries.GetBannedMembers(ctx, groupID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving banned members: %w", err)
+		}
+		jsonMembers = make([]ModeratedMember, len(members))
+		for i, member := range members {
+			jsonMembers[i] = ModeratedMember{
+				UserID:      member.ID,
+				Username:    member.Username,
+				Email:       member.Email,
+				Role:        member.Role,
+				Status:      status,
+				Reason:      member.Reason,
+				ModeratedBy: member.ModeratedBy.UUID,
+				Moderator:   member.ModeratorUsername,
+				ModeratedAt: formatNullTime(member.ModeratedAt),
+			}
 		}
+	case "kicked":
+		members, err := a.DBQueries.GetKickedMembers(ctx, groupID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving kicked members: %w", err)
+		}
+		jsonMembers = make([]ModeratedMember, len(members))
+		for i, member := range members {
+			jsonMembers[i] = ModeratedMember{
+				UserID:      member.ID,
+				Username:    member.Username,
+				Email:       member.Email,
+				Role:        member.Role,
+				Status:      status,
+				Reason:      member.Reason,
+				ModeratedBy: member.ModeratedBy.UUID,
+				Moderator:   member.ModeratorUsername,
+				ModeratedAt: formatNullTime(member.ModeratedAt),
+				ExpiresAt:   formatNullTime(member.KickedUntil),
+			}
+		}
+	case "muted":
+		members, err := a.DBQueries.GetMutedMembers(ctx, groupID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving muted members: %w", err)
+		}
+		jsonMembers = make([]ModeratedMember, len(members))
+		for i, member := range members {
+			jsonMembers[i] = ModeratedMember{
+				UserID:      member.ID,
+				Username:    member.Username,
+				Email:       member.Email,
+				Role:        member.Role,
+				Status:      status,
+				Reason:      member.Reason,
+				ModeratedBy: member.ModeratedBy.UUID,
+				Moderator:   member.ModeratorUsername,
+				ModeratedAt: formatNullTime(member.ModeratedAt),
+				ExpiresAt:   formatNullTime(member.MutedUntil),
+			}
+		}
+	default:
+		return nil, ErrInvalidStatus
 	}
 
 	return jsonMembers, nil
@@ -421,7 +1018,23 @@ func (a *APIConfig) changeGroupRules(ctx context.Context, userID, groupID uuid.U
 		return ErrRulesTooLong
 	}
 
-	err = a.DBQueries.UpdateGroupRules(ctx, database.UpdateGroupRulesParams{
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("error retrieving group: %w", err)
+	}
+	if group.RulesInfo == newRules {
+		return nil
+	}
+
+	// Update the rules and record the new version together
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	err = qtx.UpdateGroupRules(ctx, database.UpdateGroupRulesParams{
 		ID:        groupID,
 		RulesInfo: newRules,
 	})
@@ -429,24 +1042,43 @@ func (a *APIConfig) changeGroupRules(ctx context.Context, userID, groupID uuid.U
 		return fmt.Errorf("error updating group rules: %w", err)
 	}
 
-	return nil
+	if err := recordRulesVersion(ctx, qtx, groupID, userID, newRules); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (a *APIConfig) getGroupPostCount(ctx context.Context, userID, groupID uuid.UUID) (int, error) {
+func (a *APIConfig) getGroupPostCount(ctx context.Context, userID, groupID uuid.UUID) (PostCountResponse, error) {
 	// Verify if the user is a member of the group
 	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
 	if err != nil {
-		return 0, err
+		return PostCountResponse{}, err
 	}
 	if !isMember {
-		return 0, ErrUserNotMember
+		return PostCountResponse{}, ErrUserNotMember
+	}
+
+	isLeader, err := a.isGroupLeader(ctx, userID, groupID)
+	if err != nil {
+		return PostCountResponse{}, err
 	}
 
-	// Fetch the count of parent posts in the group
-	count, err := a.DBQueries.GetPostCountByGroupID(ctx, groupID)
+	// Count parent posts the user can see in the group per category
+	counts, err := a.DBQueries.GetPostCountsByCategory(ctx, database.GetPostCountsByCategoryParams{
+		GroupID:        groupID,
+		ViewerID:       userID,
+		ViewerIsLeader: isLeader,
+	})
 	if err != nil {
-		return 0, fmt.Errorf("error retrieving post count for group: %w", err)
+		return PostCountResponse{}, fmt.Errorf("error retrieving post count for group: %w", err)
+	}
+
+	response := PostCountResponse{ByCategory: make(map[string]int64, len(counts))}
+	for _, count := range counts {
+		response.PostCount += int(count.PostCount)
+		response.ByCategory[count.Category] = count.PostCount
 	}
 
-	return int(count), nil
+	return response, nil
 }
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+)
+
+func TestDeletedGroupNameReuse(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	a := &APIConfig{DB: db, DBQueries: database.New(db)}
+
+	owner, err := a.DBQueries.CreateUser(ctx, database.CreateUserParams{
+		Username:       "owner",
+		Email:          "owner@example.com",
+		HashedPassword: "x",
+	})
+	if err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+
+	createAsAdmin := func() Group {
+		t.Helper()
+		group, err := a.createGroup(ctx, owner.ID, GroupRequest{Name: "Choir"})
+		if err != nil {
+			t.Fatalf("error creating group: %v", err)
+		}
+		err = a.DBQueries.AddUserToGroup(ctx, database.AddUserToGroupParams{
+			UserID:  owner.ID,
+			GroupID: group.ID,
+			Role:    "admin",
+		})
+		if err != nil {
+			t.Fatalf("error adding owner: %v", err)
+		}
+		return group
+	}
+
+	original := createAsAdmin()
+	if _, err := a.createGroup(ctx, owner.ID, GroupRequest{Name: "Choir"}); !errors.Is(err, ErrGroupNameTaken) {
+		t.Fatalf("duplicate name: error = %v, want ErrGroupNameTaken", err)
+	}
+
+	if _, err := a.softDeleteGroup(ctx, owner.ID, original.ID, ""); err != nil {
+		t.Fatalf("error deleting group: %v", err)
+	}
+
+	// The deleted group no longer holds on to its name
+	replacement := createAsAdmin()
+
+	if _, err := a.restoreGroup(ctx, owner.ID, original.ID); !errors.Is(err, ErrRestoreNameTaken) {
+		t.Fatalf("restore over reused name: error = %v, want ErrRestoreNameTaken", err)
+	}
+
+	rename := "Choir 2"
+	if _, _, err := a.updateGroupSettings(ctx, owner.ID, replacement.ID, GroupSettingsRequest{Name: &rename}); err != nil {
+		t.Fatalf("error renaming replacement: %v", err)
+	}
+	restored, err := a.restoreGroup(ctx, owner.ID, original.ID)
+	if err != nil {
+		t.Fatalf("restore after rename: %v", err)
+	}
+	if restored.Name != "Choir" {
+		t.Errorf("restored name = %q, want Choir", restored.Name)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+func (a *APIConfig) UpdateGroupSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse the settings to change from the request body
+	settingsReq, err := ParseJSON[GroupSettingsRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	group, pending, err := a.updateGroupSettings(r.Context(), userID, groupID, settingsReq)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrGroupNameTaken) ||
+			errors.Is(err, ErrActionAlreadyPending) ||
+			errors.Is(err, ErrNoSecondAdmin) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrInvalidGroupSettings) ||
+			errors.Is(err, ErrDescriptionTooLong) ||
+			errors.Is(err, ErrRulesTooLong) ||
+			errors.Is(err, ErrInvalidPostPolicy) ||
+			errors.Is(err, ErrInvalidJoinMode) ||
+			errors.Is(err, ErrInvalidEditWindow) ||
+			errors.Is(err, ErrInvalidAutoArchive) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error updating group settings: %v", err)
+		http.Error(w, "Error updating group settings", http.StatusInternalServerError)
+		return
+	}
+
+	// Other changes are saved, turning off second approval waits for another admin
+	if pending != nil {
+		if err := CreateJSONResponse(pending, w, http.StatusAccepted); err != nil {
+			log.Printf("Error creating JSON response: %v", err)
+			return
+		}
+		log.Printf("Disabling second approval for group %v requested by user %v", groupID, userID)
+		return
+	}
+
+	if err := CreateJSONResponse(group, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Group settings updated successfully for group %v by user %v", groupID, userID)
+}
+
+func (a *APIConfig) GetJoinRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	requests, err := a.getJoinRequests(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Error retrieving join requests", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(requests, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) ReviewJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID and requesting user ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	targetID, err := parseUUIDPathParam(r, "user_id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	reviewReq, err := ParseJSON[ReviewJoinRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	action := strings.ToLower(reviewReq.Action)
+	err = a.reviewJoinRequest(r.Context(), userID, groupID, targetID, action)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidReviewAction) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrJoinRequestNotFound) {
+			http.Error(w, "Join request not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error reviewing join request", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Join request from user %v for group %v %sd by user %v", targetID, groupID, action, userID)
+}
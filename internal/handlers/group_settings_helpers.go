@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/validation"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGroupNameTaken       = errors.New("you already own a group with this name")
+	ErrDescriptionTooLong   = errors.New("group description cannot exceed 1000 characters")
+	ErrInvalidPostPolicy    = errors.New("post policy must be 'everyone' or 'admins'")
+	ErrInvalidJoinMode      = errors.New("join mode must be 'open', 'approval' or 'closed'")
+	ErrInvalidGroupSettings = errors.New("invalid group settings")
+	ErrPostingRestricted    = errors.New("only admins can post in this group")
+	ErrGroupClosed          = errors.New("group is not accepting new members")
+	ErrJoinRequestNotFound  = errors.New("join request not found")
+	ErrInvalidReviewAction  = errors.New("review action must be 'approve' or 'deny'")
+	ErrInvalidEditWindow    = errors.New("edit window must be between 0 and 43200 minutes")
+	ErrInvalidAutoArchive   = errors.New("auto archive must be between 0 and 365 days")
+)
+
+// 30 days, 0 lets authors edit at any time
+const maxEditWindowMinutes = 43200
+
+// 0 turns automatic archiving off
+const maxAutoArchiveDays = 365
+
+var (
+	validPostPolicies = []string{"everyone", "admins"}
+	validJoinModes    = []string{"open", "approval", "closed"}
+)
+
+func toJSONGroup(group database.Group) Group {
+	return Group{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description.String,
+		OwnerID:     group.OwnerID.UUID,
+		InviteCode:  group.InviteCode,
+		RulesInfo:   group.RulesInfo,
+		AvatarURL:   group.AvatarUrl,
+		PostPolicy:  group.PostPolicy,
+		JoinMode:    group.JoinMode,
+		Listed:      group.IsListed,
+		OrgID:       group.OrganizationID.UUID,
+
+		RequireApproval: group.RequireSecondApproval,
+		RequireRules:    group.RequireRulesAcceptance,
+		EditWindow:      group.EditWindowMinutes,
+		AllowAnonymous:  group.AllowAnonymous,
+		AutoArchiveDays: group.AutoArchiveDays,
+	}
+}
+
+func isGroupNameConflict(err error) bool {
+	return strings.Contains(err.Error(), "groups_name_owner_id_key")
+}
+
+// updateGroupSettings saves the changed settings. Turning off second approval
+// while it is in force is itself held for another admin, so the returned
+// pending action is set when that part of the request is still waiting.
+func (a *APIConfig) updateGroupSettings(ctx context.Context, userID, groupID uuid.UUID, req GroupSettingsRequest) (Group, *PendingAction, error) {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return Group{}, nil, err
+	}
+
+	// Start from the current settings so omitted fields are kept
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return Group{}, nil, fmt.Errorf("error retrieving group by ID: %w", err)
+	}
+
+	params := database.UpdateGroupSettingsParams{
+		ID:          groupID,
+		Name:        group.Name,
+		Description: group.Description,
+		AvatarUrl:   group.AvatarUrl,
+		RulesInfo:   group.RulesInfo,
+		PostPolicy:  group.PostPolicy,
+		JoinMode:    group.JoinMode,
+		IsListed:    group.IsListed,
+
+		RequireSecondApproval:  group.RequireSecondApproval,
+		RequireRulesAcceptance: group.RequireRulesAcceptance,
+		EditWindowMinutes:      group.EditWindowMinutes,
+		AllowAnonymous:         group.AllowAnonymous,
+		AutoArchiveDays:        group.AutoArchiveDays,
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		result := validation.ValidateGroupName(name)
+		if !result.IsValid {
+			return Group{}, nil, fmt.Errorf("%w: %s", ErrInvalidGroupSettings, strings.Join(result.Errors, ", "))
+		}
+		params.Name = name
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if len(description) > 1000 {
+			return Group{}, nil, ErrDescriptionTooLong
+		}
+		params.Description = sql.NullString{String: description, Valid: description != ""}
+	}
+
+	if req.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*req.AvatarURL)
+		if avatarURL != "" { // Empty string removes the avatar
+			result := validation.ValidateImageURL(avatarURL)
+			if !result.IsValid {
+				return Group{}, nil, fmt.Errorf("%w: %s", ErrInvalidGroupSettings, strings.Join(result.Errors, ", "))
+			}
+		}
+		params.AvatarUrl = avatarURL
+	}
+
+	if req.Rules != nil {
+		if len(*req.Rules) > 1500 {
+			return Group{}, nil, ErrRulesTooLong
+		}
+		params.RulesInfo = *req.Rules
+	}
+
+	if req.PostPolicy != nil {
+		policy := strings.ToLower(strings.TrimSpace(*req.PostPolicy))
+		if !slices.Contains(validPostPolicies, policy) {
+			return Group{}, nil, ErrInvalidPostPolicy
+		}
+		params.PostPolicy = policy
+	}
+
+	if req.JoinMode != nil {
+		mode := strings.ToLower(strings.TrimSpace(*req.JoinMode))
+		if !slices.Contains(validJoinModes, mode) {
+			return Group{}, nil, ErrInvalidJoinMode
+		}
+		params.JoinMode = mode
+	}
+
+	if req.Listed != nil {
+		params.IsListed = *req.Listed
+	}
+
+	// One admin can't switch off the two admin rule on their own
+	disableApproval := false
+	if req.RequireApproval != nil {
+		if !*req.RequireApproval && group.RequireSecondApproval {
+			needsApproval, err := a.requiresSecondApproval(ctx, groupID)
+			if err != nil {
+				return Group{}, nil, fmt.Errorf("error checking approval policy: %w", err)
+			}
+			disableApproval = needsApproval
+		}
+		if !disableApproval {
+			params.RequireSecondApproval = *req.RequireApproval
+		}
+	}
+
+	if req.RequireRules != nil {
+		params.RequireRulesAcceptance = *req.RequireRules
+	}
+
+	if req.EditWindow != nil {
+		if *req.EditWindow < 0 || *req.EditWindow > maxEditWindowMinutes {
+			return Group{}, nil, ErrInvalidEditWindow
+		}
+		params.EditWindowMinutes = *req.EditWindow
+	}
+
+	if req.AllowAnonymous != nil {
+		params.AllowAnonymous = *req.AllowAnonymous
+	}
+
+	if req.AutoArchiveDays != nil {
+		if *req.AutoArchiveDays < 0 || *req.AutoArchiveDays > maxAutoArchiveDays {
+			return Group{}, nil, ErrInvalidAutoArchive
+		}
+		params.AutoArchiveDays = *req.AutoArchiveDays
+	}
+
+	// Save the settings, any new rules version and the approval request together
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Group{}, nil, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	updated, err := qtx.UpdateGroupSettings(ctx, params)
+	if err != nil {
+		if isGroupNameConflict(err) {
+			return Group{}, nil, ErrGroupNameTaken
+		}
+		return Group{}, nil, fmt.Errorf("error updating group settings: %w", err)
+	}
+
+	if params.RulesInfo != group.RulesInfo {
+		if err := recordRulesVersion(ctx, qtx, groupID, userID, params.RulesInfo); err != nil {
+			return Group{}, nil, err
+		}
+	}
+
+	var pending *PendingAction
+	if disableApproval {
+		action, err := createPendingAction(ctx, qtx, userID, groupID, actionDisableApproval, uuid.NullUUID{})
+		if err != nil {
+			return Group{}, nil, err
+		}
+		pending = &action
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Group{}, nil, err
+	}
+
+	return toJSONGroup(updated), pending, nil
+}
+
+func (a *APIConfig) verifyPostingAllowed(ctx context.Context, userID, groupID uuid.UUID) error {
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	// Announcement groups only allow admins to start new posts
+	if group.PostPolicy == "admins" {
+		if err := a.isAdmin(ctx, userID, groupID); err != nil {
+			if errors.Is(err, ErrUserNotAdmin) {
+				return ErrPostingRestricted
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *APIConfig) getJoinRequests(ctx context.Context, userID, groupID uuid.UUID) ([]JoinRequest, error) {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	requests, err := a.DBQueries.GetJoinRequests(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving join requests: %w", err)
+	}
+
+	jsonRequests := make([]JoinRequest, len(requests))
+	for i, request := range requests {
+		jsonRequests[i] = JoinRequest{
+			UserID:      request.ID,
+			Username:    request.Username,
+			Email:       request.Email,
+			RequestedAt: request.CreatedAt.Time.Format(time.RFC3339),
+		}
+	}
+
+	return jsonRequests, nil
+}
+
+func (a *APIConfig) reviewJoinRequest(ctx context.Context, adminID, groupID, targetID uuid.UUID, action string) error {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, adminID, groupID); err != nil {
+		return err
+	}
+
+	if action != "approve" && action != "deny" {
+		return ErrInvalidReviewAction
+	}
+
+	// Remove the request and add the member together so an approval can't
+	// lose the request without the user joining
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	// Remove the request either way
+	removed, err := qtx.DeleteJoinRequest(ctx, database.DeleteJoinRequestParams{
+		GroupID: groupID,
+		UserID:  targetID,
+	})
+	if err != nil {
+		return fmt.Errorf("error removing join request: %w", err)
+	}
+	if removed == 0 {
+		return ErrJoinRequestNotFound
+	}
+
+	if action == "approve" {
+		err = qtx.AddUserToGroup(ctx, database.AddUserToGroupParams{
+			UserID:  targetID,
+			GroupID: groupID,
+			Role:    "member",
+		})
+		if err != nil {
+			return fmt.Errorf("error adding user to group: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if action == "deny" {
+		return nil
+	}
+
+	a.dispatchWebhookEvent(ctx, groupID, eventMemberJoined, webhookMember{UserID: targetID, Role: "member"})
+
+	return nil
+}
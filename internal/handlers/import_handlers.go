@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (a *APIConfig) ImportMembersHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Imports are a dry run unless ?commit=true
+	commit := false
+	if val := r.URL.Query().Get("commit"); val != "" {
+		commit, err = strconv.ParseBool(val)
+		if err != nil {
+			http.Error(w, "Invalid commit parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// The request body is the CSV itself
+	rows, err := parseMemberCSV(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		if errors.Is(err, ErrInvalidCSV) || errors.Is(err, ErrImportEmpty) || errors.Is(err, ErrImportTooLarge) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	report, err := a.importMembers(r.Context(), userID, groupID, rows, commit)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		// Return the report so the admin can see which rows to fix
+		if errors.Is(err, ErrImportInvalid) {
+			if err := CreateJSONResponse(report, w, http.StatusUnprocessableEntity); err != nil {
+				log.Printf("Error creating JSON response: %v", err)
+			}
+			return
+		}
+		log.Printf("Error importing members: %v", err)
+		http.Error(w, "Error importing members", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(report, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	if report.Committed {
+		log.Printf("Imported members into group %v: %d added, %d invited", groupID, report.Added, report.Invited)
+	}
+}
+
+func (a *APIConfig) AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// The token comes from the invitation email
+	token := mux.Vars(r)["token"]
+	if token == "" {
+		http.Error(w, "Invalid invitation token", http.StatusBadRequest)
+		return
+	}
+
+	response, err := a.acceptInvitation(r.Context(), userID, token)
+	if err != nil {
+		if errors.Is(err, ErrInvitationNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrUserIsMember) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrUserKickedOrBanned) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Error accepting invitation: %v", err)
+		http.Error(w, "Error accepting invitation", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(response, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("User %v joined group %v from an invitation", userID, response.GroupID)
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/jobs"
+	"github.com/TheJa750/PrayerPals/internal/validation"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCSV     = errors.New("could not read CSV, expected columns name,email,role")
+	ErrImportEmpty    = errors.New("CSV contains no members")
+	ErrImportTooLarge = errors.New("CSV cannot contain more than 500 members")
+	ErrImportInvalid  = errors.New("CSV has invalid rows, nothing was imported")
+
+	ErrInvitationNotFound = errors.New("invitation not found or already used")
+)
+
+const (
+	maxImportRows  = 500
+	maxImportBytes = 1 << 20
+	importRole     = "member"
+)
+
+// parseMemberCSV reads name,email,role rows. A header row is skipped and an
+// empty role defaults to member.
+func parseMemberCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(rows) == 0 && len(record) >= 2 &&
+			strings.EqualFold(strings.TrimSpace(record[0]), "name") &&
+			strings.EqualFold(strings.TrimSpace(record[1]), "email") {
+			continue
+		}
+		// Skip blank lines padded with commas
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := ImportRow{Line: line}
+		if len(record) < 2 || len(record) > 3 {
+			row.Errors = append(row.Errors, "Expected name,email,role")
+		}
+		if len(record) > 0 {
+			row.Name = strings.TrimSpace(record[0])
+		}
+		if len(record) > 1 {
+			row.Email = strings.ToLower(strings.TrimSpace(record[1]))
+		}
+		if len(record) > 2 {
+			row.Role = strings.ToLower(strings.TrimSpace(record[2]))
+		}
+		rows = append(rows, row)
+
+		if len(rows) > maxImportRows {
+			return nil, ErrImportTooLarge
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	return rows, nil
+}
+
+// importMembers validates every row and reports what would happen. With
+// commit set, all rows are applied in a single transaction or none are.
+func (a *APIConfig) importMembers(ctx context.Context, userID, groupID uuid.UUID, rows []ImportRow, commit bool) (ImportReport, error) {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return ImportReport{}, err
+	}
+
+	report := ImportReport{Rows: rows}
+	userIDs := make(map[int]uuid.UUID)
+	seen := make(map[string]int)
+
+	for i := range report.Rows {
+		row := &report.Rows[i]
+
+		result := validation.ValidateEmail(row.Email)
+		if !result.IsValid {
+			row.Errors = append(row.Errors, result.Errors...)
+		}
+		if row.Role == "" {
+			row.Role = "member"
+		}
+		// Admins are promoted after they join rather than granted by a CSV,
+		// elevated rows still import but as members
+		if !slices.Contains(getValidRoles(), row.Role) {
+			row.Errors = append(row.Errors, fmt.Sprintf("Invalid role %q", row.Role))
+		} else if row.Role != importRole {
+			row.Warnings = append(row.Warnings, fmt.Sprintf("Role %q imported as %s, promote them once they join", row.Role, importRole))
+			row.Role = importRole
+		}
+		if first, ok := seen[row.Email]; ok && row.Email != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("Duplicate of line %d", first))
+		} else {
+			seen[row.Email] = row.Line
+		}
+
+		if len(row.Errors) > 0 {
+			row.Status = "invalid"
+			report.Invalid++
+			continue
+		}
+
+		// Unregistered emails get an invitation
+		user, err := a.DBQueries.GetUserIDByEmail(ctx, row.Email)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return ImportReport{}, fmt.Errorf("error looking up user by email: %w", err)
+			}
+			row.Status = "invite"
+			report.Invited++
+			continue
+		}
+
+		// Registered users are added unless they already have a membership row
+		status, err := a.DBQueries.GetKickBanStatus(ctx, database.GetKickBanStatusParams{
+			UserID:  user.ID,
+			GroupID: groupID,
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			row.Status = "add"
+			userIDs[i] = user.ID
+			report.Added++
+		case err != nil:
+			return ImportReport{}, fmt.Errorf("error checking membership: %w", err)
+		case status.IsBanned:
+			row.Status = "invalid"
+			row.Errors = append(row.Errors, "User is banned from the group")
+			report.Invalid++
+		default:
+			row.Status = "already_member"
+			report.Skipped++
+		}
+	}
+
+	if !commit {
+		return report, nil
+	}
+	if report.Invalid > 0 {
+		return report, ErrImportInvalid
+	}
+
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	for i, row := range report.Rows {
+		switch row.Status {
+		case "add":
+			err := qtx.AddUserToGroup(ctx, database.AddUserToGroupParams{
+				UserID:  userIDs[i],
+				GroupID: groupID,
+				Role:    row.Role,
+			})
+			if err != nil {
+				return ImportReport{}, fmt.Errorf("error adding %s to group: %w", row.Email, err)
+			}
+		case "invite":
+			token, err := newInvitationToken()
+			if err != nil {
+				return ImportReport{}, err
+			}
+			invitation, err := qtx.CreateGroupInvitation(ctx, database.CreateGroupInvitationParams{
+				GroupID:   groupID,
+				Email:     row.Email,
+				Name:      row.Name,
+				Role:      row.Role,
+				InvitedBy: uuid.NullUUID{UUID: userID, Valid: true},
+				Token:     token,
+			})
+			if err != nil {
+				return ImportReport{}, fmt.Errorf("error inviting %s: %w", row.Email, err)
+			}
+
+			// Queued in the same transaction so no email goes out for a rolled back import
+			payload := jobs.InvitationPayload{InvitationID: invitation.ID}
+			if err := jobs.Enqueue(ctx, qtx, jobs.KindSendInvitation, payload, time.Now()); err != nil {
+				return ImportReport{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ImportReport{}, err
+	}
+
+	report.Committed = true
+	return report, nil
+}
+
+// acceptInvitation adds the user to the group that sent the invitation
+// token. Holding the token from the email is what proves the invitation was
+// meant for them.
+func (a *APIConfig) acceptInvitation(ctx context.Context, userID uuid.UUID, token string) (UserJoinGroup, error) {
+	invitation, err := a.DBQueries.GetPendingInvitationByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return UserJoinGroup{}, ErrInvitationNotFound
+		}
+		return UserJoinGroup{}, fmt.Errorf("error retrieving invitation: %w", err)
+	}
+
+	// Kicked and banned members keep their membership row
+	status, err := a.DBQueries.GetKickBanStatus(ctx, database.GetKickBanStatusParams{
+		UserID:  userID,
+		GroupID: invitation.GroupID,
+	})
+	switch {
+	case err == nil && (status.IsBanned || status.IsKicked):
+		return UserJoinGroup{}, ErrUserKickedOrBanned
+	case err == nil:
+		return UserJoinGroup{}, ErrUserIsMember
+	case !errors.Is(err, sql.ErrNoRows):
+		return UserJoinGroup{}, fmt.Errorf("error checking membership: %w", err)
+	}
+
+	// Join and use up the invitation together
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return UserJoinGroup{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	accepted, err := qtx.MarkInvitationAccepted(ctx, invitation.ID)
+	if err != nil {
+		return UserJoinGroup{}, fmt.Errorf("error marking invitation accepted: %w", err)
+	}
+	if accepted == 0 {
+		return UserJoinGroup{}, ErrInvitationNotFound
+	}
+
+	err = qtx.AddUserToGroup(ctx, database.AddUserToGroupParams{
+		UserID:  userID,
+		GroupID: invitation.GroupID,
+		Role:    invitation.Role,
+	})
+	if err != nil {
+		return UserJoinGroup{}, fmt.Errorf("error adding invited user to group: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return UserJoinGroup{}, err
+	}
+
+	a.dispatchWebhookEvent(ctx, invitation.GroupID, eventMemberJoined, webhookMember{UserID: userID, Role: invitation.Role})
+
+	return UserJoinGroup{
+		UserID:    userID,
+		GroupID:   invitation.GroupID,
+		GroupName: invitation.GroupName,
+		Role:      invitation.Role,
+		Status:    "joined",
+	}, nil
+}
+
+func newInvitationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
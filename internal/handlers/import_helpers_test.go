@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+func TestParseMemberCSV(t *testing.T) {
+	rows, err := parseMemberCSV(strings.NewReader("name,email,role\nAnn, ANN@example.com ,Admin\n,,\nBob,bob@example.com\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ImportRow{
+		{Line: 2, Name: "Ann", Email: "ann@example.com", Role: "admin"},
+		{Line: 4, Name: "Bob", Email: "bob@example.com"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i := range want {
+		got := rows[i]
+		if got.Line != want[i].Line || got.Name != want[i].Name || got.Email != want[i].Email || got.Role != want[i].Role {
+			t.Errorf("row %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestImportMembersRoles(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	a := &APIConfig{DB: db, DBQueries: database.New(db)}
+
+	admin, err := a.DBQueries.CreateUser(ctx, database.CreateUserParams{
+		Username:       "importer",
+		Email:          "importer@example.com",
+		HashedPassword: "x",
+	})
+	if err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+	group, err := a.DBQueries.CreateGroup(ctx, database.CreateGroupParams{
+		Name:       "Import",
+		OwnerID:    uuid.NullUUID{UUID: admin.ID, Valid: true},
+		InviteCode: "IMPORT01",
+	})
+	if err != nil {
+		t.Fatalf("error creating group: %v", err)
+	}
+	err = a.DBQueries.AddUserToGroup(ctx, database.AddUserToGroupParams{
+		UserID:  admin.ID,
+		GroupID: group.ID,
+		Role:    "admin",
+	})
+	if err != nil {
+		t.Fatalf("error adding admin: %v", err)
+	}
+
+	rows := []ImportRow{
+		{Line: 1, Name: "Ann", Email: "ann@example.com", Role: "admin"},
+		{Line: 2, Name: "Bob", Email: "bob@example.com"},
+		{Line: 3, Name: "Cal", Email: "cal@example.com", Role: "owner"},
+	}
+	report, err := a.importMembers(ctx, admin.ID, group.ID, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role     string
+		status   string
+		warnings int
+	}{
+		{"member", "invite", 1}, // Elevated roles are downgraded, not rejected
+		{"member", "invite", 0},
+		{"owner", "invalid", 0},
+	}
+	for i, tt := range tests {
+		row := report.Rows[i]
+		if row.Role != tt.role || row.Status != tt.status || len(row.Warnings) != tt.warnings {
+			t.Errorf("line %d = role %q status %q warnings %v, want %q %q with %d warnings",
+				row.Line, row.Role, row.Status, row.Warnings, tt.role, tt.status, tt.warnings)
+		}
+	}
+	if report.Invited != 2 || report.Invalid != 1 {
+		t.Errorf("invited %d invalid %d, want 2 and 1", report.Invited, report.Invalid)
+	}
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"database/sql"
 	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -58,3 +60,11 @@ func parseInviteCodePathParam(r *http.Request, key string) (string, error) {
 
 	return valStr, nil
 }
+
+// formatNullTime returns an RFC3339 timestamp, or "" when the time is NULL
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
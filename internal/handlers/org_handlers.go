@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+func (a *APIConfig) CreateOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization details from the request body
+	orgReq, err := ParseJSON[OrganizationRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	org, err := a.createOrganization(r.Context(), userID, orgReq)
+	if err != nil {
+		if errors.Is(err, ErrInvalidOrgName) || errors.Is(err, ErrDescriptionTooLong) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error creating organization: %v", err)
+		http.Error(w, "Error creating organization", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(org, w, http.StatusCreated); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Organization %v created by user %v", org.ID, userID)
+}
+
+func (a *APIConfig) GetOrganizationsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	orgs, err := a.getOrganizationsForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error retrieving organizations: %v", err)
+		http.Error(w, "Error retrieving organizations", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(orgs, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) GetOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization ID from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	org, err := a.getOrganization(r.Context(), userID, orgID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrOrgNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error retrieving organization: %v", err)
+		http.Error(w, "Error retrieving organization", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(org, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) UpdateOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization ID from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse the settings to change from the request body
+	settingsReq, err := ParseJSON[OrganizationSettingsRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	org, err := a.updateOrganization(r.Context(), userID, orgID, settingsReq)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidOrgName) ||
+			errors.Is(err, ErrDescriptionTooLong) ||
+			errors.Is(err, ErrRulesTooLong) ||
+			errors.Is(err, ErrInvalidJoinMode) ||
+			errors.Is(err, ErrInvalidPostPolicy) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error updating organization: %v", err)
+		http.Error(w, "Error updating organization", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(org, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Organization %v updated by user %v", orgID, userID)
+}
+
+func (a *APIConfig) GetOrganizationAdminsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization ID from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	admins, err := a.getOrganizationAdmins(r.Context(), userID, orgID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving organization admins: %v", err)
+		http.Error(w, "Error retrieving organization admins", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(admins, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) AddOrganizationAdminHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization ID from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	adminReq, err := ParseJSON[OrganizationAdminRequest](r)
+	if err != nil || adminReq.Email == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	admin, err := a.addOrganizationAdmin(r.Context(), userID, orgID, adminReq.Email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrUserNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error adding organization admin: %v", err)
+		http.Error(w, "Error adding organization admin", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(admin, w, http.StatusCreated); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("User %v made admin of organization %v by user %v", admin.UserID, orgID, userID)
+}
+
+func (a *APIConfig) RemoveOrganizationAdminHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization and user IDs from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+	targetID, err := parseUUIDPathParam(r, "user_id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	err = a.removeOrganizationAdmin(r.Context(), userID, orgID, targetID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrUserIsOnlyOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrUserNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error removing organization admin: %v", err)
+		http.Error(w, "Error removing organization admin", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("User %v removed from organization %v by user %v", targetID, orgID, userID)
+}
+
+func (a *APIConfig) CreateOrganizationGroupHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization ID from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	groupReq, err := ParseJSON[GroupRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	group, err := a.createOrganizationGroup(r.Context(), userID, orgID, groupReq)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidGroupSettings) || errors.Is(err, ErrDescriptionTooLong) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrGroupNameTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Error creating organization group: %v", err)
+		http.Error(w, "Error creating group", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(group, w, http.StatusCreated); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Group %s created in organization %v by user %v", group.Name, orgID, userID)
+}
+
+func (a *APIConfig) GetOrganizationGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization ID from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	groups, err := a.getOrganizationGroups(r.Context(), userID, orgID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving organization groups: %v", err)
+		http.Error(w, "Error retrieving organization groups", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(groups, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) AttachGroupHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization and group IDs from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	err = a.attachGroupToOrganization(r.Context(), userID, orgID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) || errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Error attaching group to organization: %v", err)
+		http.Error(w, "Error attaching group to organization", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Group %v attached to organization %v by user %v", groupID, orgID, userID)
+}
+
+func (a *APIConfig) DetachGroupHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization and group IDs from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	err = a.detachGroupFromOrganization(r.Context(), userID, orgID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrGroupNotInOrg) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error detaching group from organization: %v", err)
+		http.Error(w, "Error detaching group from organization", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Group %v detached from organization %v by user %v", groupID, orgID, userID)
+}
+
+func (a *APIConfig) GetOrganizationMembershipHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the organization ID from the URL path
+	orgID, err := parseUUIDPathParam(r, "org_id")
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	membership, err := a.getOrganizationMembership(r.Context(), userID, orgID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotOrgAdmin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving organization membership: %v", err)
+		http.Error(w, "Error retrieving organization membership", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(membership, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
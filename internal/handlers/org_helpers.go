@@ -0,0 +1,446 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/validation"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotOrgAdmin    = errors.New("user is not an admin of the organization")
+	ErrInvalidOrgName     = errors.New("organization name must be between 1 and 100 characters")
+	ErrOrgNotFound        = errors.New("organization not found")
+	ErrUserIsOnlyOrgAdmin = errors.New("cannot remove the only organization admin")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrGroupNotInOrg      = errors.New("group does not belong to the organization")
+)
+
+func toJSONOrganization(org database.Organization) Organization {
+	return Organization{
+		ID:                org.ID,
+		Name:              org.Name,
+		Description:       org.Description,
+		OwnerID:           org.OwnerID.UUID,
+		DefaultRules:      org.DefaultRules,
+		DefaultJoinMode:   org.DefaultJoinMode,
+		DefaultPostPolicy: org.DefaultPostPolicy,
+	}
+}
+
+func validateOrgName(name string) error {
+	count := utf8.RuneCountInString(name)
+	if count == 0 || count > 100 {
+		return ErrInvalidOrgName
+	}
+	return nil
+}
+
+func (a *APIConfig) isOrgAdmin(ctx context.Context, userID, orgID uuid.UUID) error {
+	role, err := a.DBQueries.GetOrganizationRole(ctx, database.GetOrganizationRoleParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotOrgAdmin
+		}
+		return err
+	}
+
+	if role != "admin" {
+		return ErrUserNotOrgAdmin
+	}
+
+	return nil
+}
+
+func (a *APIConfig) createOrganization(ctx context.Context, userID uuid.UUID, req OrganizationRequest) (Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := validateOrgName(name); err != nil {
+		return Organization{}, err
+	}
+	description := strings.TrimSpace(req.Description)
+	if len(description) > 1000 {
+		return Organization{}, ErrDescriptionTooLong
+	}
+
+	// Create the organization and its first admin together
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Organization{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	org, err := qtx.CreateOrganization(ctx, database.CreateOrganizationParams{
+		Name:        name,
+		Description: description,
+		OwnerID:     uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return Organization{}, fmt.Errorf("error creating organization: %w", err)
+	}
+
+	err = qtx.AddOrganizationMember(ctx, database.AddOrganizationMemberParams{
+		OrganizationID: org.ID,
+		UserID:         userID,
+		Role:           "admin",
+	})
+	if err != nil {
+		return Organization{}, fmt.Errorf("error adding organization admin: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Organization{}, err
+	}
+
+	return toJSONOrganization(org), nil
+}
+
+func (a *APIConfig) getOrganization(ctx context.Context, userID, orgID uuid.UUID) (Organization, error) {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return Organization{}, err
+	}
+
+	org, err := a.DBQueries.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Organization{}, ErrOrgNotFound
+		}
+		return Organization{}, fmt.Errorf("error retrieving organization: %w", err)
+	}
+
+	return toJSONOrganization(org), nil
+}
+
+func (a *APIConfig) getOrganizationsForUser(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
+	orgs, err := a.DBQueries.GetOrganizationsForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving organizations: %w", err)
+	}
+
+	jsonOrgs := make([]Organization, len(orgs))
+	for i, org := range orgs {
+		jsonOrgs[i] = toJSONOrganization(org)
+	}
+
+	return jsonOrgs, nil
+}
+
+func (a *APIConfig) updateOrganization(ctx context.Context, userID, orgID uuid.UUID, req OrganizationSettingsRequest) (Organization, error) {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return Organization{}, err
+	}
+
+	// Start from the current settings so omitted fields are kept
+	org, err := a.DBQueries.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		return Organization{}, fmt.Errorf("error retrieving organization: %w", err)
+	}
+
+	params := database.UpdateOrganizationParams{
+		ID:                orgID,
+		Name:              org.Name,
+		Description:       org.Description,
+		DefaultRules:      org.DefaultRules,
+		DefaultJoinMode:   org.DefaultJoinMode,
+		DefaultPostPolicy: org.DefaultPostPolicy,
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := validateOrgName(name); err != nil {
+			return Organization{}, err
+		}
+		params.Name = name
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if len(description) > 1000 {
+			return Organization{}, ErrDescriptionTooLong
+		}
+		params.Description = description
+	}
+
+	if req.DefaultRules != nil {
+		if len(*req.DefaultRules) > 1500 {
+			return Organization{}, ErrRulesTooLong
+		}
+		params.DefaultRules = *req.DefaultRules
+	}
+
+	if req.DefaultJoinMode != nil {
+		mode := strings.ToLower(strings.TrimSpace(*req.DefaultJoinMode))
+		if !slices.Contains(validJoinModes, mode) {
+			return Organization{}, ErrInvalidJoinMode
+		}
+		params.DefaultJoinMode = mode
+	}
+
+	if req.DefaultPostPolicy != nil {
+		policy := strings.ToLower(strings.TrimSpace(*req.DefaultPostPolicy))
+		if !slices.Contains(validPostPolicies, policy) {
+			return Organization{}, ErrInvalidPostPolicy
+		}
+		params.DefaultPostPolicy = policy
+	}
+
+	updated, err := a.DBQueries.UpdateOrganization(ctx, params)
+	if err != nil {
+		return Organization{}, fmt.Errorf("error updating organization: %w", err)
+	}
+
+	return toJSONOrganization(updated), nil
+}
+
+func (a *APIConfig) getOrganizationAdmins(ctx context.Context, userID, orgID uuid.UUID) ([]OrganizationAdmin, error) {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	admins, err := a.DBQueries.GetOrganizationAdmins(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving organization admins: %w", err)
+	}
+
+	jsonAdmins := make([]OrganizationAdmin, len(admins))
+	for i, admin := range admins {
+		jsonAdmins[i] = OrganizationAdmin{
+			UserID:   admin.ID,
+			Username: admin.Username,
+			Email:    admin.Email,
+			Role:     admin.Role,
+		}
+	}
+
+	return jsonAdmins, nil
+}
+
+func (a *APIConfig) addOrganizationAdmin(ctx context.Context, userID, orgID uuid.UUID, email string) (OrganizationAdmin, error) {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return OrganizationAdmin{}, err
+	}
+
+	// Users are looked up by their unique email
+	user, err := a.DBQueries.GetUserIDByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return OrganizationAdmin{}, ErrUserNotFound
+		}
+		return OrganizationAdmin{}, fmt.Errorf("error retrieving user by email: %w", err)
+	}
+
+	err = a.DBQueries.AddOrganizationMember(ctx, database.AddOrganizationMemberParams{
+		OrganizationID: orgID,
+		UserID:         user.ID,
+		Role:           "admin",
+	})
+	if err != nil {
+		return OrganizationAdmin{}, fmt.Errorf("error adding organization admin: %w", err)
+	}
+
+	return OrganizationAdmin{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     "admin",
+	}, nil
+}
+
+func (a *APIConfig) removeOrganizationAdmin(ctx context.Context, userID, orgID, targetID uuid.UUID) error {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return err
+	}
+
+	// The organization must keep at least one admin
+	admins, err := a.DBQueries.GetOrganizationAdmins(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("error retrieving organization admins: %w", err)
+	}
+	if len(admins) == 1 && admins[0].ID == targetID {
+		return ErrUserIsOnlyOrgAdmin
+	}
+
+	removed, err := a.DBQueries.RemoveOrganizationMember(ctx, database.RemoveOrganizationMemberParams{
+		OrganizationID: orgID,
+		UserID:         targetID,
+	})
+	if err != nil {
+		return fmt.Errorf("error removing organization admin: %w", err)
+	}
+	if removed == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (a *APIConfig) createOrganizationGroup(ctx context.Context, userID, orgID uuid.UUID, req GroupRequest) (Group, error) {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return Group{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	result := validation.ValidateGroupName(name)
+	if !result.IsValid {
+		return Group{}, fmt.Errorf("%w: %s", ErrInvalidGroupSettings, strings.Join(result.Errors, ", "))
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if len(description) > 1000 {
+		return Group{}, ErrDescriptionTooLong
+	}
+
+	org, err := a.DBQueries.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		return Group{}, fmt.Errorf("error retrieving organization: %w", err)
+	}
+
+	// Create the group, its first admin and its first rules version together
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Group{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	// New groups inherit the organization's defaults
+	group, err := qtx.CreateOrganizationGroup(ctx, database.CreateOrganizationGroupParams{
+		Name: name,
+		Description: sql.NullString{
+			String: description,
+			Valid:  description != "",
+		},
+		OwnerID:        uuid.NullUUID{UUID: userID, Valid: true},
+		InviteCode:     generateInviteCode(""),
+		OrganizationID: uuid.NullUUID{UUID: orgID, Valid: true},
+		RulesInfo:      org.DefaultRules,
+		JoinMode:       org.DefaultJoinMode,
+		PostPolicy:     org.DefaultPostPolicy,
+	})
+	if err != nil {
+		if isGroupNameConflict(err) {
+			return Group{}, ErrGroupNameTaken
+		}
+		return Group{}, fmt.Errorf("error creating organization group: %w", err)
+	}
+
+	err = qtx.AddUserToGroup(ctx, database.AddUserToGroupParams{
+		UserID:  userID,
+		GroupID: group.ID,
+		Role:    "admin",
+	})
+	if err != nil {
+		return Group{}, fmt.Errorf("error adding group admin: %w", err)
+	}
+
+	// Inherited rules start the group's rules history
+	if group.RulesInfo != "" {
+		if err := recordRulesVersion(ctx, qtx, group.ID, userID, group.RulesInfo); err != nil {
+			return Group{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Group{}, err
+	}
+
+	return toJSONGroup(group), nil
+}
+
+func (a *APIConfig) getOrganizationGroups(ctx context.Context, userID, orgID uuid.UUID) ([]OrganizationGroup, error) {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+
+	groups, err := a.DBQueries.GetOrganizationGroups(ctx, uuid.NullUUID{UUID: orgID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving organization groups: %w", err)
+	}
+
+	jsonGroups := make([]OrganizationGroup, len(groups))
+	for i, group := range groups {
+		jsonGroups[i] = OrganizationGroup{
+			ID:          group.ID,
+			Name:        group.Name,
+			Description: group.Description.String,
+			OwnerID:     group.OwnerID.UUID,
+			JoinMode:    group.JoinMode,
+			PostPolicy:  group.PostPolicy,
+			MemberCount: group.MemberCount,
+		}
+	}
+
+	return jsonGroups, nil
+}
+
+func (a *APIConfig) getOrganizationMembership(ctx context.Context, userID, orgID uuid.UUID) (OrganizationMembership, error) {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return OrganizationMembership{}, err
+	}
+
+	summary, err := a.DBQueries.GetOrganizationMembershipSummary(ctx, uuid.NullUUID{UUID: orgID, Valid: true})
+	if err != nil {
+		return OrganizationMembership{}, fmt.Errorf("error retrieving organization membership: %w", err)
+	}
+
+	return OrganizationMembership{
+		GroupCount:        summary.GroupCount,
+		MembershipCount:   summary.MembershipCount,
+		UniqueMemberCount: summary.UniqueMemberCount,
+	}, nil
+}
+
+func (a *APIConfig) attachGroupToOrganization(ctx context.Context, userID, orgID, groupID uuid.UUID) error {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return err
+	}
+
+	// Only the group's own admins can hand it to an organization
+	role, err := a.DBQueries.GetUserGroupRole(ctx, database.GetUserGroupRoleParams{
+		UserID:  userID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotAdmin
+		}
+		return err
+	}
+	if role != "admin" {
+		return ErrUserNotAdmin
+	}
+
+	return a.DBQueries.SetGroupOrganization(ctx, database.SetGroupOrganizationParams{
+		ID:             groupID,
+		OrganizationID: uuid.NullUUID{UUID: orgID, Valid: true},
+	})
+}
+
+func (a *APIConfig) detachGroupFromOrganization(ctx context.Context, userID, orgID, groupID uuid.UUID) error {
+	if err := a.isOrgAdmin(ctx, userID, orgID); err != nil {
+		return err
+	}
+
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group.OrganizationID.UUID != orgID {
+		return ErrGroupNotInOrg
+	}
+
+	return a.DBQueries.SetGroupOrganization(ctx, database.SetGroupOrganizationParams{
+		ID:             groupID,
+		OrganizationID: uuid.NullUUID{},
+	})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+)
+
+func TestCreateOrganizationGroup(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	a := &APIConfig{DB: db, DBQueries: database.New(db)}
+
+	admin, err := a.DBQueries.CreateUser(ctx, database.CreateUserParams{
+		Username:       "orgadmin",
+		Email:          "orgadmin@example.com",
+		HashedPassword: "x",
+	})
+	if err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+
+	org, err := a.createOrganization(ctx, admin.ID, OrganizationRequest{Name: "Parish"})
+	if err != nil {
+		t.Fatalf("error creating organization: %v", err)
+	}
+	rules := "Be kind"
+	if _, err := a.updateOrganization(ctx, admin.ID, org.ID, OrganizationSettingsRequest{DefaultRules: &rules}); err != nil {
+		t.Fatalf("error setting default rules: %v", err)
+	}
+
+	_, err = a.createOrganizationGroup(ctx, admin.ID, org.ID, GroupRequest{
+		Name:        "Too long",
+		Description: strings.Repeat("a", 1001),
+	})
+	if !errors.Is(err, ErrDescriptionTooLong) {
+		t.Errorf("long description: error = %v, want ErrDescriptionTooLong", err)
+	}
+
+	group, err := a.createOrganizationGroup(ctx, admin.ID, org.ID, GroupRequest{Name: "Youth"})
+	if err != nil {
+		t.Fatalf("error creating group: %v", err)
+	}
+
+	// The creator can use the group straight away without relying on org admin rights
+	role, err := a.DBQueries.GetUserGroupRole(ctx, database.GetUserGroupRoleParams{
+		UserID:  admin.ID,
+		GroupID: group.ID,
+	})
+	if err != nil {
+		t.Fatalf("creator is not a member: %v", err)
+	}
+	if role != "admin" {
+		t.Errorf("creator role = %s, want admin", role)
+	}
+
+	latest, err := a.DBQueries.GetLatestRulesVersion(ctx, group.ID)
+	if err != nil {
+		t.Fatalf("inherited rules have no version: %v", err)
+	}
+	if latest.Version != 1 || latest.Rules != rules {
+		t.Errorf("rules version = %d %q, want 1 %q", latest.Version, latest.Rules, rules)
+	}
+}
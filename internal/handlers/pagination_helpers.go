@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrInvalidPageSize = errors.New("limit must be between 1 and 100")
+)
+
+const (
+	maxPageSize            = 100
+	defaultCommentPageSize = 20
+)
+
+// pageCursor is a position in a list ordered newest first by (created_at,
+// id). Clients only see it encoded so the format can change.
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Newer     bool      `json:"n,omitempty"` // Page towards newer items instead of older ones
+}
+
+func encodeCursor(createdAt time.Time, id uuid.UUID, newer bool) string {
+	data, _ := json.Marshal(pageCursor{CreatedAt: createdAt, ID: id, Newer: newer})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns nil for an empty cursor, the first page
+func decodeCursor(s string) (*pageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// wantsCursorPage reports whether a list request asked for cursor pagination.
+// An empty cursor asks for the first page, leaving it out keeps limit/offset.
+func wantsCursorPage(r *http.Request) bool {
+	return r.URL.Query().Has("cursor")
+}
+
+func validatePageSize(limit int) error {
+	if limit < 1 || limit > maxPageSize {
+		return ErrInvalidPageSize
+	}
+	return nil
+}
+
+// paginate trims a page fetched with one extra item and works out the
+// cursors either side of it. Items must be newest first.
+func paginate[T any](items []T, limit int, cursor *pageCursor, key func(T) (time.Time, uuid.UUID)) ([]T, string, string) {
+	newer := cursor != nil && cursor.Newer
+	hasMore := len(items) > limit
+	if hasMore {
+		// The extra item is on the side the page was walking towards
+		if newer {
+			items = items[len(items)-limit:]
+		} else {
+			items = items[:limit]
+		}
+	}
+	if len(items) == 0 {
+		return items, "", ""
+	}
+
+	var next, prev string
+	if newer || hasMore {
+		createdAt, id := key(items[len(items)-1])
+		next = encodeCursor(createdAt, id, false)
+	}
+	if (newer && hasMore) || (cursor != nil && !newer) {
+		createdAt, id := key(items[0])
+		prev = encodeCursor(createdAt, id, true)
+	}
+	return items, next, prev
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+func (a *APIConfig) GetPendingActionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	actions, err := a.getPendingActions(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving pending actions: %v", err)
+		http.Error(w, "Error retrieving pending actions", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(actions, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) ApprovePendingActionHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group and action IDs from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	actionID, err := parseUUIDPathParam(r, "action_id")
+	if err != nil {
+		http.Error(w, "Invalid action ID", http.StatusBadRequest)
+		return
+	}
+
+	action, err := a.approvePendingAction(r.Context(), userID, groupID, actionID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrSelfApproval) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrPendingActionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error approving pending action: %v", err)
+		http.Error(w, "Error approving pending action", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(action, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Pending action %v (%s) in group %v approved by user %v", actionID, action.Action, groupID, userID)
+}
+
+func (a *APIConfig) CancelPendingActionHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group and action IDs from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	actionID, err := parseUUIDPathParam(r, "action_id")
+	if err != nil {
+		http.Error(w, "Invalid action ID", http.StatusBadRequest)
+		return
+	}
+
+	err = a.cancelPendingAction(r.Context(), userID, groupID, actionID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrPendingActionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error cancelling pending action: %v", err)
+		http.Error(w, "Error cancelling pending action", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Pending action %v in group %v cancelled by user %v", actionID, groupID, userID)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrActionAlreadyPending  = errors.New("this action is already waiting for approval")
+	ErrPendingActionNotFound = errors.New("pending action not found or no longer pending")
+	ErrSelfApproval          = errors.New("a different admin must approve this action")
+	ErrNoSecondAdmin         = errors.New("the group requires a second approval but has no other admin to give it")
+)
+
+const (
+	actionDeleteGroup     = "delete_group"
+	actionRemoveContent   = "remove_content"
+	actionDisableApproval = "disable_second_approval"
+	actionDemoteAdmin     = "demote_admin"
+
+	pendingActionWindow = 48 * time.Hour
+)
+
+func toJSONPendingAction(action database.PendingAction) PendingAction {
+	return PendingAction{
+		ID:           action.ID,
+		Action:       action.Action,
+		TargetUserID: action.TargetUserID.UUID,
+		RequestedBy:  action.RequestedBy,
+		Status:       action.Status,
+		CreatedAt:    formatNullTime(action.CreatedAt),
+		ExpiresAt:    action.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// requiresSecondApproval reports whether destructive actions in the group
+// must wait for another admin. While the policy is on and nobody else could
+// approve, the action is refused rather than let through unchecked.
+func (a *APIConfig) requiresSecondApproval(ctx context.Context, groupID uuid.UUID) (bool, error) {
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return false, err
+	}
+	if !group.RequireSecondApproval {
+		return false, nil
+	}
+
+	specialRoles, err := a.DBQueries.GetGroupSpecialRoles(ctx, groupID)
+	if err != nil {
+		return false, err
+	}
+
+	// Group admins and admins of its organization can all approve
+	approvers := make(map[uuid.UUID]bool)
+	for _, member := range specialRoles {
+		if member.Role == "admin" {
+			approvers[member.UserID] = true
+		}
+	}
+	if group.OrganizationID.Valid {
+		orgAdmins, err := a.DBQueries.GetOrganizationAdmins(ctx, group.OrganizationID.UUID)
+		if err != nil {
+			return false, err
+		}
+		for _, admin := range orgAdmins {
+			approvers[admin.ID] = true
+		}
+	}
+
+	if len(approvers) < 2 {
+		return false, ErrNoSecondAdmin
+	}
+
+	return true, nil
+}
+
+func (a *APIConfig) requestPendingAction(ctx context.Context, userID, groupID uuid.UUID, action string, target uuid.NullUUID) (PendingAction, error) {
+	return createPendingAction(ctx, a.DBQueries, userID, groupID, action, target)
+}
+
+// createPendingAction files an action for another admin to approve, q may be
+// bound to a transaction
+func createPendingAction(ctx context.Context, q *database.Queries, userID, groupID uuid.UUID, action string, target uuid.NullUUID) (PendingAction, error) {
+	// Clear out expired requests so they don't block a new one
+	if _, err := q.ExpirePendingActions(ctx); err != nil {
+		return PendingAction{}, fmt.Errorf("error expiring pending actions: %w", err)
+	}
+
+	pending, err := q.CreatePendingAction(ctx, database.CreatePendingActionParams{
+		GroupID:      groupID,
+		Action:       action,
+		TargetUserID: target,
+		RequestedBy:  userID,
+		ExpiresAt:    time.Now().Add(pendingActionWindow),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "pending_actions_open_idx") {
+			return PendingAction{}, ErrActionAlreadyPending
+		}
+		return PendingAction{}, fmt.Errorf("error creating pending action: %w", err)
+	}
+
+	return toJSONPendingAction(pending), nil
+}
+
+// executePendingAction runs an approved action, q may be bound to a transaction
+func executePendingAction(ctx context.Context, q *database.Queries, action database.PendingAction) error {
+	switch action.Action {
+	case actionDeleteGroup:
+		_, err := q.SoftDeleteGroup(ctx, database.SoftDeleteGroupParams{
+			ID:        action.GroupID,
+			DeletedBy: uuid.NullUUID{UUID: action.RequestedBy, Valid: true},
+		})
+		return err
+	case actionRemoveContent:
+		err := q.RemovePostsByUser(ctx, database.RemovePostsByUserParams{
+			UserID:  action.TargetUserID.UUID,
+			GroupID: action.GroupID,
+		})
+		if err != nil {
+			return err
+		}
+		return logModeration(ctx, q, database.LogModerationParams{
+			GroupID:      action.GroupID,
+			ActorID:      uuid.NullUUID{UUID: action.RequestedBy, Valid: true},
+			Action:       actionRemoveContent,
+			TargetUserID: action.TargetUserID,
+		})
+	case actionDisableApproval:
+		return q.DisableSecondApproval(ctx, action.GroupID)
+	case actionDemoteAdmin:
+		return q.AdjustUserGroupRole(ctx, database.AdjustUserGroupRoleParams{
+			UserID:  action.TargetUserID.UUID,
+			GroupID: action.GroupID,
+			Role:    "member",
+		})
+	default:
+		return fmt.Errorf("unknown pending action %q", action.Action)
+	}
+}
+
+func (a *APIConfig) getPendingActions(ctx context.Context, userID, groupID uuid.UUID) ([]PendingAction, error) {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	actions, err := a.DBQueries.GetPendingActions(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pending actions: %w", err)
+	}
+
+	jsonActions := make([]PendingAction, len(actions))
+	for i, action := range actions {
+		jsonActions[i] = PendingAction{
+			ID:                  action.ID,
+			Action:              action.Action,
+			TargetUserID:        action.TargetUserID.UUID,
+			TargetUsername:      action.TargetUsername,
+			RequestedBy:         action.RequestedBy,
+			RequestedByUsername: action.RequestedByUsername,
+			Status:              action.Status,
+			CreatedAt:           formatNullTime(action.CreatedAt),
+			ExpiresAt:           action.ExpiresAt.Format(time.RFC3339),
+		}
+	}
+
+	return jsonActions, nil
+}
+
+func (a *APIConfig) approvePendingAction(ctx context.Context, userID, groupID, actionID uuid.UUID) (PendingAction, error) {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return PendingAction{}, err
+	}
+
+	// Approve and run the action together so it can't be approved twice
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return PendingAction{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	pending, err := qtx.GetPendingAction(ctx, database.GetPendingActionParams{
+		ID:      actionID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PendingAction{}, ErrPendingActionNotFound
+		}
+		return PendingAction{}, fmt.Errorf("error retrieving pending action: %w", err)
+	}
+	if pending.RequestedBy == userID {
+		return PendingAction{}, ErrSelfApproval
+	}
+
+	approved, err := qtx.ResolvePendingAction(ctx, database.ResolvePendingActionParams{
+		ID:         actionID,
+		GroupID:    groupID,
+		Status:     "approved",
+		ResolvedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PendingAction{}, ErrPendingActionNotFound
+		}
+		return PendingAction{}, fmt.Errorf("error approving pending action: %w", err)
+	}
+
+	if err := executePendingAction(ctx, qtx, approved); err != nil {
+		return PendingAction{}, fmt.Errorf("error running approved action: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return PendingAction{}, err
+	}
+
+	return toJSONPendingAction(approved), nil
+}
+
+func (a *APIConfig) cancelPendingAction(ctx context.Context, userID, groupID, actionID uuid.UUID) error {
+	// Any admin, including the requester, can cancel
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return err
+	}
+
+	_, err := a.DBQueries.ResolvePendingAction(ctx, database.ResolvePendingActionParams{
+		ID:         actionID,
+		GroupID:    groupID,
+		Status:     "cancelled",
+		ResolvedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPendingActionNotFound
+		}
+		return fmt.Errorf("error cancelling pending action: %w", err)
+	}
+
+	return nil
+}
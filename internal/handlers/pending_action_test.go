@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+func TestSecondApprovalPolicy(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	a := &APIConfig{DB: db, DBQueries: database.New(db)}
+
+	var first, second, member uuid.UUID
+	for _, user := range []struct {
+		id   *uuid.UUID
+		name string
+	}{{&first, "first"}, {&second, "second"}, {&member, "member"}} {
+		created, err := a.DBQueries.CreateUser(ctx, database.CreateUserParams{
+			Username:       user.name,
+			Email:          user.name + "@example.com",
+			HashedPassword: "x",
+		})
+		if err != nil {
+			t.Fatalf("error creating %s: %v", user.name, err)
+		}
+		*user.id = created.ID
+	}
+
+	group, err := a.DBQueries.CreateGroup(ctx, database.CreateGroupParams{
+		Name:       "Approvals",
+		OwnerID:    uuid.NullUUID{UUID: first, Valid: true},
+		InviteCode: "APPROVE1",
+	})
+	if err != nil {
+		t.Fatalf("error creating group: %v", err)
+	}
+	for userID, role := range map[uuid.UUID]string{first: "admin", second: "admin", member: "member"} {
+		err := a.DBQueries.AddUserToGroup(ctx, database.AddUserToGroupParams{
+			UserID:  userID,
+			GroupID: group.ID,
+			Role:    role,
+		})
+		if err != nil {
+			t.Fatalf("error adding member: %v", err)
+		}
+	}
+	if _, err := db.Exec("UPDATE groups SET require_second_approval = TRUE WHERE id = $1", group.ID); err != nil {
+		t.Fatalf("error turning on second approval: %v", err)
+	}
+
+	roleOf := func(userID uuid.UUID) string {
+		t.Helper()
+		role, err := a.DBQueries.GetUserGroupRole(ctx, database.GetUserGroupRoleParams{
+			UserID:  userID,
+			GroupID: group.ID,
+		})
+		if err != nil {
+			t.Fatalf("error retrieving role: %v", err)
+		}
+		return role
+	}
+
+	// Demoting the other admin waits for approval
+	pending, err := a.promoteUser(ctx, first, group.ID, second, "member")
+	if err != nil {
+		t.Fatalf("demote admin: %v", err)
+	}
+	if pending == nil || pending.Action != actionDemoteAdmin {
+		t.Fatalf("demote admin: pending = %+v, want a %s action", pending, actionDemoteAdmin)
+	}
+	if role := roleOf(second); role != "admin" {
+		t.Fatalf("role before approval = %s, want admin", role)
+	}
+
+	if _, err := a.approvePendingAction(ctx, second, group.ID, pending.ID); err != nil {
+		t.Fatalf("approve demotion: %v", err)
+	}
+	if role := roleOf(second); role != "member" {
+		t.Fatalf("role after approval = %s, want member", role)
+	}
+
+	// With a single admin left the policy refuses instead of switching off
+	if _, err := a.removePostsByUser(ctx, first, group.ID, member); !errors.Is(err, ErrNoSecondAdmin) {
+		t.Errorf("remove content: error = %v, want ErrNoSecondAdmin", err)
+	}
+	if _, err := a.softDeleteGroup(ctx, first, group.ID, ""); !errors.Is(err, ErrNoSecondAdmin) {
+		t.Errorf("delete group: error = %v, want ErrNoSecondAdmin", err)
+	}
+
+	disable := false
+	if _, _, err := a.updateGroupSettings(ctx, first, group.ID, GroupSettingsRequest{RequireApproval: &disable}); !errors.Is(err, ErrNoSecondAdmin) {
+		t.Errorf("disable approval: error = %v, want ErrNoSecondAdmin", err)
+	}
+	if _, err := a.DBQueries.GetGroupByID(ctx, group.ID); err != nil {
+		t.Fatalf("group should still exist: %v", err)
+	}
+
+	// Promoting is never held back
+	if _, err := a.promoteUser(ctx, first, group.ID, second, "admin"); err != nil {
+		t.Fatalf("promote member: %v", err)
+	}
+	if role := roleOf(second); role != "admin" {
+		t.Errorf("role after promotion = %s, want admin", role)
+	}
+}
@@ -35,12 +35,44 @@ func (a *APIConfig) CreatePostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	jsonPost, err := a.createPost(r.Context(), groupID, userID, postReq.Content)
+	jsonPost, err := a.createPost(r.Context(), groupID, userID, postReq)
 	if err != nil {
 		if errors.Is(err, ErrUserNotMember) {
 			http.Error(w, "User not a member of the group", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrUserMuted) {
+			http.Error(w, "User is muted in the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrRulesNotAccepted) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrPostingRestricted) {
+			http.Error(w, "Only admins can post in this group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrAnnouncementAdminOnly) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidCategory) || errors.Is(err, ErrInvalidAudience) || errors.Is(err, ErrInvalidAudienceMembers) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrAnonymousNotAllowed) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidPublishAt) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrScheduleAdminOnly) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, "Failed to create post", http.StatusInternalServerError)
 		return
 	}
@@ -86,12 +118,28 @@ func (a *APIConfig) CreateCommentHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Create the comment in the database
-	comment, err := a.createComment(r.Context(), postID, userID, commentReq.Content)
+	comment, err := a.createComment(r.Context(), postID, userID, commentReq.Content, commentReq.Anonymous)
 	if err != nil {
 		if errors.Is(err, ErrUserNotMember) {
 			http.Error(w, "User not a member of the group", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrUserMuted) {
+			http.Error(w, "User is muted in the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrRulesNotAccepted) || errors.Is(err, ErrAnonymousNotAllowed) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrReplyTooDeep) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
 		return
 	}
@@ -132,6 +180,10 @@ func (a *APIConfig) DeletePostHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, "Failed to verify authority to delete posts", http.StatusInternalServerError)
 		return
 	}
@@ -144,6 +196,7 @@ func (a *APIConfig) DeletePostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("User %v deleted post %v in group %v", userID, postID, groupID)
+	a.endCommitmentsForPost(r.Context(), postID, commitmentEndedDeleted)
 
 	// Delete comments associated with the post
 	parentID := uuid.NullUUID{
@@ -176,13 +229,42 @@ func (a *APIConfig) GetCommentsForPostHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Fetch comments for the post
-	response, err := a.getPostWithComments(r.Context(), userID, postID)
+	// Parse optional pagination of top-level comments, all are returned by default
+	limit, err := parseIntQueryParam(r, "limit", 0)
+	if err != nil || limit < 0 {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	// Fetch comments for the post. A cursor switches to keyset pagination
+	// and returns a page of comments without the post.
+	var response any
+	if wantsCursorPage(r) {
+		if limit == 0 {
+			limit = defaultCommentPageSize
+		}
+		response, err = a.getCommentPage(r.Context(), userID, postID, limit, r.URL.Query().Get("cursor"))
+	} else {
+		response, err = a.getPostWithComments(r.Context(), userID, postID, commentPage{Limit: limit, Offset: offset})
+	}
 	if err != nil {
 		if errors.Is(err, ErrUserNotMember) {
 			http.Error(w, "User not a member of the group", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrInvalidCursor) || errors.Is(err, ErrInvalidPageSize) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to fetch comments for post", http.StatusInternalServerError)
 		return
 	}
@@ -216,14 +298,252 @@ func (a *APIConfig) RemoveUserContentHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = a.removePostsByUser(r.Context(), userID, groupID, targetID)
+	pending, err := a.removePostsByUser(r.Context(), userID, groupID, targetID)
 	if err != nil {
 		if errors.Is(err, ErrUserNotMember) || errors.Is(err, ErrUserNotAdmin) {
 			http.Error(w, err.Error(), http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, ErrActionAlreadyPending) || errors.Is(err, ErrNoSecondAdmin) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, "Failed to remove posts by user", http.StatusInternalServerError)
 		return
 	}
 
+	// Another admin has to approve before the posts are removed
+	if pending != nil {
+		if err := CreateJSONResponse(pending, w, http.StatusAccepted); err != nil {
+			log.Printf("Error creating JSON response: %v", err)
+		}
+		log.Printf("Content removal for user %v in group %v awaiting approval", targetID, groupID)
+		return
+	}
+
+	log.Printf("Posts by user %v removed from group %v by user %v", targetID, groupID, userID)
+}
+
+func (a *APIConfig) GetPinnedPostsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	posts, err := a.getPinnedPosts(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to get pinned posts", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(posts, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) PinPostHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Extract group ID and post ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse the pin order and expiry from the request body
+	pinReq, err := ParseJSON[PinPostRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = a.pinPost(r.Context(), userID, groupID, postID, pinReq)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidPinExpiry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error pinning post: %v", err)
+		http.Error(w, "Failed to pin post", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Post %v pinned in group %v by user %v", postID, groupID, userID)
+}
+
+func (a *APIConfig) UnpinPostHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Extract group ID and post ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	err = a.unpinPost(r.Context(), userID, groupID, postID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Pinned post not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error unpinning post: %v", err)
+		http.Error(w, "Failed to unpin post", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Post %v unpinned in group %v by user %v", postID, groupID, userID)
+}
+
+func (a *APIConfig) EditPostHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse the request body for the new content
+	postReq, err := ParseJSON[PostRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if postReq.Content == "" {
+		http.Error(w, "Post content is required", http.StatusBadRequest)
+		return
+	}
+
+	jsonPost, err := a.editPost(r.Context(), userID, groupID, postID, postReq.Content)
+	if err != nil {
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User not a member of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrUserMuted) {
+			http.Error(w, "User is muted in the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrNotPostAuthor) || errors.Is(err, ErrEditWindowClosed) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Error editing post: %v", err)
+		http.Error(w, "Failed to edit post", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(jsonPost, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("User %v edited post %v in group %v", userID, postID, groupID)
+}
+
+func (a *APIConfig) GetPostHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	history, err := a.getPostHistory(r.Context(), userID, groupID, postID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error retrieving post history: %v", err)
+		http.Error(w, "Failed to retrieve post history", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(history, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
 }
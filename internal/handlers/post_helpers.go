@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"time"
 
@@ -11,52 +12,147 @@ import (
 
 var ErrUnauthorizedDelete = errors.New("user is not authorized to delete this post")
 var ErrPostNotFound = errors.New("post not found")
+var ErrInvalidPinExpiry = errors.New("pin expiry must be an RFC3339 time in the future")
+var ErrNotPostAuthor = errors.New("only the author can edit this post")
+var ErrEditWindowClosed = errors.New("the edit window for this post has closed")
+var ErrReplyTooDeep = errors.New("this comment can't be replied to, the thread is nested too deeply")
 
-func (a *APIConfig) createPost(ctx context.Context, groupID, userID uuid.UUID, content string) (Post, error) {
-	// Validate user in group (future: add role check in helper function)
-	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+// DefaultMaxReplyDepth allows comments, replies to them and one more level
+const DefaultMaxReplyDepth = 3
+
+func (a *APIConfig) maxReplyDepth() int {
+	if a.MaxReplyDepth > 0 {
+		return a.MaxReplyDepth
+	}
+	return DefaultMaxReplyDepth
+}
+
+func (a *APIConfig) createPost(ctx context.Context, groupID, userID uuid.UUID, req PostRequest) (Post, error) {
+	// Validate user in group and not muted (future: add role check in helper function)
+	if err := a.verifyUserCanPost(ctx, userID, groupID); err != nil {
+		return Post{}, err
+	}
+	if err := a.verifyPostingAllowed(ctx, userID, groupID); err != nil {
+		return Post{}, err
+	}
+	if err := a.verifyRulesAccepted(ctx, userID, groupID); err != nil {
+		return Post{}, err
+	}
+	category, err := a.resolvePostCategory(ctx, userID, groupID, req.Category)
 	if err != nil {
 		return Post{}, err
 	}
-	if !isMember {
-		return Post{}, ErrUserNotMember
+	if req.Anonymous {
+		if err := a.verifyAnonymousAllowed(ctx, groupID); err != nil {
+			return Post{}, err
+		}
+	}
+	audience, audienceIDs, err := resolvePostAudience(req)
+	if err != nil {
+		return Post{}, err
+	}
+	publishAt, err := a.resolvePublishAt(ctx, userID, groupID, req.PublishAt)
+	if err != nil {
+		return Post{}, err
 	}
 
-	// Create the post in the database
-	post, err := a.DBQueries.CreatePost(ctx, database.CreatePostParams{
-		GroupID: groupID,
-		UserID:  userID,
-		Content: content,
+	// Create the post and its audience together
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Post{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	post, err := qtx.CreatePost(ctx, database.CreatePostParams{
+		GroupID:     groupID,
+		UserID:      userID,
+		Content:     req.Content,
+		Category:    category,
+		IsAnonymous: req.Anonymous,
+		Audience:    audience,
+		PublishAt:   publishAt,
 	})
 	if err != nil {
 		return Post{}, err
 	}
 
+	if audience == audienceCustom {
+		added, err := qtx.AddPostAudienceMembers(ctx, database.AddPostAudienceMembersParams{
+			PostID:  post.ID,
+			GroupID: groupID,
+			UserIds: audienceIDs,
+		})
+		if err != nil {
+			return Post{}, err
+		}
+		if added != int64(len(audienceIDs)) {
+			return Post{}, ErrInvalidAudienceMembers
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Post{}, err
+	}
+
 	// Convert database post to API Post type
 	jsonPost := Post{
-		ID:        post.ID,
-		GroupID:   post.GroupID,
-		UserID:    post.UserID,
-		Content:   post.Content,
-		CreatedAt: post.CreatedAt.Time.Format(time.RFC3339),
+		ID:          post.ID,
+		GroupID:     post.GroupID,
+		UserID:      post.UserID,
+		Content:     post.Content,
+		Category:    post.Category,
+		Status:      post.Status,
+		CreatedAt:   post.CreatedAt.Time.Format(time.RFC3339),
+		Anonymous:   post.IsAnonymous,
+		Audience:    post.Audience,
+		AudienceIDs: audienceIDs,
+		PublishAt:   formatNullTime(post.PublishAt),
+	}
+
+	a.Activity.Touch(userID, groupID)
+
+	// Webhook receivers only get what a regular member would see, so
+	// restricted posts aren't sent at all. Scheduled posts are sent when
+	// they're published.
+	if audience == audienceMembers && !publishAt.Valid {
+		hookPost := jsonPost
+		maskPostAuthor(&hookPost, uuid.Nil, false)
+		a.dispatchWebhookEvent(ctx, groupID, eventPostCreated, hookPost)
 	}
 
 	return jsonPost, nil
 }
 
-func (a *APIConfig) createComment(ctx context.Context, postID, userID uuid.UUID, content string) (Comment, error) {
+func (a *APIConfig) createComment(ctx context.Context, postID, userID uuid.UUID, content string, anonymous bool) (Comment, error) {
 	// Validate user in group (future: add role check in helper function)
 	post, err := a.DBQueries.GetPostByID(ctx, postID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Comment{}, ErrPostNotFound
+		}
 		return Comment{}, err
 	}
 
-	isMember, err := a.verifyUserInGroup(ctx, userID, post.GroupID)
+	if err := a.verifyUserCanPost(ctx, userID, post.GroupID); err != nil {
+		return Comment{}, err
+	}
+	if err := a.verifyRulesAccepted(ctx, userID, post.GroupID); err != nil {
+		return Comment{}, err
+	}
+	if anonymous {
+		if err := a.verifyAnonymousAllowed(ctx, post.GroupID); err != nil {
+			return Comment{}, err
+		}
+	}
+
+	// Only members who can see the post can reply to it
+	root, err := a.getAudienceRoot(ctx, post)
 	if err != nil {
 		return Comment{}, err
 	}
-	if !isMember {
-		return Comment{}, ErrUserNotMember
+	if err := a.canViewPost(ctx, userID, root); err != nil {
+		return Comment{}, err
 	}
 
 	// Validate post in group
@@ -68,6 +164,15 @@ func (a *APIConfig) createComment(ctx context.Context, postID, userID uuid.UUID,
 		return Comment{}, ErrPostNotFound
 	}
 
+	// Replies can nest up to the configured depth
+	depth, err := a.DBQueries.GetPostDepth(ctx, postID)
+	if err != nil {
+		return Comment{}, err
+	}
+	if int(depth)+1 > a.maxReplyDepth() {
+		return Comment{}, ErrReplyTooDeep
+	}
+
 	// Create the comment in the database
 	parentID := uuid.NullUUID{
 		UUID:  postID,
@@ -79,6 +184,7 @@ func (a *APIConfig) createComment(ctx context.Context, postID, userID uuid.UUID,
 		GroupID:      post.GroupID,
 		UserID:       userID,
 		Content:      content,
+		IsAnonymous:  anonymous,
 	})
 	if err != nil {
 		return Comment{}, err
@@ -86,18 +192,55 @@ func (a *APIConfig) createComment(ctx context.Context, postID, userID uuid.UUID,
 
 	// Return the created comment as JSON response
 	jsonComment := Comment{
-		ID:      comment.ID,
-		PostID:  comment.ParentPostID.UUID,
-		GroupID: comment.GroupID,
-		UserID:  userID,
-		Content: comment.Content,
+		ID:        comment.ID,
+		PostID:    root.ID,
+		ParentID:  comment.ParentPostID.UUID,
+		GroupID:   comment.GroupID,
+		UserID:    userID,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt.Time.Format(time.RFC3339),
+		Anonymous: comment.IsAnonymous,
+		Depth:     depth + 1,
+		Replies:   []Comment{},
+	}
+
+	a.Activity.Touch(userID, post.GroupID)
+
+	a.unarchivePost(ctx, root)
+
+	// Webhook receivers only get what a regular member would see
+	if root.Audience == audienceMembers {
+		hookComment := jsonComment
+		maskCommentAuthor(&hookComment, uuid.Nil, false)
+		a.dispatchWebhookEvent(ctx, post.GroupID, eventCommentCreated, hookComment)
 	}
 
 	return jsonComment, nil
 }
 
 func (a *APIConfig) verifyUserCanDeletePost(ctx context.Context, userID, postID, groupID uuid.UUID) error {
-	// Verify if the user is a member of the group
+	// Check the post belongs to the group the permissions are checked against
+	post, err := a.DBQueries.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	if post.GroupID != groupID {
+		return ErrPostNotFound
+	}
+
+	// Group and organization admins can delete any post in the group
+	err = a.isAdmin(ctx, userID, groupID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrUserNotAdmin) {
+		return err
+	}
+
+	// Otherwise the user must be a member deleting their own post
 	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
 	if err != nil {
 		return err
@@ -105,110 +248,550 @@ func (a *APIConfig) verifyUserCanDeletePost(ctx context.Context, userID, postID,
 	if !isMember {
 		return ErrUserNotMember
 	}
+	if err := a.canViewPost(ctx, userID, post); err != nil {
+		return err
+	}
+	if post.UserID != userID {
+		return ErrUnauthorizedDelete
+	}
 
-	// Check if the user is the owner of the post or an admin in the group
-	post, err := a.DBQueries.GetPostByID(ctx, postID)
+	return nil
+}
+
+// commentPage selects which top-level comments to load, replies always come
+// with their parent. A zero Limit loads every comment.
+type commentPage struct {
+	Limit  int
+	Offset int
+	Keyset bool        // Page by cursor instead of offset
+	Cursor *pageCursor // Nil for the first page
+}
+
+// getCommentsOnPost returns the comments on a post as a tree of replies
+func (a *APIConfig) getCommentsOnPost(ctx context.Context, viewerID, postID uuid.UUID, isLeader bool, page commentPage) (CommentPage, error) {
+	params := database.GetCommentsByPostIDParams{
+		PostID:         postID,
+		ViewerID:       viewerID,
+		ViewerIsLeader: isLeader,
+		Offset:         int32(page.Offset),
+	}
+	if page.Limit > 0 {
+		params.Limit = sql.NullInt32{Int32: int32(page.Limit), Valid: true}
+	}
+	if page.Keyset {
+		// Fetch one extra comment to tell whether there's another page
+		params.Limit = sql.NullInt32{Int32: int32(page.Limit + 1), Valid: true}
+		params.Offset = 0
+		if page.Cursor != nil {
+			params.CursorTime = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+			params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+			params.Newer = page.Cursor.Newer
+		}
+	}
+
+	comments, err := a.DBQueries.GetCommentsByPostID(ctx, params)
 	if err != nil {
-		return err
+		return CommentPage{}, err
 	}
 
-	if post.UserID == userID {
-		return nil // User is the owner of the post
+	// Comments arrive deepest first, so every reply is complete by the
+	// time its parent is reached
+	replies := make(map[uuid.UUID][]Comment)
+	createdAt := make(map[uuid.UUID]time.Time)
+	for _, comment := range comments {
+		createdAt[comment.ID] = comment.CreatedAt.Time
+		jsonComment := Comment{
+			ID:         comment.ID,
+			PostID:     postID,
+			ParentID:   comment.ParentPostID.UUID,
+			GroupID:    comment.GroupID,
+			UserID:     comment.UserID,
+			Content:    comment.Content,
+			CreatedAt:  comment.CreatedAt.Time.Format(time.RFC3339),
+			Author:     comment.Username.String,
+			Anonymous:  comment.IsAnonymous,
+			Edited:     comment.EditedAt.Valid,
+			EditedAt:   formatNullTime(comment.EditedAt),
+			Depth:      comment.Depth,
+			ReplyCount: comment.ReplyCount,
+			Replies:    replies[comment.ID],
+		}
+		if jsonComment.Replies == nil {
+			jsonComment.Replies = []Comment{}
+		}
+		replies[jsonComment.ParentID] = append(replies[jsonComment.ParentID], jsonComment)
 	}
 
-	// Check if the user is an admin in the group
-	if err = a.isAdmin(ctx, userID, groupID); err != nil {
-		return err // error will be ErrUserNotAdmin or a DB query error
-	} else {
-		return nil // User is an admin in the group
+	result := CommentPage{Comments: replies[postID]}
+	if result.Comments == nil {
+		result.Comments = []Comment{}
+	}
+	if page.Keyset {
+		result.Comments, result.NextCursor, result.PrevCursor = paginate(result.Comments, page.Limit, page.Cursor, func(comment Comment) (time.Time, uuid.UUID) {
+			return createdAt[comment.ID], comment.ID
+		})
 	}
+	return result, nil
 }
 
-func (a *APIConfig) getCommentsOnPost(ctx context.Context, postID uuid.UUID) ([]Comment, error) {
-	parentID := uuid.NullUUID{
-		UUID:  postID,
-		Valid: true,
+// getCommentPage returns a page of top-level comments before or after a
+// cursor, each with all of its replies
+func (a *APIConfig) getCommentPage(ctx context.Context, userID, postID uuid.UUID, limit int, cursorParam string) (CommentPage, error) {
+	if err := validatePageSize(limit); err != nil {
+		return CommentPage{}, err
 	}
-	comments, err := a.DBQueries.GetCommentsByPostID(ctx, parentID)
+	cursor, err := decodeCursor(cursorParam)
 	if err != nil {
-		return nil, err
+		return CommentPage{}, err
 	}
 
-	// Convert database comments to API Comment type
-	jsonComments := make([]Comment, len(comments))
-	for i, comment := range comments {
-		jsonComments[i] = Comment{
-			ID:        comment.ID,
-			PostID:    postID,
-			UserID:    comment.UserID,
-			Content:   comment.Content,
-			CreatedAt: comment.CreatedAt.Time.Format(time.RFC3339),
-			Author:    comment.Username.String,
-		}
+	post, isLeader, err := a.getViewablePost(ctx, userID, postID)
+	if err != nil {
+		return CommentPage{}, err
 	}
 
-	return jsonComments, nil
+	page, err := a.getCommentsOnPost(ctx, userID, postID, isLeader, commentPage{
+		Limit:  limit,
+		Keyset: true,
+		Cursor: cursor,
+	})
+	if err != nil {
+		return CommentPage{}, err
+	}
+	for i := range page.Comments {
+		maskCommentAuthor(&page.Comments[i], userID, isLeader)
+	}
+	a.Activity.Touch(userID, post.GroupID)
+
+	return page, nil
 }
 
-func (a *APIConfig) getPostWithComments(ctx context.Context, userID, postID uuid.UUID) (Post, error) {
-	// Fetch the post
+// getViewablePost fetches a post or comment the user can read and reports
+// whether they lead its group
+func (a *APIConfig) getViewablePost(ctx context.Context, userID, postID uuid.UUID) (database.GetPostByIDRow, bool, error) {
 	post, err := a.DBQueries.GetPostByID(ctx, postID)
 	if err != nil {
-		return Post{}, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.GetPostByIDRow{}, false, ErrPostNotFound
+		}
+		return database.GetPostByIDRow{}, false, err
 	}
 
 	// Verify if the user is a member of the group
 	isMember, err := a.verifyUserInGroup(ctx, userID, post.GroupID)
 	if err != nil {
-		return Post{}, err
+		return database.GetPostByIDRow{}, false, err
 	}
 	if !isMember {
-		return Post{}, ErrUserNotMember
+		return database.GetPostByIDRow{}, false, ErrUserNotMember
+	}
+	if err := a.canViewPost(ctx, userID, post); err != nil {
+		return database.GetPostByIDRow{}, false, err
 	}
 
+	isLeader, err := a.isGroupLeader(ctx, userID, post.GroupID)
+	if err != nil {
+		return database.GetPostByIDRow{}, false, err
+	}
+
+	return post, isLeader, nil
+}
+
+func (a *APIConfig) getPostWithComments(ctx context.Context, userID, postID uuid.UUID, page commentPage) (Post, error) {
+	// Fetch the post the user can see
+	post, isLeader, err := a.getViewablePost(ctx, userID, postID)
+	if err != nil {
+		return Post{}, err
+	}
+	a.Activity.Touch(userID, post.GroupID)
+
 	// Fetch comments for the post
-	comments, err := a.getCommentsOnPost(ctx, postID)
+	comments, err := a.getCommentsOnPost(ctx, userID, postID, isLeader, page)
 	if err != nil {
 		return Post{}, err
 	}
 
+	// Top-level posts carry the "I prayed" summary
+	var prayers PrayerSummary
+	if !post.ParentPostID.Valid {
+		prayers, err = a.getPrayerSummary(ctx, userID, postID)
+		if err != nil {
+			return Post{}, err
+		}
+	}
+
 	// Convert database post to API Post type
 	jsonPost := Post{
-		ID:        post.ID,
-		GroupID:   post.GroupID,
-		UserID:    post.UserID,
-		Content:   post.Content,
-		CreatedAt: post.CreatedAt.Time.Format(time.RFC3339),
-		Author:    post.Username.String,
-		Comments:  comments,
+		ID:          post.ID,
+		GroupID:     post.GroupID,
+		UserID:      post.UserID,
+		Content:     post.Content,
+		Category:    post.Category,
+		CreatedAt:   post.CreatedAt.Time.Format(time.RFC3339),
+		Author:      post.Username.String,
+		Anonymous:   post.IsAnonymous,
+		Audience:    post.Audience,
+		Comments:    comments.Comments,
+		Edited:      post.EditedAt.Valid,
+		EditedAt:    formatNullTime(post.EditedAt),
+		Status:      post.Status,
+		AnsweredAt:  formatNullTime(post.AnsweredAt),
+		AnswerNote:  post.AnswerNote,
+		PrayedCount: prayers.PrayedCount,
+		PrayedByMe:  prayers.PrayedByMe,
+		PublishAt:   formatNullTime(post.PublishAt),
+		Archived:    post.ArchivedAt.Valid,
+		ArchivedAt:  formatNullTime(post.ArchivedAt),
+	}
+	maskPostAuthor(&jsonPost, userID, isLeader)
+
+	// Only the author and leaders see who a custom audience includes
+	if post.Audience == audienceCustom && (isLeader || post.UserID == userID) {
+		jsonPost.AudienceIDs, err = a.DBQueries.GetPostAudience(ctx, postID)
+		if err != nil {
+			return Post{}, err
+		}
 	}
 
 	return jsonPost, nil
 }
 
-func (a *APIConfig) removePostsByUser(ctx context.Context, userID, groupID, targetID uuid.UUID) error {
-	// Verify if the requester is an admin in the group
-	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+func (a *APIConfig) removePostsByUser(ctx context.Context, userID, groupID, targetID uuid.UUID) (*PendingAction, error) {
+	// Verify if the requester is an admin in the group (or its organization)
+	err := a.isAdmin(ctx, userID, groupID)
 	if err != nil {
-		return err // error will be ErrUserNotMember or a DB query error
+		return nil, err // error will be ErrUserNotAdmin or a DB query error
 	}
-	if !isMember {
-		return ErrUserNotMember
+
+	// Groups with the approval policy queue the removal for another admin
+	needsApproval, err := a.requiresSecondApproval(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if needsApproval {
+		pending, err := a.requestPendingAction(ctx, userID, groupID, actionRemoveContent, uuid.NullUUID{UUID: targetID, Valid: true})
+		if err != nil {
+			return nil, err
+		}
+		return &pending, nil
 	}
 
-	err = a.isAdmin(ctx, userID, groupID)
+	// Remove the posts and record it together so the history can't miss one
+	tx, err := a.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return err // error will be ErrUserNotAdmin or a DB query error
+		return nil, err
 	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
 
 	// Remove posts by the target user in the group
-	err = a.DBQueries.RemovePostsByUser(ctx, database.RemovePostsByUserParams{
+	err = qtx.RemovePostsByUser(ctx, database.RemovePostsByUserParams{
 		UserID:  targetID,
 		GroupID: groupID,
 	})
 	if err != nil {
+		return nil, err
+	}
+
+	err = logModeration(ctx, qtx, database.LogModerationParams{
+		GroupID:      groupID,
+		ActorID:      uuid.NullUUID{UUID: userID, Valid: true},
+		Action:       actionRemoveContent,
+		TargetUserID: uuid.NullUUID{UUID: targetID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, tx.Commit()
+}
+
+func (a *APIConfig) getPinnedPosts(ctx context.Context, userID, groupID uuid.UUID) ([]Post, error) {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrUserNotMember
+	}
+
+	isLeader, err := a.isGroupLeader(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := a.DBQueries.GetPinnedPosts(ctx, database.GetPinnedPostsParams{
+		GroupID:        groupID,
+		ViewerID:       userID,
+		ViewerIsLeader: isLeader,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jsonPosts := make([]Post, len(posts))
+	for i, post := range posts {
+		jsonPosts[i] = Post{
+			ID:           post.ID,
+			GroupID:      post.GroupID,
+			UserID:       post.UserID,
+			Content:      post.Content,
+			Category:     post.Category,
+			CreatedAt:    post.CreatedAt.Time.Format(time.RFC3339),
+			Author:       post.Username.String,
+			Anonymous:    post.IsAnonymous,
+			Audience:     post.Audience,
+			CommentCount: post.CommentCount,
+			Pinned:       true,
+			PinOrder:     post.PinOrder,
+			PinnedUntil:  formatNullTime(post.PinnedUntil),
+			Edited:       post.EditedAt.Valid,
+			EditedAt:     formatNullTime(post.EditedAt),
+			Status:       post.Status,
+			AnsweredAt:   formatNullTime(post.AnsweredAt),
+			AnswerNote:   post.AnswerNote,
+		}
+		maskPostAuthor(&jsonPosts[i], userID, isLeader)
+	}
+
+	return jsonPosts, nil
+}
+
+func (a *APIConfig) pinPost(ctx context.Context, userID, groupID, postID uuid.UUID, req PinPostRequest) error {
+	// Only admins can pin posts
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
 		return err
 	}
 
-	return nil
+	var until sql.NullTime
+	if req.Until != "" {
+		t, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil || !t.After(time.Now()) {
+			return ErrInvalidPinExpiry
+		}
+		until = sql.NullTime{Time: t, Valid: true}
+	}
+
+	// Pin and record it together so the history can't miss one
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	// Only top-level posts that still exist can be pinned
+	pinned, err := qtx.PinPost(ctx, database.PinPostParams{
+		ID:          postID,
+		GroupID:     groupID,
+		PinnedUntil: until,
+		PinnedBy:    uuid.NullUUID{UUID: userID, Valid: true},
+		PinOrder:    req.Order,
+	})
+	if err != nil {
+		return err
+	}
+	if pinned == 0 {
+		return ErrPostNotFound
+	}
+
+	err = logModeration(ctx, qtx, database.LogModerationParams{
+		GroupID:      groupID,
+		ActorID:      uuid.NullUUID{UUID: userID, Valid: true},
+		Action:       "pin",
+		TargetPostID: uuid.NullUUID{UUID: postID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (a *APIConfig) unpinPost(ctx context.Context, userID, groupID, postID uuid.UUID) error {
+	// Only admins can unpin posts
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return err
+	}
+
+	// Unpin and record it together so the history can't miss one
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	unpinned, err := qtx.UnpinPost(ctx, database.UnpinPostParams{
+		ID:      postID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		return err
+	}
+	if unpinned == 0 {
+		return ErrPostNotFound
+	}
+
+	err = logModeration(ctx, qtx, database.LogModerationParams{
+		GroupID:      groupID,
+		ActorID:      uuid.NullUUID{UUID: userID, Valid: true},
+		Action:       "unpin",
+		TargetPostID: uuid.NullUUID{UUID: postID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// editPost replaces the content of a post or comment, keeping the previous
+// content as a revision. Only the author can edit, within the group's window.
+func (a *APIConfig) editPost(ctx context.Context, userID, groupID, postID uuid.UUID, content string) (Post, error) {
+	post, err := a.DBQueries.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Post{}, ErrPostNotFound
+		}
+		return Post{}, err
+	}
+	if post.GroupID != groupID {
+		return Post{}, ErrPostNotFound
+	}
+
+	// Muted members can't change what they've written either
+	if err := a.verifyUserCanPost(ctx, userID, groupID); err != nil {
+		return Post{}, err
+	}
+	if err := a.canViewPost(ctx, userID, post); err != nil {
+		return Post{}, err
+	}
+	if post.UserID != userID {
+		return Post{}, ErrNotPostAuthor
+	}
+
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return Post{}, err
+	}
+	window := time.Duration(group.EditWindowMinutes) * time.Minute
+	if window > 0 && time.Since(post.CreatedAt.Time) > window {
+		return Post{}, ErrEditWindowClosed
+	}
+
+	jsonPost := Post{
+		ID:         post.ID,
+		GroupID:    post.GroupID,
+		UserID:     post.UserID,
+		Content:    post.Content,
+		Category:   post.Category,
+		CreatedAt:  post.CreatedAt.Time.Format(time.RFC3339),
+		Author:     post.Username.String,
+		Anonymous:  post.IsAnonymous,
+		Audience:   post.Audience,
+		Edited:     post.EditedAt.Valid,
+		EditedAt:   formatNullTime(post.EditedAt),
+		Status:     post.Status,
+		AnsweredAt: formatNullTime(post.AnsweredAt),
+		AnswerNote: post.AnswerNote,
+	}
+	// Comments don't have a category, status or audience of their own
+	if post.ParentPostID.Valid {
+		jsonPost.Category = ""
+		jsonPost.Status = ""
+		jsonPost.Audience = ""
+	}
+
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Post{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	// Re-read the content under a row lock, another edit may have landed
+	// since the post was loaded and its text belongs in the revision
+	current, err := qtx.GetPostContentForUpdate(ctx, post.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Post{}, ErrPostNotFound
+		}
+		return Post{}, err
+	}
+
+	// Nothing changed, don't record an empty revision
+	if content == current {
+		jsonPost.Content = current
+		return jsonPost, nil
+	}
+
+	err = qtx.CreatePostRevision(ctx, database.CreatePostRevisionParams{
+		PostID:   post.ID,
+		Content:  current,
+		EditedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return Post{}, err
+	}
+
+	updated, err := qtx.UpdatePost(ctx, database.UpdatePostParams{
+		Content: content,
+		ID:      post.ID,
+	})
+	if err != nil {
+		return Post{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Post{}, err
+	}
+
+	jsonPost.Content = updated.Content
+	jsonPost.Edited = true
+	jsonPost.EditedAt = formatNullTime(updated.EditedAt)
+
+	return jsonPost, nil
+}
+
+func (a *APIConfig) getPostHistory(ctx context.Context, userID, groupID, postID uuid.UUID) (PostHistory, error) {
+	// Only admins can see what a post said before it was edited
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return PostHistory{}, err
+	}
+
+	post, err := a.DBQueries.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PostHistory{}, ErrPostNotFound
+		}
+		return PostHistory{}, err
+	}
+	if post.GroupID != groupID {
+		return PostHistory{}, ErrPostNotFound
+	}
+
+	revisions, err := a.DBQueries.GetPostRevisions(ctx, postID)
+	if err != nil {
+		return PostHistory{}, err
+	}
+
+	history := PostHistory{
+		PostID:    post.ID,
+		Content:   post.Content,
+		EditedAt:  formatNullTime(post.EditedAt),
+		Revisions: make([]PostRevision, 0, len(revisions)),
+	}
+	for _, revision := range revisions {
+		history.Revisions = append(history.Revisions, PostRevision{
+			ID:        revision.ID,
+			Content:   revision.Content,
+			EditedBy:  revision.EditedBy.UUID,
+			Editor:    revision.Editor,
+			CreatedAt: formatNullTime(revision.CreatedAt),
+		})
+	}
+
+	return history, nil
 }
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+func (a *APIConfig) SetPostStatusHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := ParseJSON[PostStatusRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	post, err := a.setPostStatus(r.Context(), userID, groupID, postID, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPostStatus) || errors.Is(err, ErrAnswerNoteTooLong) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrPostNotFound) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrUnauthorizedStatus) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Error updating post status: %v", err)
+		http.Error(w, "Failed to update post status", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(post, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("User %v set post %v in group %v to %s", userID, postID, groupID, post.Status)
+}
+
+func (a *APIConfig) GetAnsweredPostsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse query parameters for pagination
+	limit, err := parseIntQueryParam(r, "limit", 10)
+	if err != nil {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	posts, err := a.getAnsweredPosts(r.Context(), userID, groupID, limit, offset)
+	if err != nil {
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving answered posts: %v", err)
+		http.Error(w, "Failed to get answered prayers", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(posts, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPostStatus  = errors.New("status must be 'open', 'ongoing', 'answered' or 'closed'")
+	ErrAnswerNoteTooLong  = errors.New("answer note cannot exceed 2000 characters")
+	ErrUnauthorizedStatus = errors.New("only the author or a group admin can change the status")
+)
+
+const (
+	statusOpen     = "open"
+	statusOngoing  = "ongoing"
+	statusAnswered = "answered"
+	statusClosed   = "closed"
+
+	maxAnswerNoteLength = 2000
+)
+
+var validPostStatuses = []string{statusOpen, statusOngoing, statusAnswered, statusClosed}
+
+func (a *APIConfig) setPostStatus(ctx context.Context, userID, groupID, postID uuid.UUID, req PostStatusRequest) (Post, error) {
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if !slices.Contains(validPostStatuses, status) {
+		return Post{}, ErrInvalidPostStatus
+	}
+
+	// Counted in characters so testimonies in any script get the same room
+	note := strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(note) > maxAnswerNoteLength {
+		return Post{}, ErrAnswerNoteTooLong
+	}
+	// Notes belong to answered requests only
+	if status != statusAnswered {
+		note = ""
+	}
+
+	post, err := a.DBQueries.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Post{}, ErrPostNotFound
+		}
+		return Post{}, err
+	}
+	if post.GroupID != groupID || post.ParentPostID.Valid {
+		return Post{}, ErrPostNotFound
+	}
+
+	// The author can update their own request, admins can update any
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return Post{}, err
+	}
+	if !isMember {
+		return Post{}, ErrUserNotMember
+	}
+	if err := a.canViewPost(ctx, userID, post); err != nil {
+		return Post{}, err
+	}
+	if post.UserID != userID {
+		if err := a.isAdmin(ctx, userID, groupID); err != nil {
+			if errors.Is(err, ErrUserNotAdmin) {
+				return Post{}, ErrUnauthorizedStatus
+			}
+			return Post{}, err
+		}
+	}
+
+	updated, err := a.DBQueries.SetPostStatus(ctx, database.SetPostStatusParams{
+		Status:     status,
+		AnswerNote: note,
+		ChangedBy:  uuid.NullUUID{UUID: userID, Valid: true},
+		ID:         postID,
+		GroupID:    groupID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Post{}, ErrPostNotFound
+		}
+		return Post{}, fmt.Errorf("error updating post status: %w", err)
+	}
+
+	jsonPost := Post{
+		ID:         updated.ID,
+		GroupID:    updated.GroupID,
+		UserID:     updated.UserID,
+		Content:    updated.Content,
+		Category:   updated.Category,
+		CreatedAt:  updated.CreatedAt.Time.Format(time.RFC3339),
+		Author:     post.Username.String,
+		Anonymous:  updated.IsAnonymous,
+		Audience:   updated.Audience,
+		Edited:     updated.EditedAt.Valid,
+		EditedAt:   formatNullTime(updated.EditedAt),
+		Status:     updated.Status,
+		AnsweredAt: formatNullTime(updated.AnsweredAt),
+		AnswerNote: updated.AnswerNote,
+	}
+
+	// Only announce the first time a request is answered, not note updates
+	if status == statusAnswered && post.Status != statusAnswered {
+		if updated.Audience == audienceMembers {
+			hookPost := jsonPost
+			maskPostAuthor(&hookPost, uuid.Nil, false)
+			a.dispatchWebhookEvent(ctx, groupID, eventRequestAnswered, hookPost)
+		}
+		a.endCommitmentsForPost(ctx, postID, commitmentEndedAnswered)
+	}
+
+	return jsonPost, nil
+}
+
+func (a *APIConfig) getAnsweredPosts(ctx context.Context, userID, groupID uuid.UUID, limit, offset int) ([]Post, error) {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrUserNotMember
+	}
+
+	isLeader, err := a.isGroupLeader(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := a.DBQueries.GetAnsweredPosts(ctx, database.GetAnsweredPostsParams{
+		GroupID:        groupID,
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		ViewerID:       userID,
+		ViewerIsLeader: isLeader,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jsonPosts := make([]Post, len(posts))
+	for i, post := range posts {
+		jsonPosts[i] = Post{
+			ID:           post.ID,
+			GroupID:      post.GroupID,
+			UserID:       post.UserID,
+			Content:      post.Content,
+			Category:     post.Category,
+			CreatedAt:    post.CreatedAt.Time.Format(time.RFC3339),
+			Author:       post.Username.String,
+			Anonymous:    post.IsAnonymous,
+			Audience:     post.Audience,
+			CommentCount: post.CommentCount,
+			Edited:       post.EditedAt.Valid,
+			EditedAt:     formatNullTime(post.EditedAt),
+			Status:       statusAnswered,
+			AnsweredAt:   formatNullTime(post.AnsweredAt),
+			AnswerNote:   post.AnswerNote,
+		}
+		maskPostAuthor(&jsonPosts[i], userID, isLeader)
+	}
+
+	return jsonPosts, nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+func TestSetPostStatusNoteLength(t *testing.T) {
+	// Rejected before anything is read from the database
+	a := &APIConfig{}
+	note := strings.Repeat("я", maxAnswerNoteLength+1)
+	_, err := a.setPostStatus(context.Background(), uuid.New(), uuid.New(), uuid.New(), PostStatusRequest{Status: statusAnswered, Note: note})
+	if !errors.Is(err, ErrAnswerNoteTooLong) {
+		t.Errorf("%d character note: error = %v, want ErrAnswerNoteTooLong", maxAnswerNoteLength+1, err)
+	}
+}
+
+func TestSetPostStatusNonLatinNote(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	a := &APIConfig{DB: db, DBQueries: database.New(db)}
+
+	author, err := a.DBQueries.CreateUser(ctx, database.CreateUserParams{
+		Username:       "testimony",
+		Email:          "testimony@example.com",
+		HashedPassword: "x",
+	})
+	if err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+	group, err := a.DBQueries.CreateGroup(ctx, database.CreateGroupParams{
+		Name:       "Testimonies",
+		OwnerID:    uuid.NullUUID{UUID: author.ID, Valid: true},
+		InviteCode: "TESTIFY1",
+	})
+	if err != nil {
+		t.Fatalf("error creating group: %v", err)
+	}
+	err = a.DBQueries.AddUserToGroup(ctx, database.AddUserToGroupParams{
+		UserID:  author.ID,
+		GroupID: group.ID,
+		Role:    "member",
+	})
+	if err != nil {
+		t.Fatalf("error adding member: %v", err)
+	}
+	post, err := a.DBQueries.CreatePost(ctx, database.CreatePostParams{
+		UserID:   author.ID,
+		GroupID:  group.ID,
+		Content:  "Please pray for my exams",
+		Category: "prayer_request",
+		Audience: audienceMembers,
+	})
+	if err != nil {
+		t.Fatalf("error creating post: %v", err)
+	}
+
+	// Two bytes per character, well over the limit in bytes
+	note := strings.Repeat("я", maxAnswerNoteLength)
+	updated, err := a.setPostStatus(ctx, author.ID, group.ID, post.ID, PostStatusRequest{Status: statusAnswered, Note: note})
+	if err != nil {
+		t.Fatalf("%d character note: %v", maxAnswerNoteLength, err)
+	}
+	if updated.AnswerNote != note {
+		t.Errorf("answer note was not saved in full")
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+// prayerErrorStatus maps prayer response errors to a status code, returning
+// 0 for unexpected errors
+func prayerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrPostNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUserNotMember), errors.Is(err, ErrRosterAuthorOnly):
+		return http.StatusForbidden
+	}
+	return 0
+}
+
+func (a *APIConfig) RecordPrayerHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := a.recordPrayer(r.Context(), userID, groupID, postID)
+	if err != nil {
+		if status := prayerErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error recording prayer: %v", err)
+		http.Error(w, "Failed to record prayer", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(summary, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("User %v prayed for post %v in group %v", userID, postID, groupID)
+}
+
+func (a *APIConfig) WithdrawPrayerHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := a.withdrawPrayer(r.Context(), userID, groupID, postID)
+	if err != nil {
+		if status := prayerErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error withdrawing prayer: %v", err)
+		http.Error(w, "Failed to withdraw prayer", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(summary, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("User %v withdrew prayers for post %v in group %v", userID, postID, groupID)
+}
+
+func (a *APIConfig) GetPrayerRosterHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the post ID and group ID from URL parameters
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postID, err := parseUUIDPathParam(r, "post_id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	roster, err := a.getPrayerRoster(r.Context(), userID, groupID, postID)
+	if err != nil {
+		if status := prayerErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error retrieving prayer roster: %v", err)
+		http.Error(w, "Failed to retrieve prayer roster", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(roster, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var ErrRosterAuthorOnly = errors.New("only the author can see who prayed")
+
+// getPrayablePost loads a top-level post in the group after checking the
+// user is a member
+func (a *APIConfig) getPrayablePost(ctx context.Context, userID, groupID, postID uuid.UUID) (database.GetPostByIDRow, error) {
+	post, err := a.DBQueries.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.GetPostByIDRow{}, ErrPostNotFound
+		}
+		return database.GetPostByIDRow{}, err
+	}
+	if post.GroupID != groupID || post.ParentPostID.Valid {
+		return database.GetPostByIDRow{}, ErrPostNotFound
+	}
+
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return database.GetPostByIDRow{}, err
+	}
+	if !isMember {
+		return database.GetPostByIDRow{}, ErrUserNotMember
+	}
+	if err := a.canViewPost(ctx, userID, post); err != nil {
+		return database.GetPostByIDRow{}, err
+	}
+
+	return post, nil
+}
+
+func (a *APIConfig) getPrayerSummary(ctx context.Context, userID, postID uuid.UUID) (PrayerSummary, error) {
+	summary, err := a.DBQueries.GetPrayerSummary(ctx, database.GetPrayerSummaryParams{
+		ViewerID: userID,
+		PostID:   postID,
+	})
+	if err != nil {
+		return PrayerSummary{}, fmt.Errorf("error retrieving prayer summary: %w", err)
+	}
+
+	return PrayerSummary{
+		PostID:      postID,
+		PrayedCount: summary.PrayedCount,
+		PrayerCount: summary.PrayerCount,
+		PrayedByMe:  summary.PrayedByMe,
+	}, nil
+}
+
+// recordPrayer marks that the user prayed for the post today. Repeating it
+// on the same day has no effect.
+func (a *APIConfig) recordPrayer(ctx context.Context, userID, groupID, postID uuid.UUID) (PrayerSummary, error) {
+	post, err := a.getPrayablePost(ctx, userID, groupID, postID)
+	if err != nil {
+		return PrayerSummary{}, err
+	}
+
+	_, err = a.DBQueries.RecordPrayerResponse(ctx, database.RecordPrayerResponseParams{
+		PostID: postID,
+		UserID: userID,
+	})
+	if err != nil {
+		return PrayerSummary{}, fmt.Errorf("error recording prayer response: %w", err)
+	}
+	a.Activity.Touch(userID, groupID)
+	a.unarchivePost(ctx, post)
+
+	return a.getPrayerSummary(ctx, userID, postID)
+}
+
+func (a *APIConfig) withdrawPrayer(ctx context.Context, userID, groupID, postID uuid.UUID) (PrayerSummary, error) {
+	if _, err := a.getPrayablePost(ctx, userID, groupID, postID); err != nil {
+		return PrayerSummary{}, err
+	}
+
+	_, err := a.DBQueries.WithdrawPrayerResponses(ctx, database.WithdrawPrayerResponsesParams{
+		PostID: postID,
+		UserID: userID,
+	})
+	if err != nil {
+		return PrayerSummary{}, fmt.Errorf("error withdrawing prayer response: %w", err)
+	}
+
+	return a.getPrayerSummary(ctx, userID, postID)
+}
+
+func (a *APIConfig) getPrayerRoster(ctx context.Context, userID, groupID, postID uuid.UUID) ([]PrayerRosterEntry, error) {
+	post, err := a.getPrayablePost(ctx, userID, groupID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.UserID != userID {
+		return nil, ErrRosterAuthorOnly
+	}
+
+	roster, err := a.DBQueries.GetPrayerRoster(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving prayer roster: %w", err)
+	}
+
+	entries := make([]PrayerRosterEntry, 0, len(roster))
+	for _, entry := range roster {
+		entries = append(entries, PrayerRosterEntry{
+			UserID:       entry.UserID,
+			Username:     entry.Username,
+			TimesPrayed:  entry.TimesPrayed,
+			LastPrayedAt: entry.LastPrayedAt.Format(time.RFC3339),
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+func (a *APIConfig) GetRulesHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	history, err := a.getRulesHistory(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotMember) || errors.Is(err, ErrUserKickedOrBanned) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving rules history: %v", err)
+		http.Error(w, "Error retrieving rules history", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(history, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) GetRulesDiffHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Versions default to the current rules and the version before them
+	from, err := parseIntQueryParam(r, "from", 0)
+	if err != nil || from < 0 {
+		http.Error(w, "Invalid from parameter", http.StatusBadRequest)
+		return
+	}
+	to, err := parseIntQueryParam(r, "to", 0)
+	if err != nil || to < 0 {
+		http.Error(w, "Invalid to parameter", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := a.getRulesDiff(r.Context(), userID, groupID, int32(from), int32(to))
+	if err != nil {
+		if errors.Is(err, ErrUserNotMember) || errors.Is(err, ErrUserKickedOrBanned) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrRulesVersionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error building rules diff: %v", err)
+		http.Error(w, "Error building rules diff", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(diff, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) AcceptRulesHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse the accepted version from the request body
+	acceptReq, err := ParseJSON[AcceptRulesRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = a.acceptRules(r.Context(), userID, groupID, acceptReq.Version)
+	if err != nil {
+		if errors.Is(err, ErrUserNotMember) || errors.Is(err, ErrUserKickedOrBanned) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrRulesVersionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrStaleRulesVersion) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Error accepting rules: %v", err)
+		http.Error(w, "Error accepting rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("User %v accepted the rules of group %v", userID, groupID)
+}
+
+func (a *APIConfig) GetRulesAcceptancesHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	acceptances, err := a.getRulesAcceptances(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving rules acceptances: %v", err)
+		http.Error(w, "Error retrieving rules acceptances", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(acceptances, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRulesNotAccepted     = errors.New("you must accept the current group rules before posting")
+	ErrRulesVersionNotFound = errors.New("rules version not found")
+	ErrStaleRulesVersion    = errors.New("the rules have changed, please review the latest version")
+)
+
+// recordRulesVersion saves rules as the group's next version. The admin who
+// wrote them is counted as having accepted them. q must be bound to a
+// transaction so the group stays locked until the version is committed.
+func recordRulesVersion(ctx context.Context, q *database.Queries, groupID, userID uuid.UUID, rules string) error {
+	// Two admins saving at once would otherwise pick the same version number
+	if err := q.LockGroupRules(ctx, groupID); err != nil {
+		return fmt.Errorf("error locking group rules: %w", err)
+	}
+
+	version, err := q.CreateRulesVersion(ctx, database.CreateRulesVersionParams{
+		GroupID:   groupID,
+		Rules:     rules,
+		CreatedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("error recording rules version: %w", err)
+	}
+
+	err = q.AcceptRules(ctx, database.AcceptRulesParams{
+		GroupID: groupID,
+		UserID:  userID,
+		Version: version.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("error recording rules acceptance: %w", err)
+	}
+
+	return nil
+}
+
+// latestRulesVersion returns 0 when the group has never had rules
+func (a *APIConfig) latestRulesVersion(ctx context.Context, groupID uuid.UUID) (int32, error) {
+	latest, err := a.DBQueries.GetLatestRulesVersion(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return latest.Version, nil
+}
+
+func (a *APIConfig) acceptedRulesVersion(ctx context.Context, userID, groupID uuid.UUID) (int32, error) {
+	version, err := a.DBQueries.GetAcceptedRulesVersion(ctx, database.GetAcceptedRulesVersionParams{
+		GroupID: groupID,
+		UserID:  userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+func (a *APIConfig) verifyRulesAccepted(ctx context.Context, userID, groupID uuid.UUID) error {
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if !group.RequireRulesAcceptance {
+		return nil
+	}
+
+	latest, err := a.latestRulesVersion(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	accepted, err := a.acceptedRulesVersion(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+
+	if accepted < latest {
+		return ErrRulesNotAccepted
+	}
+	return nil
+}
+
+func (a *APIConfig) getRulesHistory(ctx context.Context, userID, groupID uuid.UUID) (RulesHistory, error) {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return RulesHistory{}, err
+	}
+	if !isMember {
+		return RulesHistory{}, ErrUserNotMember
+	}
+
+	group, err := a.DBQueries.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return RulesHistory{}, fmt.Errorf("error retrieving group: %w", err)
+	}
+
+	versions, err := a.DBQueries.GetRulesVersions(ctx, groupID)
+	if err != nil {
+		return RulesHistory{}, fmt.Errorf("error retrieving rules versions: %w", err)
+	}
+
+	accepted, err := a.acceptedRulesVersion(ctx, userID, groupID)
+	if err != nil {
+		return RulesHistory{}, fmt.Errorf("error retrieving rules acceptance: %w", err)
+	}
+
+	history := RulesHistory{
+		AcceptedVersion:   accepted,
+		RequireAcceptance: group.RequireRulesAcceptance,
+		Versions:          make([]RulesVersion, len(versions)),
+	}
+	for i, version := range versions {
+		history.Versions[i] = RulesVersion{
+			Version:   version.Version,
+			Rules:     version.Rules,
+			CreatedBy: version.CreatedByUsername,
+			CreatedAt: formatNullTime(version.CreatedAt),
+		}
+	}
+	if len(versions) > 0 {
+		history.CurrentVersion = versions[0].Version
+	}
+
+	return history, nil
+}
+
+// getRulesDiff compares two versions line by line. A zero "to" means the
+// current version and a zero "from" means the version before "to".
+func (a *APIConfig) getRulesDiff(ctx context.Context, userID, groupID uuid.UUID, from, to int32) (RulesDiff, error) {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return RulesDiff{}, err
+	}
+	if !isMember {
+		return RulesDiff{}, ErrUserNotMember
+	}
+
+	if to == 0 {
+		if to, err = a.latestRulesVersion(ctx, groupID); err != nil {
+			return RulesDiff{}, fmt.Errorf("error retrieving rules version: %w", err)
+		}
+		if to == 0 {
+			return RulesDiff{}, ErrRulesVersionNotFound
+		}
+	}
+	if from == 0 {
+		from = to - 1
+	}
+
+	toRules, err := a.rulesAtVersion(ctx, groupID, to)
+	if err != nil {
+		return RulesDiff{}, err
+	}
+	// Version 0 is the empty rules before the first version
+	fromRules := ""
+	if from > 0 {
+		if fromRules, err = a.rulesAtVersion(ctx, groupID, from); err != nil {
+			return RulesDiff{}, err
+		}
+	}
+
+	return RulesDiff{
+		From:  from,
+		To:    to,
+		Lines: diffLines(splitLines(fromRules), splitLines(toRules)),
+	}, nil
+}
+
+func (a *APIConfig) rulesAtVersion(ctx context.Context, groupID uuid.UUID, version int32) (string, error) {
+	rules, err := a.DBQueries.GetRulesVersion(ctx, database.GetRulesVersionParams{
+		GroupID: groupID,
+		Version: version,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrRulesVersionNotFound
+		}
+		return "", fmt.Errorf("error retrieving rules version: %w", err)
+	}
+	return rules.Rules, nil
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// diffLines builds a line diff from the longest common subsequence. Rules are
+// capped at 1500 characters so the quadratic table stays small.
+func diffLines(from, to []string) []DiffLine {
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := []DiffLine{}
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, DiffLine{Op: "equal", Text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: "removed", Text: from[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: "added", Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, DiffLine{Op: "removed", Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, DiffLine{Op: "added", Text: to[j]})
+	}
+
+	return lines
+}
+
+func (a *APIConfig) acceptRules(ctx context.Context, userID, groupID uuid.UUID, version int32) error {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrUserNotMember
+	}
+
+	latest, err := a.latestRulesVersion(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("error retrieving rules version: %w", err)
+	}
+	if latest == 0 {
+		return ErrRulesVersionNotFound
+	}
+
+	// Make sure the member accepted what they actually read
+	if version != 0 && version != latest {
+		return ErrStaleRulesVersion
+	}
+
+	err = a.DBQueries.AcceptRules(ctx, database.AcceptRulesParams{
+		GroupID: groupID,
+		UserID:  userID,
+		Version: latest,
+	})
+	if err != nil {
+		return fmt.Errorf("error accepting rules: %w", err)
+	}
+
+	return nil
+}
+
+func (a *APIConfig) getRulesAcceptances(ctx context.Context, userID, groupID uuid.UUID) ([]RulesAcceptance, error) {
+	// Verify the user is an admin of the group
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	latest, err := a.latestRulesVersion(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving rules version: %w", err)
+	}
+
+	acceptances, err := a.DBQueries.GetRulesAcceptances(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving rules acceptances: %w", err)
+	}
+
+	jsonAcceptances := make([]RulesAcceptance, len(acceptances))
+	for i, acceptance := range acceptances {
+		jsonAcceptances[i] = RulesAcceptance{
+			UserID:          acceptance.ID,
+			Username:        acceptance.Username,
+			AcceptedVersion: acceptance.AcceptedVersion,
+			AcceptedAt:      formatNullTime(acceptance.AcceptedAt),
+			Current:         acceptance.AcceptedVersion >= latest,
+		}
+	}
+
+	return jsonAcceptances, nil
+}
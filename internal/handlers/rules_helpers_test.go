@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+func TestRecordRulesVersionConcurrent(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	q := database.New(db)
+
+	admin, err := q.CreateUser(ctx, database.CreateUserParams{
+		Username:       "rulesadmin",
+		Email:          "rulesadmin@example.com",
+		HashedPassword: "x",
+	})
+	if err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+	group, err := q.CreateGroup(ctx, database.CreateGroupParams{
+		Name:       "Rules",
+		OwnerID:    uuid.NullUUID{UUID: admin.ID, Valid: true},
+		InviteCode: "RULES001",
+	})
+	if err != nil {
+		t.Fatalf("error creating group: %v", err)
+	}
+
+	// The first save holds its transaction open while the second starts
+	first, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback()
+	if err := recordRulesVersion(ctx, q.WithTx(first), group.ID, admin.ID, "First"); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		second, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			done <- err
+			return
+		}
+		defer second.Rollback()
+		if err := recordRulesVersion(ctx, q.WithTx(second), group.ID, admin.ID, "Second"); err != nil {
+			done <- err
+			return
+		}
+		done <- second.Commit()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if err := first.Commit(); err != nil {
+		t.Fatalf("first commit: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("second save: %v", err)
+	}
+
+	latest, err := q.GetLatestRulesVersion(ctx, group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Version != 2 || latest.Rules != "Second" {
+		t.Errorf("latest version = %d %q, want 2 %q", latest.Version, latest.Rules, "Second")
+	}
+}
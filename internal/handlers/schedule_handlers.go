@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+func (a *APIConfig) GetArchivedPostsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse query parameters for pagination
+	limit, err := parseIntQueryParam(r, "limit", 10)
+	if err != nil {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	// Archived posts use the same filters as the feed
+	filter := postFeedFilter{
+		Category: r.URL.Query().Get("category"),
+		Status:   r.URL.Query().Get("status"),
+		Archived: true,
+	}
+	var posts any
+	if wantsCursorPage(r) {
+		posts, err = a.getPostFeedPage(r.Context(), userID, groupID, limit, r.URL.Query().Get("cursor"), filter)
+	} else {
+		posts, err = a.getPostFeed(r.Context(), userID, groupID, limit, offset, filter)
+	}
+	if err != nil {
+		if errors.Is(err, ErrUserNotMember) {
+			http.Error(w, "User is not a member of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidCategory) {
+			http.Error(w, "Invalid category parameter", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrInvalidPostStatus) {
+			http.Error(w, "Invalid status parameter", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrInvalidCursor) || errors.Is(err, ErrInvalidPageSize) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error retrieving archived posts: %v", err)
+		http.Error(w, "Failed to get archived posts", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(posts, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) GetScheduledPostsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	posts, err := a.getScheduledPosts(r.Context(), userID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		log.Printf("Error retrieving scheduled posts: %v", err)
+		http.Error(w, "Failed to get scheduled posts", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(posts, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPublishAt  = errors.New("publish_at must be an RFC3339 time within the next 90 days")
+	ErrScheduleAdminOnly = errors.New("only admins can schedule posts")
+)
+
+const maxScheduleAhead = 90 * 24 * time.Hour
+
+// resolvePublishAt validates when a new post should be published. An empty
+// value publishes the post straight away.
+func (a *APIConfig) resolvePublishAt(ctx context.Context, userID, groupID uuid.UUID, publishAt string) (sql.NullTime, error) {
+	if publishAt == "" {
+		return sql.NullTime{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, publishAt)
+	if err != nil || !t.After(time.Now()) || time.Until(t) > maxScheduleAhead {
+		return sql.NullTime{}, ErrInvalidPublishAt
+	}
+
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			return sql.NullTime{}, ErrScheduleAdminOnly
+		}
+		return sql.NullTime{}, err
+	}
+
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+func (a *APIConfig) getScheduledPosts(ctx context.Context, userID, groupID uuid.UUID) ([]Post, error) {
+	// Only admins can see posts that haven't been published yet
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	posts, err := a.DBQueries.GetScheduledPosts(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonPosts := make([]Post, len(posts))
+	for i, post := range posts {
+		jsonPosts[i] = Post{
+			ID:        post.ID,
+			GroupID:   post.GroupID,
+			UserID:    post.UserID,
+			Content:   post.Content,
+			Category:  post.Category,
+			CreatedAt: post.CreatedAt.Time.Format(time.RFC3339),
+			Author:    post.Username.String,
+			Anonymous: post.IsAnonymous,
+			Audience:  post.Audience,
+			Edited:    post.EditedAt.Valid,
+			EditedAt:  formatNullTime(post.EditedAt),
+			PublishAt: formatNullTime(post.PublishAt),
+		}
+	}
+
+	return jsonPosts, nil
+}
+
+// unarchivePost brings an archived post back into the feed when there's new
+// activity on it. Failures are logged so they don't block the activity.
+func (a *APIConfig) unarchivePost(ctx context.Context, post database.GetPostByIDRow) {
+	if !post.ArchivedAt.Valid {
+		return
+	}
+	if err := a.DBQueries.UnarchivePost(ctx, post.ID); err != nil {
+		log.Printf("Error unarchiving post %v: %v", post.ID, err)
+	}
+}
+
+// PostsPublished is called by the scheduler once scheduled posts appear in
+// their feeds, so webhooks see them as newly created
+func (a *APIConfig) PostsPublished(ctx context.Context, posts []database.Post) {
+	for _, post := range posts {
+		if post.Audience != audienceMembers {
+			continue
+		}
+
+		hookPost := Post{
+			ID:        post.ID,
+			GroupID:   post.GroupID,
+			UserID:    post.UserID,
+			Content:   post.Content,
+			Category:  post.Category,
+			Status:    post.Status,
+			CreatedAt: post.CreatedAt.Time.Format(time.RFC3339),
+			Anonymous: post.IsAnonymous,
+			Audience:  post.Audience,
+		}
+		maskPostAuthor(&hookPost, uuid.Nil, false)
+		a.dispatchWebhookEvent(ctx, post.GroupID, eventPostCreated, hookPost)
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Stats are aggregates over whole weeks, a few minutes of staleness is fine
+const statsCacheControl = "private, max-age=300"
+
+func (a *APIConfig) GetGroupStatsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	weeks, err := parseIntQueryParam(r, "weeks", defaultStatsWeeks)
+	if err != nil {
+		http.Error(w, "Invalid weeks parameter", http.StatusBadRequest)
+		return
+	}
+
+	// CSV can be asked for with ?format=csv or an Accept header
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+		if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+			format = "csv"
+		}
+	}
+	if format != "json" && format != "csv" {
+		http.Error(w, "Invalid format parameter", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := a.getGroupStats(r.Context(), userID, groupID, weeks)
+	if err != nil {
+		if errors.Is(err, ErrUserNotAdmin) {
+			http.Error(w, "User is not an admin of the group", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidStatsRange) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error retrieving group stats: %v", err)
+		http.Error(w, "Error retrieving group stats", http.StatusInternalServerError)
+		return
+	}
+
+	// Render the body first so the ETag can be derived from it
+	var body bytes.Buffer
+	contentType := "application/json"
+	if format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+		err = writeGroupStatsCSV(&body, stats)
+	} else {
+		err = json.NewEncoder(&body).Encode(stats)
+	}
+	if err != nil {
+		log.Printf("Error encoding group stats: %v", err)
+		http.Error(w, "Error encoding group stats", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("Cache-Control", statsCacheControl)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Vary", "Accept")
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == "csv" {
+		w.Header().Set("Content-Disposition", `attachment; filename="group-stats.csv"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		log.Printf("Error writing group stats response: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidStatsRange = errors.New("weeks must be between 1 and 52")
+
+const (
+	defaultStatsWeeks = 12
+	maxStatsWeeks     = 52
+
+	// Members who haven't posted or commented in this window count as dormant
+	activeMemberWindow = 30 * 24 * time.Hour
+)
+
+func (a *APIConfig) getGroupStats(ctx context.Context, userID, groupID uuid.UUID, weeks int) (GroupStats, error) {
+	// Only admins can see group analytics
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return GroupStats{}, err
+	}
+
+	if weeks < 1 || weeks > maxStatsWeeks {
+		return GroupStats{}, ErrInvalidStatsRange
+	}
+
+	// The current week counts as the first one
+	since := time.Now().AddDate(0, 0, -7*(weeks-1))
+
+	weekly, err := a.DBQueries.GetGroupWeeklyActivity(ctx, database.GetGroupWeeklyActivityParams{
+		Since:   since,
+		GroupID: groupID,
+	})
+	if err != nil {
+		return GroupStats{}, fmt.Errorf("error retrieving weekly activity: %w", err)
+	}
+
+	members, err := a.DBQueries.GetGroupMemberActivity(ctx, database.GetGroupMemberActivityParams{
+		ActiveSince: time.Now().Add(-activeMemberWindow),
+		GroupID:     groupID,
+	})
+	if err != nil {
+		return GroupStats{}, fmt.Errorf("error retrieving member activity: %w", err)
+	}
+
+	responses, err := a.DBQueries.GetGroupResponseStats(ctx, database.GetGroupResponseStatsParams{
+		GroupID: groupID,
+		Since:   since,
+	})
+	if err != nil {
+		return GroupStats{}, fmt.Errorf("error retrieving response stats: %w", err)
+	}
+
+	stats := GroupStats{
+		GroupID:                     groupID,
+		Weeks:                       weeks,
+		Members:                     members.Members,
+		ActiveMembers:               members.ActiveMembers,
+		DormantMembers:              members.Members - members.ActiveMembers,
+		MedianSecondsToFirstComment: responses.MedianSecondsToFirstComment,
+		Weekly:                      make([]WeeklyActivity, 0, len(weekly)),
+	}
+	if responses.Requests > 0 {
+		stats.AnsweredRate = float64(responses.Answered) / float64(responses.Requests)
+	}
+
+	for _, week := range weekly {
+		stats.Posts += week.Posts
+		stats.Comments += week.Comments
+		stats.NewJoins += week.NewJoins
+		stats.Weekly = append(stats.Weekly, WeeklyActivity{
+			WeekStart:     week.WeekStart.Format("2006-01-02"),
+			Posts:         week.Posts,
+			Comments:      week.Comments,
+			ActiveMembers: week.ActiveMembers,
+			NewJoins:      week.NewJoins,
+		})
+	}
+
+	return stats, nil
+}
+
+// writeGroupStatsCSV writes the summary as metric,value rows followed by a
+// blank line and the weekly breakdown, one row per week
+func writeGroupStatsCSV(w io.Writer, stats GroupStats) error {
+	writer := csv.NewWriter(w)
+
+	summary := [][]string{
+		{"metric", "value"},
+		{"weeks", strconv.Itoa(stats.Weeks)},
+		{"members", strconv.FormatInt(stats.Members, 10)},
+		{"active_members", strconv.FormatInt(stats.ActiveMembers, 10)},
+		{"dormant_members", strconv.FormatInt(stats.DormantMembers, 10)},
+		{"new_joins", strconv.FormatInt(stats.NewJoins, 10)},
+		{"posts", strconv.FormatInt(stats.Posts, 10)},
+		{"comments", strconv.FormatInt(stats.Comments, 10)},
+		{"answered_rate", strconv.FormatFloat(stats.AnsweredRate, 'f', 4, 64)},
+		{"median_seconds_to_first_comment", strconv.FormatFloat(stats.MedianSecondsToFirstComment, 'f', 0, 64)},
+		{},
+	}
+	if err := writer.WriteAll(summary); err != nil {
+		return err
+	}
+
+	if err := writer.Write([]string{"week_start", "posts", "comments", "active_members", "new_joins"}); err != nil {
+		return err
+	}
+	for _, week := range stats.Weekly {
+		err := writer.Write([]string{
+			week.WeekStart,
+			strconv.FormatInt(week.Posts, 10),
+			strconv.FormatInt(week.Comments, 10),
+			strconv.FormatInt(week.ActiveMembers, 10),
+			strconv.FormatInt(week.NewJoins, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
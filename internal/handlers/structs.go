@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 
+	"github.com/TheJa750/PrayerPals/internal/activity"
 	"github.com/TheJa750/PrayerPals/internal/database"
 	"github.com/google/uuid"
 )
@@ -22,8 +24,17 @@ type JsonError struct {
 }
 
 type APIConfig struct {
+	DB        *sql.DB // Used to open transactions, queries go through DBQueries
 	DBQueries *database.Queries
 	JWTSecret string
+	Activity  *activity.Tracker // Batches last seen updates, nil disables tracking
+
+	// How deeply comments can nest, 1 only allows comments on posts.
+	// 0 uses DefaultMaxReplyDepth.
+	MaxReplyDepth int
+
+	// Lets webhooks target localhost over http, only set in development
+	AllowLocalWebhooks bool
 }
 
 type UserRequest struct {
@@ -57,31 +68,246 @@ type Group struct {
 	OwnerID     uuid.UUID `json:"owner_id"`
 	InviteCode  string    `json:"invite_code"`
 	RulesInfo   string    `json:"rules_info"`
+	AvatarURL   string    `json:"avatar_url"`
+	PostPolicy  string    `json:"post_policy"` // "everyone" or "admins"
+	JoinMode    string    `json:"join_mode"`   // "open", "approval" or "closed"
+	Listed      bool      `json:"listed"`      // Whether the group appears in the public directory
+	OrgID       uuid.UUID `json:"organization_id"`
+
+	RequireApproval bool  `json:"require_second_approval"`  // Destructive actions need a second admin
+	RequireRules    bool  `json:"require_rules_acceptance"` // Members must accept the latest rules to post
+	EditWindow      int32 `json:"edit_window_minutes"`      // How long authors can edit, 0 means no limit
+	AllowAnonymous  bool  `json:"allow_anonymous"`          // Members may hide their name on posts and comments
+	AutoArchiveDays int32 `json:"auto_archive_days"`        // Archive posts after this many days without activity, 0 means never
+}
+
+type GroupSettingsRequest struct {
+	// Fields left out of the request are not changed
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	AvatarURL   *string `json:"avatar_url"`
+	Rules       *string `json:"rules"`
+	PostPolicy  *string `json:"post_policy"`
+	JoinMode    *string `json:"join_mode"`
+	Listed      *bool   `json:"listed"`
+
+	RequireApproval *bool  `json:"require_second_approval"`
+	RequireRules    *bool  `json:"require_rules_acceptance"`
+	EditWindow      *int32 `json:"edit_window_minutes"`
+	AllowAnonymous  *bool  `json:"allow_anonymous"`
+	AutoArchiveDays *int32 `json:"auto_archive_days"`
+}
+
+type DirectoryGroup struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	AvatarURL   string    `json:"avatar_url"`
+	InviteCode  string    `json:"invite_code"`
+	JoinMode    string    `json:"join_mode"`
+	MemberCount int64     `json:"member_count"`
+}
+
+type OrganizationRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Organization struct {
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description"`
+	OwnerID           uuid.UUID `json:"owner_id"`
+	DefaultRules      string    `json:"default_rules"`       // Rules copied into new groups
+	DefaultJoinMode   string    `json:"default_join_mode"`   // Join mode for new groups
+	DefaultPostPolicy string    `json:"default_post_policy"` // Post policy for new groups
+}
+
+type OrganizationSettingsRequest struct {
+	// Fields left out of the request are not changed
+	Name              *string `json:"name"`
+	Description       *string `json:"description"`
+	DefaultRules      *string `json:"default_rules"`
+	DefaultJoinMode   *string `json:"default_join_mode"`
+	DefaultPostPolicy *string `json:"default_post_policy"`
+}
+
+type OrganizationAdminRequest struct {
+	Email string `json:"email"` // Email of the user to make an organization admin
+}
+
+type OrganizationAdmin struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+}
+
+type OrganizationGroup struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	JoinMode    string    `json:"join_mode"`
+	PostPolicy  string    `json:"post_policy"`
+	MemberCount int64     `json:"member_count"`
+}
+
+type OrganizationMembership struct {
+	GroupCount        int64 `json:"group_count"`
+	MembershipCount   int64 `json:"membership_count"`    // Memberships summed across groups
+	UniqueMemberCount int64 `json:"unique_member_count"` // People in at least one group
 }
 
 type PostRequest struct {
-	Content string `json:"content"`
+	Content   string `json:"content"`
+	Category  string `json:"category"`  // Defaults to "prayer_request", ignored for comments and edits
+	Anonymous bool   `json:"anonymous"` // Hide the author from members, only allowed if the group permits it
+
+	// Who can see the post, ignored for comments and edits
+	Audience        string      `json:"audience"`          // "members" (default), "leaders" or "custom"
+	AudienceUserIDs []uuid.UUID `json:"audience_user_ids"` // Members who can see a custom audience post
+
+	PublishAt string `json:"publish_at"` // Optional RFC3339 time to publish a top-level post later, admins only
+}
+
+type PostCategory struct {
+	Name      string `json:"name"`
+	BuiltIn   bool   `json:"built_in"`   // False for categories the group added
+	AdminOnly bool   `json:"admin_only"` // Only admins can post announcements
+}
+
+type PostCategoryRequest struct {
+	Name string `json:"name"`
 }
 
 type Post struct {
-	ID           uuid.UUID `json:"id"`
-	GroupID      uuid.UUID `json:"group_id"`
+	ID           uuid.UUID   `json:"id"`
+	GroupID      uuid.UUID   `json:"group_id"`
+	UserID       uuid.UUID   `json:"user_id"`
+	Content      string      `json:"content"`
+	Category     string      `json:"category,omitempty"` // e.g., "prayer_request", "praise_report" or a group category
+	CreatedAt    string      `json:"created_at"`
+	Author       string      `json:"author"`                      // Username of the post author, "Anonymous" when hidden from the viewer
+	Anonymous    bool        `json:"anonymous"`                   // Posted without the author's name
+	Audience     string      `json:"audience,omitempty"`          // "members", "leaders" or "custom"
+	AudienceIDs  []uuid.UUID `json:"audience_user_ids,omitempty"` // Who can see a custom audience post, shown to the author and leaders
+	CommentCount int64       `json:"comment_count"`               // Number of comments on the post
+	Comments     []Comment   `json:"comments"`                    // Comments associated with the post
+	Pinned       bool        `json:"pinned"`
+	PinOrder     int32       `json:"pin_order,omitempty"`    // Lower numbers are shown first
+	PinnedUntil  string      `json:"pinned_until,omitempty"` // Empty when the pin doesn't expire
+	Edited       bool        `json:"edited"`
+	EditedAt     string      `json:"edited_at,omitempty"` // Time of the latest edit
+	Status       string      `json:"status,omitempty"`    // "open", "ongoing", "answered" or "closed"
+	AnsweredAt   string      `json:"answered_at,omitempty"`
+	AnswerNote   string      `json:"answer_note,omitempty"` // Testimony or update shared when answered
+	PrayedCount  int64       `json:"prayed_count"`          // Number of people who prayed
+	PrayedByMe   bool        `json:"prayed_by_me"`
+	PublishAt    string      `json:"publish_at,omitempty"` // When a scheduled post will appear in the feed
+	Archived     bool        `json:"archived"`             // Moved out of the feed after a period without activity
+	ArchivedAt   string      `json:"archived_at,omitempty"`
+}
+
+// PostPage is one page of a cursor paginated list, cursors are empty when
+// there's nothing further in that direction
+type PostPage struct {
+	Posts      []Post `json:"posts"`
+	NextCursor string `json:"next_cursor,omitempty"` // Older posts
+	PrevCursor string `json:"prev_cursor,omitempty"` // Newer posts
+}
+
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"next_cursor,omitempty"` // Older comments
+	PrevCursor string    `json:"prev_cursor,omitempty"` // Newer comments
+}
+
+type PrayerSummary struct {
+	PostID      uuid.UUID `json:"post_id"`
+	PrayedCount int64     `json:"prayed_count"` // Number of people who prayed
+	PrayerCount int64     `json:"prayer_count"` // Responses including repeats on later days
+	PrayedByMe  bool      `json:"prayed_by_me"`
+}
+
+type PrayerRosterEntry struct {
 	UserID       uuid.UUID `json:"user_id"`
-	Content      string    `json:"content"`
-	CreatedAt    string    `json:"created_at"`
-	Author       string    `json:"author"`        // Username of the post author
-	CommentCount int64     `json:"comment_count"` // Number of comments on the post
-	Comments     []Comment `json:"comments"`      // Comments associated with the post
+	Username     string    `json:"username"`
+	TimesPrayed  int64     `json:"times_prayed"` // Days this member prayed for the request
+	LastPrayedAt string    `json:"last_prayed_at"`
 }
 
-type Comment struct {
+type CommitmentRequest struct {
+	Frequency string `json:"frequency"` // daily or weekly, defaults to daily
+	Until     string `json:"until"`     // RFC3339 time the commitment ends
+}
+
+type Commitment struct {
+	ID             uuid.UUID `json:"id"`
+	PostID         uuid.UUID `json:"post_id"`
+	GroupID        uuid.UUID `json:"group_id"`
+	GroupName      string    `json:"group_name,omitempty"`
+	Author         string    `json:"author,omitempty"`
+	Content        string    `json:"content,omitempty"`
+	Frequency      string    `json:"frequency"`
+	Until          string    `json:"until"`
+	NextReminderAt string    `json:"next_reminder_at"`
+	LastRemindedAt string    `json:"last_reminded_at,omitempty"`
+	CreatedAt      string    `json:"created_at"`
+}
+
+type PostStatusRequest struct {
+	Status string `json:"status"` // "open", "ongoing", "answered" or "closed"
+	Note   string `json:"note"`   // Optional testimony, only kept for answered requests
+}
+
+type PostRevision struct {
 	ID        uuid.UUID `json:"id"`
-	PostID    uuid.UUID `json:"post_id"`
-	GroupID   uuid.UUID `json:"group_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Content   string    `json:"content"`
-	CreatedAt string    `json:"created_at"`
-	Author    string    `json:"author"` // Username of the comment author
+	Content   string    `json:"content"`   // Content before the edit
+	EditedBy  uuid.UUID `json:"edited_by"` // User who made the edit
+	Editor    string    `json:"editor"`
+	CreatedAt string    `json:"created_at"` // When the edit replaced this content
+}
+
+type PostHistory struct {
+	PostID    uuid.UUID      `json:"post_id"`
+	Content   string         `json:"content"` // Current content
+	EditedAt  string         `json:"edited_at,omitempty"`
+	Revisions []PostRevision `json:"revisions"` // Oldest first
+}
+
+type PinPostRequest struct {
+	Order int32  `json:"order"` // Display position among pinned posts
+	Until string `json:"until"` // Optional RFC3339 time when the pin expires
+}
+
+type ModerationLogEntry struct {
+	ID             uuid.UUID `json:"id"`
+	Action         string    `json:"action"` // e.g. "kick", "unban", "remove_content", "pin"
+	ActorID        uuid.UUID `json:"actor_id"`
+	ActorUsername  string    `json:"actor_username"`
+	TargetUserID   uuid.UUID `json:"target_user_id"`
+	TargetUsername string    `json:"target_username"`
+	TargetPostID   uuid.UUID `json:"target_post_id"`
+	Reason         string    `json:"reason"`
+	CreatedAt      string    `json:"created_at"`
+}
+
+type Comment struct {
+	ID         uuid.UUID `json:"id"`
+	PostID     uuid.UUID `json:"post_id"`   // Top-level post the thread belongs to
+	ParentID   uuid.UUID `json:"parent_id"` // Post or comment this replies to
+	GroupID    uuid.UUID `json:"group_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Content    string    `json:"content"`
+	CreatedAt  string    `json:"created_at"`
+	Author     string    `json:"author"` // Username of the comment author, "Anonymous" when hidden from the viewer
+	Anonymous  bool      `json:"anonymous"`
+	Edited     bool      `json:"edited"`
+	EditedAt   string    `json:"edited_at,omitempty"`
+	Depth      int32     `json:"depth"`       // 1 for comments on the post, 2 for replies to them and so on
+	ReplyCount int64     `json:"reply_count"` // Direct replies to this comment
+	Replies    []Comment `json:"replies"`     // Newest first
 }
 
 type PromoteUserRequest struct {
@@ -95,15 +321,170 @@ type PostFeedRequest struct {
 }
 
 type ModerateUserRequest struct {
-	Action string `json:"action"` // e.g., "kick", "ban"
-	Reason string `json:"reason"` // Reason for the action
+	Action        string `json:"action"`         // e.g., "kick", "ban", "mute", "unkick", "unban", "unmute"
+	Reason        string `json:"reason"`         // Reason for the action
+	DurationHours int    `json:"duration_hours"` // Optional length of a kick or mute
+	Until         string `json:"until"`          // Optional RFC3339 end time of a kick or mute, overrides duration_hours
 }
 
 type UserJoinGroup struct {
 	UserID    uuid.UUID `json:"user_id"`
 	GroupID   uuid.UUID `json:"group_id"`
 	GroupName string    `json:"group_name"`
-	Role      string    `json:"role"` // e.g., "admin", "member"
+	Role      string    `json:"role"`   // e.g., "admin", "member"
+	Status    string    `json:"status"` // "joined", or "pending" when the group requires approval
+}
+
+type JoinRequest struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	RequestedAt string    `json:"requested_at"`
+}
+
+type DeleteGroupRequest struct {
+	ConfirmName string `json:"confirm_name"` // Optional, must match the group name exactly when set
+}
+
+type DeletedGroup struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	DeletedAt    string    `json:"deleted_at"`
+	RestoreUntil string    `json:"restore_until"`
+}
+
+type PendingAction struct {
+	ID                  uuid.UUID `json:"id"`
+	Action              string    `json:"action"` // "delete_group" or "remove_content"
+	TargetUserID        uuid.UUID `json:"target_user_id"`
+	TargetUsername      string    `json:"target_username"`
+	RequestedBy         uuid.UUID `json:"requested_by"`
+	RequestedByUsername string    `json:"requested_by_username"`
+	Status              string    `json:"status"` // "pending", "approved", "cancelled" or "expired"
+	CreatedAt           string    `json:"created_at"`
+	ExpiresAt           string    `json:"expires_at"`
+}
+
+type RulesVersion struct {
+	Version   int32  `json:"version"`
+	Rules     string `json:"rules"`
+	CreatedBy string `json:"created_by"` // Username of the admin who saved this version
+	CreatedAt string `json:"created_at"`
+}
+
+type RulesHistory struct {
+	CurrentVersion    int32          `json:"current_version"`  // 0 when the group has never had rules
+	AcceptedVersion   int32          `json:"accepted_version"` // Latest version the requesting user accepted
+	RequireAcceptance bool           `json:"require_acceptance"`
+	Versions          []RulesVersion `json:"versions"` // Newest first
+}
+
+type DiffLine struct {
+	Op   string `json:"op"` // "equal", "added" or "removed"
+	Text string `json:"text"`
+}
+
+type RulesDiff struct {
+	From  int32      `json:"from"`
+	To    int32      `json:"to"`
+	Lines []DiffLine `json:"lines"`
+}
+
+type AcceptRulesRequest struct {
+	Version int32 `json:"version"` // Version the member read, must be the current one
+}
+
+type RulesAcceptance struct {
+	UserID          uuid.UUID `json:"user_id"`
+	Username        string    `json:"username"`
+	AcceptedVersion int32     `json:"accepted_version"` // 0 if never accepted
+	AcceptedAt      string    `json:"accepted_at"`
+	Current         bool      `json:"current"` // Accepted the latest version
+}
+
+type ImportRow struct {
+	Line   int      `json:"line"` // Line number in the uploaded CSV
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Role   string   `json:"role"`
+	Status string   `json:"status"` // "add", "invite", "already_member" or "invalid"
+	Errors []string `json:"errors,omitempty"`
+
+	Warnings []string `json:"warnings,omitempty"` // Changes made to the row, e.g. a downgraded role
+}
+
+type ImportReport struct {
+	Committed bool        `json:"committed"` // False for a dry run
+	Added     int         `json:"added"`     // Existing users added to the group
+	Invited   int         `json:"invited"`   // Unregistered emails sent an invitation
+	Skipped   int         `json:"skipped"`   // Already members
+	Invalid   int         `json:"invalid"`
+	Rows      []ImportRow `json:"rows"`
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // Any of the webhook event names, e.g. "post.created"
+}
+
+type WebhookUpdateRequest struct {
+	// Fields left out of the request are not changed
+	URL      *string   `json:"url"`
+	Events   *[]string `json:"events"`
+	IsActive *bool     `json:"is_active"`
+}
+
+type Webhook struct {
+	ID        uuid.UUID `json:"id"`
+	GroupID   uuid.UUID `json:"group_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	IsActive  bool      `json:"is_active"`
+	Secret    string    `json:"secret,omitempty"` // Only returned when the webhook is created
+	CreatedAt string    `json:"created_at"`
+	UpdatedAt string    `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // "pending", "retrying", "succeeded" or "failed"
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus int32           `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+}
+
+type WeeklyActivity struct {
+	WeekStart     string `json:"week_start"`
+	Posts         int64  `json:"posts"`
+	Comments      int64  `json:"comments"`
+	ActiveMembers int64  `json:"active_members"` // Distinct members who posted or commented that week
+	NewJoins      int64  `json:"new_joins"`
+}
+
+type GroupStats struct {
+	GroupID        uuid.UUID `json:"group_id"`
+	Weeks          int       `json:"weeks"`
+	Members        int64     `json:"members"`
+	ActiveMembers  int64     `json:"active_members"`  // Posted or commented in the last 30 days
+	DormantMembers int64     `json:"dormant_members"` // Members with no recent activity
+	NewJoins       int64     `json:"new_joins"`
+	Posts          int64     `json:"posts"`
+	Comments       int64     `json:"comments"`
+	AnsweredRate   float64   `json:"answered_rate"` // Share of requests in the range marked answered
+	// Median seconds from a request being posted to its first comment, 0 when nothing was answered
+	MedianSecondsToFirstComment float64          `json:"median_seconds_to_first_comment"`
+	Weekly                      []WeeklyActivity `json:"weekly"`
+}
+
+type ReviewJoinRequest struct {
+	Action string `json:"action"` // "approve" or "deny"
 }
 
 type UpdateUserRequest struct {
@@ -112,10 +493,36 @@ type UpdateUserRequest struct {
 }
 
 type GroupMember struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	Role     string    `json:"role"` // e.g., "admin", "member"
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email,omitempty"`        // Left out when the member restricts it
+	Role       string    `json:"role"`                   // e.g., "admin", "member"
+	JoinedAt   string    `json:"joined_at,omitempty"`    // Empty for members who joined before this was recorded
+	LastSeenAt string    `json:"last_seen_at,omitempty"` // Only shown to admins
+}
+
+type PrivacySettings struct {
+	EmailVisibility   string `json:"email_visibility"`    // "members" or "admins"
+	HideFromDirectory bool   `json:"hide_from_directory"` // Only admins see the user in member lists
+}
+
+type PrivacySettingsRequest struct {
+	// Fields left out of the request are not changed
+	EmailVisibility   *string `json:"email_visibility"`
+	HideFromDirectory *bool   `json:"hide_from_directory"`
+}
+
+type ModeratedMember struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`               // "kicked", "muted" or "banned"
+	Reason      string    `json:"reason"`               // Reason given by the moderator
+	ModeratedBy uuid.UUID `json:"moderated_by"`         // ID of the moderating admin
+	Moderator   string    `json:"moderator"`            // Username of the moderating admin
+	ModeratedAt string    `json:"moderated_at"`         // When the action was taken
+	ExpiresAt   string    `json:"expires_at,omitempty"` // When a kick or mute ends, empty for bans
 }
 
 type UpdateInviteCodeRequest struct {
@@ -131,7 +538,8 @@ type UpdateGroupDescriptionRequest struct {
 }
 
 type PostCountResponse struct {
-	PostCount int `json:"post_count"` // Total number of posts in the group
+	PostCount  int              `json:"post_count"`  // Total number of posts in the group
+	ByCategory map[string]int64 `json:"by_category"` // Posts per category, categories without posts are left out
 }
 
 func ParseJSON[T any](r *http.Request) (T, error) {
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -236,3 +237,58 @@ func (a *APIConfig) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 	log.Printf("User %v updated successfully", userID)
 }
+
+func (a *APIConfig) GetPrivacySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate user token
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := a.getPrivacySettings(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error retrieving privacy settings: %v", err)
+		http.Error(w, "Error retrieving privacy settings", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(settings, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) UpdatePrivacySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	// Parse request body for the settings to change
+	settingsReq, err := ParseJSON[PrivacySettingsRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate user token
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := a.updatePrivacySettings(r.Context(), userID, settingsReq)
+	if err != nil {
+		if errors.Is(err, ErrInvalidVisibility) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error updating privacy settings: %v", err)
+		http.Error(w, "Error updating privacy settings", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(settings, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Privacy settings updated for user %v", userID)
+}
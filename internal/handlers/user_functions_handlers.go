@@ -27,10 +27,24 @@ func (a *APIConfig) JoinGroupHandler(w http.ResponseWriter, r *http.Request) {
 	response, err := a.joinGroup(r.Context(), userID, "member", invCode)
 	if err != nil {
 		log.Printf("Error adding user to group: %v", err)
+		if errors.Is(err, ErrGroupClosed) {
+			http.Error(w, "Group is not accepting new members", http.StatusForbidden)
+			return
+		}
 		http.Error(w, "Error adding user to group", http.StatusInternalServerError)
 		return
 	}
 
+	// Groups requiring approval accept the request for later review
+	if response.Status == "pending" {
+		if err := CreateJSONResponse(response, w, http.StatusAccepted); err != nil {
+			log.Printf("Error creating JSON response: %v", err)
+			return
+		}
+		log.Printf("User %v requested to join group %v", userID, response.GroupID)
+		return
+	}
+
 	if err := CreateJSONResponse(response, w, http.StatusOK); err != nil {
 		log.Printf("Error creating JSON response: %v", err)
 		return
@@ -107,3 +121,42 @@ func (a *APIConfig) GetGroupsForFeed(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("User %v fetched groups for feed", userID)
 }
+
+func (a *APIConfig) DiscoverGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse query parameters for pagination
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil || limit < 1 || limit > 50 {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	groups, err := a.discoverGroups(r.Context(), r.URL.Query().Get("q"), limit, offset)
+	if err != nil {
+		if errors.Is(err, ErrSearchTooLong) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error discovering groups: %v", err)
+		http.Error(w, "Error searching groups", http.StatusInternalServerError)
+		return
+	}
+
+	err = CreateJSONResponse(groups, w, http.StatusOK)
+	if err != nil {
+		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("User %v searched the group directory", userID)
+}
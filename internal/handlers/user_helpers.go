@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,8 +20,33 @@ var (
 	ErrNoGroupFound       = errors.New("no group found for the provided invite code")
 	ErrUserIsMember       = errors.New("user is already a member of the group")
 	ErrUserKickedOrBanned = errors.New("user is kicked or banned from the group")
+	ErrUserMuted          = errors.New("user is muted in the group")
+	ErrSearchTooLong      = errors.New("search query cannot exceed 200 characters")
+	ErrInvalidVisibility  = errors.New("visibility must be 'members' or 'admins'")
 )
 
+// Visibility levels for profile fields shown to other group members
+const (
+	visibilityMembers = "members"
+	visibilityAdmins  = "admins"
+)
+
+var validVisibilities = []string{visibilityMembers, visibilityAdmins}
+
+// profileViewer is the user looking at another member's profile. Every
+// profile field with a visibility setting should be checked through canSee.
+type profileViewer struct {
+	userID  uuid.UUID
+	isAdmin bool
+}
+
+func (v profileViewer) canSee(ownerID uuid.UUID, visibility string) bool {
+	if v.userID == ownerID || v.isAdmin {
+		return true
+	}
+	return visibility == visibilityMembers
+}
+
 func (a *APIConfig) issueTokens(user database.User, jwtSecret string, activeTime time.Duration, ctx context.Context) (string, string, error) {
 	accessToken, err := auth.MakeJWT(user.ID, jwtSecret, activeTime)
 	if err != nil {
@@ -76,9 +102,23 @@ func (a *APIConfig) verifyUserInGroup(ctx context.Context, userID, groupID uuid.
 			if err != nil {
 				return false, fmt.Errorf("verifyUserInGroup: error checking kick/ban status: %w", err)
 			}
-			if modStatus.IsBanned || modStatus.IsKicked {
+			if modStatus.IsBanned {
 				return false, ErrUserKickedOrBanned
 			}
+			if modStatus.IsKicked {
+				if modStatus.KickedUntil.Time.After(time.Now()) {
+					return false, ErrUserKickedOrBanned
+				}
+
+				// Kick has expired, reset kick status
+				err = a.DBQueries.ResetKickStatus(ctx, database.ResetKickStatusParams{
+					UserID:  userID,
+					GroupID: groupID,
+				})
+				if err != nil {
+					return false, fmt.Errorf("verifyUserInGroup: error resetting kick status: %w", err)
+				}
+			}
 
 			return true, nil
 		}
@@ -87,6 +127,42 @@ func (a *APIConfig) verifyUserInGroup(ctx context.Context, userID, groupID uuid.
 	return false, nil
 }
 
+func (a *APIConfig) verifyUserCanPost(ctx context.Context, userID, groupID uuid.UUID) error {
+	// Verify if the user is a member of the group
+	isMember, err := a.verifyUserInGroup(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrUserNotMember
+	}
+
+	// Muted members can still read the group but cannot post or comment
+	modStatus, err := a.DBQueries.GetKickBanStatus(ctx, database.GetKickBanStatusParams{
+		UserID:  userID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		return fmt.Errorf("verifyUserCanPost: error checking mute status: %w", err)
+	}
+	if modStatus.IsMuted {
+		if modStatus.MutedUntil.Time.After(time.Now()) {
+			return ErrUserMuted
+		}
+
+		// Mute has expired, reset mute status
+		err = a.DBQueries.ResetMuteStatus(ctx, database.ResetMuteStatusParams{
+			UserID:  userID,
+			GroupID: groupID,
+		})
+		if err != nil {
+			return fmt.Errorf("verifyUserCanPost: error resetting mute status: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (a *APIConfig) verifyPostInGroup(ctx context.Context, postID, groupID uuid.UUID) (bool, error) {
 	post, err := a.DBQueries.GetPostByID(ctx, postID)
 	if err != nil {
@@ -155,6 +231,30 @@ func (a *APIConfig) joinGroup(ctx context.Context, userID uuid.UUID, role, invit
 		return UserJoinGroup{}, ErrUserIsMember
 	}
 
+	// Group creators join as admin regardless of join mode
+	if role != "admin" {
+		switch group.JoinMode {
+		case "closed":
+			return UserJoinGroup{}, ErrGroupClosed
+		case "approval":
+			err = a.DBQueries.CreateJoinRequest(ctx, database.CreateJoinRequestParams{
+				GroupID: group.ID,
+				UserID:  userID,
+			})
+			if err != nil {
+				return UserJoinGroup{}, fmt.Errorf("joinGroup: error creating join request: %w", err)
+			}
+
+			return UserJoinGroup{
+				UserID:    userID,
+				GroupID:   group.ID,
+				GroupName: group.Name,
+				Role:      role,
+				Status:    "pending",
+			}, nil
+		}
+	}
+
 	// Add user to the group
 	err = a.DBQueries.AddUserToGroup(ctx, database.AddUserToGroupParams{
 		UserID:  userID,
@@ -165,11 +265,14 @@ func (a *APIConfig) joinGroup(ctx context.Context, userID uuid.UUID, role, invit
 		return UserJoinGroup{}, fmt.Errorf("joinGroup: error adding user to group: %w", err)
 	}
 
+	a.dispatchWebhookEvent(ctx, group.ID, eventMemberJoined, webhookMember{UserID: userID, Role: role})
+
 	jsonResponse := UserJoinGroup{
 		UserID:    userID,
 		GroupID:   group.ID,
 		GroupName: group.Name,
 		Role:      role,
+		Status:    "joined",
 	}
 
 	return jsonResponse, nil
@@ -264,3 +367,99 @@ func (a *APIConfig) fetchGroupsForUser(ctx context.Context, userID uuid.UUID) ([
 
 	return jsonGroups, nil
 }
+
+func (a *APIConfig) discoverGroups(ctx context.Context, query string, limit, offset int) ([]DirectoryGroup, error) {
+	query = strings.TrimSpace(query)
+	if len(query) > 200 {
+		return nil, ErrSearchTooLong
+	}
+
+	// Only groups that opted into the directory are ever returned
+	jsonGroups := []DirectoryGroup{}
+	if query == "" {
+		groups, err := a.DBQueries.ListListedGroups(ctx, database.ListListedGroupsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("discoverGroups: error listing groups: %w", err)
+		}
+		for _, group := range groups {
+			jsonGroups = append(jsonGroups, DirectoryGroup{
+				ID:          group.ID,
+				Name:        group.Name,
+				Description: group.Description.String,
+				AvatarURL:   group.AvatarUrl,
+				InviteCode:  group.InviteCode,
+				JoinMode:    group.JoinMode,
+				MemberCount: group.MemberCount,
+			})
+		}
+		return jsonGroups, nil
+	}
+
+	groups, err := a.DBQueries.SearchListedGroups(ctx, database.SearchListedGroupsParams{
+		Query:  query,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discoverGroups: error searching groups: %w", err)
+	}
+	for _, group := range groups {
+		jsonGroups = append(jsonGroups, DirectoryGroup{
+			ID:          group.ID,
+			Name:        group.Name,
+			Description: group.Description.String,
+			AvatarURL:   group.AvatarUrl,
+			InviteCode:  group.InviteCode,
+			JoinMode:    group.JoinMode,
+			MemberCount: group.MemberCount,
+		})
+	}
+
+	return jsonGroups, nil
+}
+
+func (a *APIConfig) getPrivacySettings(ctx context.Context, userID uuid.UUID) (PrivacySettings, error) {
+	settings, err := a.DBQueries.GetPrivacySettings(ctx, userID)
+	if err != nil {
+		return PrivacySettings{}, fmt.Errorf("error retrieving privacy settings: %w", err)
+	}
+
+	return PrivacySettings{
+		EmailVisibility:   settings.EmailVisibility,
+		HideFromDirectory: settings.HideFromDirectory,
+	}, nil
+}
+
+func (a *APIConfig) updatePrivacySettings(ctx context.Context, userID uuid.UUID, req PrivacySettingsRequest) (PrivacySettings, error) {
+	// Start from the current settings so omitted fields are kept
+	settings, err := a.getPrivacySettings(ctx, userID)
+	if err != nil {
+		return PrivacySettings{}, err
+	}
+
+	if req.EmailVisibility != nil {
+		visibility := strings.ToLower(strings.TrimSpace(*req.EmailVisibility))
+		if !slices.Contains(validVisibilities, visibility) {
+			return PrivacySettings{}, ErrInvalidVisibility
+		}
+		settings.EmailVisibility = visibility
+	}
+
+	if req.HideFromDirectory != nil {
+		settings.HideFromDirectory = *req.HideFromDirectory
+	}
+
+	err = a.DBQueries.UpdatePrivacySettings(ctx, database.UpdatePrivacySettingsParams{
+		ID:                userID,
+		EmailVisibility:   settings.EmailVisibility,
+		HideFromDirectory: settings.HideFromDirectory,
+	})
+	if err != nil {
+		return PrivacySettings{}, fmt.Errorf("error updating privacy settings: %w", err)
+	}
+
+	return settings, nil
+}
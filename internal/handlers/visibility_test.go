@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+// Viewers of the visibility fixture
+const (
+	viewerOutsider = "non-member"
+	viewerMember   = "member"
+	viewerCustom   = "custom audience member"
+	viewerAuthor   = "author"
+	viewerLeader   = "leader"
+)
+
+// Posts of the visibility fixture, all written by viewerAuthor
+const (
+	kindMembers   = "members"
+	kindLeaders   = "leaders"
+	kindCustom    = "custom"
+	kindScheduled = "scheduled"
+)
+
+var (
+	visibilityViewers = []string{viewerOutsider, viewerMember, viewerCustom, viewerAuthor, viewerLeader}
+	visibilityKinds   = []string{kindMembers, kindLeaders, kindCustom, kindScheduled}
+)
+
+// canRead is the audience rule every read path must agree with when a post
+// is fetched directly
+func canRead(viewer, kind string) bool {
+	switch viewer {
+	case viewerAuthor, viewerLeader:
+		return true
+	case viewerCustom:
+		return kind == kindMembers || kind == kindCustom
+	case viewerMember:
+		return kind == kindMembers
+	default:
+		return false
+	}
+}
+
+// isListed is canRead for lists, which leave out posts that aren't published
+func isListed(viewer, kind string) bool {
+	return kind != kindScheduled && canRead(viewer, kind)
+}
+
+type visibilityFixture struct {
+	cfg      *APIConfig
+	groupID  uuid.UUID
+	users    map[string]uuid.UUID
+	posts    map[string]uuid.UUID // Open requests, pinned and commented on
+	answered map[string]uuid.UUID // Answered requests
+	comments map[string]uuid.UUID // The comment on each open request
+}
+
+func newVisibilityFixture(t *testing.T) visibilityFixture {
+	t.Helper()
+
+	db := testdb.Open(t)
+	ctx := context.Background()
+	f := visibilityFixture{
+		cfg:      &APIConfig{DB: db, DBQueries: database.New(db)},
+		users:    make(map[string]uuid.UUID),
+		posts:    make(map[string]uuid.UUID),
+		answered: make(map[string]uuid.UUID),
+		comments: make(map[string]uuid.UUID),
+	}
+	q := f.cfg.DBQueries
+
+	for i, viewer := range visibilityViewers {
+		user, err := q.CreateUser(ctx, database.CreateUserParams{
+			Username:       "viewer" + string(rune('a'+i)),
+			Email:          "viewer" + string(rune('a'+i)) + "@example.com",
+			HashedPassword: "x",
+		})
+		if err != nil {
+			t.Fatalf("error creating %s: %v", viewer, err)
+		}
+		f.users[viewer] = user.ID
+	}
+
+	group, err := q.CreateGroup(ctx, database.CreateGroupParams{
+		Name:       "Visibility",
+		OwnerID:    uuid.NullUUID{UUID: f.users[viewerLeader], Valid: true},
+		InviteCode: "VISIBLE1",
+	})
+	if err != nil {
+		t.Fatalf("error creating group: %v", err)
+	}
+	f.groupID = group.ID
+
+	roles := map[string]string{
+		viewerMember: "member",
+		viewerCustom: "member",
+		viewerAuthor: "member",
+		viewerLeader: "admin",
+	}
+	for viewer, role := range roles {
+		err := q.AddUserToGroup(ctx, database.AddUserToGroupParams{
+			UserID:  f.users[viewer],
+			GroupID: f.groupID,
+			Role:    role,
+		})
+		if err != nil {
+			t.Fatalf("error adding %s to group: %v", viewer, err)
+		}
+	}
+
+	for i, kind := range visibilityKinds {
+		f.posts[kind] = f.createPost(t, kind)
+		f.answered[kind] = f.createPost(t, kind)
+
+		_, err := q.SetPostStatus(ctx, database.SetPostStatusParams{
+			Status:    statusAnswered,
+			ChangedBy: uuid.NullUUID{UUID: f.users[viewerAuthor], Valid: true},
+			ID:        f.answered[kind],
+			GroupID:   f.groupID,
+		})
+		if err != nil {
+			t.Fatalf("error answering %s post: %v", kind, err)
+		}
+
+		_, err = q.PinPost(ctx, database.PinPostParams{
+			ID:       f.posts[kind],
+			GroupID:  f.groupID,
+			PinnedBy: uuid.NullUUID{UUID: f.users[viewerLeader], Valid: true},
+			PinOrder: int32(i),
+		})
+		if err != nil {
+			t.Fatalf("error pinning %s post: %v", kind, err)
+		}
+
+		comment, err := q.CreateComment(ctx, database.CreateCommentParams{
+			UserID:       f.users[viewerAuthor],
+			GroupID:      f.groupID,
+			Content:      "Comment on " + kind,
+			ParentPostID: uuid.NullUUID{UUID: f.posts[kind], Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("error commenting on %s post: %v", kind, err)
+		}
+		f.comments[kind] = comment.ID
+
+		_, err = q.RecordPrayerResponse(ctx, database.RecordPrayerResponseParams{
+			PostID: f.posts[kind],
+			UserID: f.users[viewerLeader],
+		})
+		if err != nil {
+			t.Fatalf("error praying for %s post: %v", kind, err)
+		}
+
+		// Everyone holds a commitment, as if they committed before losing access
+		for _, viewer := range visibilityViewers {
+			_, err := q.UpsertPrayerCommitment(ctx, database.UpsertPrayerCommitmentParams{
+				PostID:         f.posts[kind],
+				UserID:         f.users[viewer],
+				Frequency:      "daily",
+				EndsAt:         time.Now().Add(7 * 24 * time.Hour),
+				NextReminderAt: time.Now().Add(24 * time.Hour),
+			})
+			if err != nil {
+				t.Fatalf("error committing %s to %s post: %v", viewer, kind, err)
+			}
+		}
+	}
+
+	return f
+}
+
+func (f visibilityFixture) createPost(t *testing.T, kind string) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	params := database.CreatePostParams{
+		UserID:   f.users[viewerAuthor],
+		GroupID:  f.groupID,
+		Content:  "A " + kind + " request",
+		Category: "prayer_request",
+		Audience: kind,
+	}
+	if kind == kindScheduled {
+		params.Audience = audienceMembers
+		params.PublishAt.Time = time.Now().Add(24 * time.Hour)
+		params.PublishAt.Valid = true
+	}
+
+	post, err := f.cfg.DBQueries.CreatePost(ctx, params)
+	if err != nil {
+		t.Fatalf("error creating %s post: %v", kind, err)
+	}
+
+	if kind == kindCustom {
+		_, err := f.cfg.DBQueries.AddPostAudienceMembers(ctx, database.AddPostAudienceMembersParams{
+			PostID:  post.ID,
+			GroupID: f.groupID,
+			UserIds: []uuid.UUID{f.users[viewerCustom]},
+		})
+		if err != nil {
+			t.Fatalf("error adding custom audience: %v", err)
+		}
+	}
+
+	return post.ID
+}
+
+// checkDenied fails unless err hides the post from a viewer who can't read it
+func checkDenied(t *testing.T, viewer, kind string, err error) {
+	t.Helper()
+
+	if viewer == viewerOutsider {
+		if !errors.Is(err, ErrUserNotMember) {
+			t.Errorf("%s reading %s post: error = %v, want ErrUserNotMember", viewer, kind, err)
+		}
+		return
+	}
+	if !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("%s reading %s post: error = %v, want ErrPostNotFound", viewer, kind, err)
+	}
+}
+
+func postIDs(posts []Post) []uuid.UUID {
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return ids
+}
+
+func TestPostVisibilityLists(t *testing.T) {
+	f := newVisibilityFixture(t)
+	ctx := context.Background()
+	newerCursor := encodeCursor(time.Unix(1, 0), uuid.New(), true)
+
+	// Each path lists the posts a viewer can see, outsiders get an error
+	paths := []struct {
+		name  string
+		posts map[string]uuid.UUID
+		list  func(userID uuid.UUID) ([]uuid.UUID, error)
+	}{
+		{"feed", f.posts, func(userID uuid.UUID) ([]uuid.UUID, error) {
+			posts, err := f.cfg.getPostFeed(ctx, userID, f.groupID, 100, 0, postFeedFilter{})
+			return postIDs(posts), err
+		}},
+		{"feed first cursor page", f.posts, func(userID uuid.UUID) ([]uuid.UUID, error) {
+			page, err := f.cfg.getPostFeedPage(ctx, userID, f.groupID, 100, "", postFeedFilter{})
+			return postIDs(page.Posts), err
+		}},
+		{"feed newer cursor page", f.posts, func(userID uuid.UUID) ([]uuid.UUID, error) {
+			page, err := f.cfg.getPostFeedPage(ctx, userID, f.groupID, 100, newerCursor, postFeedFilter{})
+			return postIDs(page.Posts), err
+		}},
+		{"pinned", f.posts, func(userID uuid.UUID) ([]uuid.UUID, error) {
+			posts, err := f.cfg.getPinnedPosts(ctx, userID, f.groupID)
+			return postIDs(posts), err
+		}},
+		{"answered", f.answered, func(userID uuid.UUID) ([]uuid.UUID, error) {
+			posts, err := f.cfg.getAnsweredPosts(ctx, userID, f.groupID, 100, 0)
+			return postIDs(posts), err
+		}},
+	}
+
+	for _, path := range paths {
+		t.Run(path.name, func(t *testing.T) {
+			for _, viewer := range visibilityViewers {
+				ids, err := path.list(f.users[viewer])
+				if viewer == viewerOutsider {
+					if !errors.Is(err, ErrUserNotMember) {
+						t.Errorf("%s: error = %v, want ErrUserNotMember", viewer, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", viewer, err)
+				}
+
+				for _, kind := range visibilityKinds {
+					want := isListed(viewer, kind)
+					if got := slices.Contains(ids, path.posts[kind]); got != want {
+						t.Errorf("%s sees %s post = %v, want %v", viewer, kind, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPostVisibilityCounts(t *testing.T) {
+	f := newVisibilityFixture(t)
+	ctx := context.Background()
+
+	for _, viewer := range visibilityViewers {
+		t.Run(viewer, func(t *testing.T) {
+			counts, err := f.cfg.getGroupPostCount(ctx, f.users[viewer], f.groupID)
+			if viewer == viewerOutsider {
+				if !errors.Is(err, ErrUserNotMember) {
+					t.Errorf("error = %v, want ErrUserNotMember", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Open and answered requests of each kind are both counted
+			want := 0
+			for _, kind := range visibilityKinds {
+				if isListed(viewer, kind) {
+					want += 2
+				}
+			}
+			if counts.PostCount != want {
+				t.Errorf("post count = %d, want %d", counts.PostCount, want)
+			}
+			if got := counts.ByCategory["prayer_request"]; got != int64(want) {
+				t.Errorf("prayer_request count = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestPostVisibilityByID(t *testing.T) {
+	f := newVisibilityFixture(t)
+	ctx := context.Background()
+
+	// Each path reads one post, or a comment on it, and fails when hidden
+	paths := []struct {
+		name string
+		read func(userID uuid.UUID, kind string) error
+	}{
+		{"post", func(userID uuid.UUID, kind string) error {
+			post, err := f.cfg.getPostWithComments(ctx, userID, f.posts[kind], commentPage{})
+			if err == nil && (post.ID != f.posts[kind] || len(post.Comments) != 1) {
+				t.Errorf("post %s came back as %v with %d comments", kind, post.ID, len(post.Comments))
+			}
+			return err
+		}},
+		{"comment", func(userID uuid.UUID, kind string) error {
+			_, err := f.cfg.getPostWithComments(ctx, userID, f.comments[kind], commentPage{})
+			return err
+		}},
+		{"comment page", func(userID uuid.UUID, kind string) error {
+			page, err := f.cfg.getCommentPage(ctx, userID, f.posts[kind], defaultCommentPageSize, "")
+			if err == nil && (len(page.Comments) != 1 || page.Comments[0].ID != f.comments[kind]) {
+				t.Errorf("comments on %s post = %+v, want the one comment", kind, page.Comments)
+			}
+			return err
+		}},
+	}
+
+	for _, path := range paths {
+		t.Run(path.name, func(t *testing.T) {
+			for _, viewer := range visibilityViewers {
+				for _, kind := range visibilityKinds {
+					err := path.read(f.users[viewer], kind)
+					if !canRead(viewer, kind) {
+						checkDenied(t, viewer, kind, err)
+						continue
+					}
+					if err != nil {
+						t.Errorf("%s reading %s post: %v", viewer, kind, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPostVisibilityPrayerRoster(t *testing.T) {
+	f := newVisibilityFixture(t)
+	ctx := context.Background()
+
+	for _, viewer := range visibilityViewers {
+		for _, kind := range visibilityKinds {
+			roster, err := f.cfg.getPrayerRoster(ctx, f.users[viewer], f.groupID, f.posts[kind])
+			switch {
+			case !canRead(viewer, kind):
+				// Hidden posts mustn't be told apart from missing ones
+				checkDenied(t, viewer, kind, err)
+			case viewer != viewerAuthor:
+				if !errors.Is(err, ErrRosterAuthorOnly) {
+					t.Errorf("%s reading %s roster: error = %v, want ErrRosterAuthorOnly", viewer, kind, err)
+				}
+			case err != nil:
+				t.Errorf("%s reading %s roster: %v", viewer, kind, err)
+			case len(roster) != 1:
+				t.Errorf("%s roster has %d entries, want 1", kind, len(roster))
+			}
+		}
+	}
+}
+
+func TestPostVisibilityCommitments(t *testing.T) {
+	f := newVisibilityFixture(t)
+	ctx := context.Background()
+
+	for _, viewer := range visibilityViewers {
+		t.Run(viewer, func(t *testing.T) {
+			commitments, err := f.cfg.getMyCommitments(ctx, f.users[viewer])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []uuid.UUID
+			for _, commitment := range commitments {
+				ids = append(ids, commitment.PostID)
+			}
+			for _, kind := range visibilityKinds {
+				want := canRead(viewer, kind)
+				if got := slices.Contains(ids, f.posts[kind]); got != want {
+					t.Errorf("commitment to %s post listed = %v, want %v", kind, got, want)
+				}
+			}
+		})
+	}
+}
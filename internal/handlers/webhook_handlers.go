@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+// webhookErrorStatus maps webhook validation and lookup errors to a status
+// code, returning 0 for unexpected errors
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUserNotAdmin):
+		return http.StatusForbidden
+	case errors.Is(err, ErrWebhookNotFound), errors.Is(err, ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidWebhookURL), errors.Is(err, ErrInvalidWebhookEvent),
+		errors.Is(err, ErrNoWebhookEvents), errors.Is(err, ErrTooManyWebhooks):
+		return http.StatusBadRequest
+	}
+	return 0
+}
+
+func (a *APIConfig) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := ParseJSON[WebhookRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := a.createWebhook(r.Context(), userID, groupID, req)
+	if err != nil {
+		if status := webhookErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error creating webhook: %v", err)
+		http.Error(w, "Error creating webhook", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(hook, w, http.StatusCreated); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Webhook %v created in group %v by user %v", hook.ID, groupID, userID)
+}
+
+func (a *APIConfig) GetWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group ID from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	hooks, err := a.getWebhooks(r.Context(), userID, groupID)
+	if err != nil {
+		if status := webhookErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error retrieving webhooks: %v", err)
+		http.Error(w, "Error retrieving webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(hooks, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group and webhook IDs from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	webhookID, err := parseUUIDPathParam(r, "webhook_id")
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	req, err := ParseJSON[WebhookUpdateRequest](r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := a.updateWebhook(r.Context(), userID, groupID, webhookID, req)
+	if err != nil {
+		if status := webhookErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error updating webhook: %v", err)
+		http.Error(w, "Error updating webhook", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(hook, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group and webhook IDs from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	webhookID, err := parseUUIDPathParam(r, "webhook_id")
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.deleteWebhook(r.Context(), userID, groupID, webhookID); err != nil {
+		if status := webhookErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error deleting webhook: %v", err)
+		http.Error(w, "Error deleting webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Webhook %v deleted from group %v by user %v", webhookID, groupID, userID)
+}
+
+func (a *APIConfig) GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group and webhook IDs from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	webhookID, err := parseUUIDPathParam(r, "webhook_id")
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse query parameters for pagination
+	limit, err := parseIntQueryParam(r, "limit", 50)
+	if err != nil || limit < 1 || limit > 200 {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid offset parameter", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := a.getWebhookDeliveries(r.Context(), userID, groupID, webhookID, limit, offset)
+	if err != nil {
+		if status := webhookErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error retrieving webhook deliveries: %v", err)
+		http.Error(w, "Error retrieving webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(deliveries, w, http.StatusOK); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+}
+
+func (a *APIConfig) ReplayWebhookDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	// Validate JWT and extract user ID
+	userID, err := a.getUserIDFromToken(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the group, webhook and delivery IDs from the URL path
+	groupID, err := parseUUIDPathParam(r, "group_id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	webhookID, err := parseUUIDPathParam(r, "webhook_id")
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+	deliveryID, err := parseUUIDPathParam(r, "delivery_id")
+	if err != nil {
+		http.Error(w, "Invalid delivery ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := a.replayWebhookDelivery(r.Context(), userID, groupID, webhookID, deliveryID)
+	if err != nil {
+		if status := webhookErrorStatus(err); status != 0 {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error replaying webhook delivery: %v", err)
+		http.Error(w, "Error replaying webhook delivery", http.StatusInternalServerError)
+		return
+	}
+
+	if err := CreateJSONResponse(delivery, w, http.StatusAccepted); err != nil {
+		log.Printf("Error creating JSON response: %v", err)
+		return
+	}
+
+	log.Printf("Webhook delivery %v replayed as %v", deliveryID, delivery.ID)
+}
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/jobs"
+	"github.com/TheJa750/PrayerPals/internal/validation"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrInvalidWebhookURL   = errors.New("invalid webhook URL")
+	ErrDeliveryNotFound    = errors.New("webhook delivery not found")
+	ErrInvalidWebhookEvent = errors.New("unknown webhook event")
+	ErrNoWebhookEvents     = errors.New("webhook must subscribe to at least one event")
+	ErrTooManyWebhooks     = errors.New("group has reached the webhook limit")
+)
+
+const (
+	eventPostCreated     = "post.created"
+	eventCommentCreated  = "comment.created"
+	eventMemberJoined    = "member.joined"
+	eventMemberModerated = "member.moderated"
+	eventRequestAnswered = "request.answered"
+
+	maxWebhooksPerGroup = 10
+)
+
+var webhookEvents = []string{
+	eventPostCreated,
+	eventCommentCreated,
+	eventMemberJoined,
+	eventMemberModerated,
+	eventRequestAnswered,
+}
+
+// webhookEnvelope is the body POSTed to receivers
+type webhookEnvelope struct {
+	Event      string    `json:"event"`
+	GroupID    uuid.UUID `json:"group_id"`
+	OccurredAt string    `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// webhookMember is the data sent with member events
+type webhookMember struct {
+	UserID  uuid.UUID  `json:"user_id"`
+	Role    string     `json:"role,omitempty"`
+	Action  string     `json:"action,omitempty"` // Moderation action for member.moderated
+	Reason  string     `json:"reason,omitempty"`
+	ActorID *uuid.UUID `json:"actor_id,omitempty"` // Admin who moderated the member
+}
+
+func toJSONWebhook(hook database.Webhook) Webhook {
+	return Webhook{
+		ID:        hook.ID,
+		GroupID:   hook.GroupID,
+		URL:       hook.Url,
+		Events:    hook.Events,
+		IsActive:  hook.IsActive,
+		CreatedAt: formatNullTime(hook.CreatedAt),
+		UpdatedAt: formatNullTime(hook.UpdatedAt),
+	}
+}
+
+func toJSONWebhookDelivery(delivery database.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus.Int32,
+		ResponseBody:   delivery.ResponseBody,
+		LastError:      delivery.LastError,
+		CreatedAt:      formatNullTime(delivery.CreatedAt),
+		DeliveredAt:    formatNullTime(delivery.DeliveredAt),
+	}
+}
+
+func (a *APIConfig) validateWebhookURL(url string) error {
+	result := validation.ValidateWebhookURL(url, a.AllowLocalWebhooks)
+	if !result.IsValid {
+		return fmt.Errorf("%w: %s", ErrInvalidWebhookURL, strings.Join(result.Errors, ", "))
+	}
+	return nil
+}
+
+// normalizeWebhookEvents checks every event is known and drops duplicates
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, ErrNoWebhookEvents
+	}
+
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !slices.Contains(webhookEvents, event) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidWebhookEvent, event)
+		}
+		if !slices.Contains(normalized, event) {
+			normalized = append(normalized, event)
+		}
+	}
+
+	return normalized, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (a *APIConfig) createWebhook(ctx context.Context, userID, groupID uuid.UUID, req WebhookRequest) (Webhook, error) {
+	// Only admins can manage webhooks
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return Webhook{}, err
+	}
+
+	url := strings.TrimSpace(req.URL)
+	if err := a.validateWebhookURL(url); err != nil {
+		return Webhook{}, err
+	}
+
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	existing, err := a.DBQueries.GetWebhooks(ctx, groupID)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("error retrieving webhooks: %w", err)
+	}
+	if len(existing) >= maxWebhooksPerGroup {
+		return Webhook{}, ErrTooManyWebhooks
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return Webhook{}, fmt.Errorf("error generating webhook secret: %w", err)
+	}
+
+	hook, err := a.DBQueries.CreateWebhook(ctx, database.CreateWebhookParams{
+		GroupID:   groupID,
+		Url:       url,
+		Secret:    secret,
+		Events:    events,
+		CreatedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return Webhook{}, fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	// The secret is shown once so the receiver can verify signatures
+	jsonHook := toJSONWebhook(hook)
+	jsonHook.Secret = hook.Secret
+
+	return jsonHook, nil
+}
+
+func (a *APIConfig) getWebhooks(ctx context.Context, userID, groupID uuid.UUID) ([]Webhook, error) {
+	// Only admins can manage webhooks
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	hooks, err := a.DBQueries.GetWebhooks(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving webhooks: %w", err)
+	}
+
+	jsonHooks := make([]Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		jsonHooks = append(jsonHooks, toJSONWebhook(hook))
+	}
+
+	return jsonHooks, nil
+}
+
+func (a *APIConfig) updateWebhook(ctx context.Context, userID, groupID, webhookID uuid.UUID, req WebhookUpdateRequest) (Webhook, error) {
+	// Only admins can manage webhooks
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return Webhook{}, err
+	}
+
+	hook, err := a.DBQueries.GetWebhook(ctx, database.GetWebhookParams{
+		ID:      webhookID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Webhook{}, ErrWebhookNotFound
+		}
+		return Webhook{}, fmt.Errorf("error retrieving webhook: %w", err)
+	}
+
+	params := database.UpdateWebhookParams{
+		ID:       hook.ID,
+		GroupID:  hook.GroupID,
+		Url:      hook.Url,
+		Events:   hook.Events,
+		IsActive: hook.IsActive,
+	}
+
+	if req.URL != nil {
+		params.Url = strings.TrimSpace(*req.URL)
+		if err := a.validateWebhookURL(params.Url); err != nil {
+			return Webhook{}, err
+		}
+	}
+	if req.Events != nil {
+		params.Events, err = normalizeWebhookEvents(*req.Events)
+		if err != nil {
+			return Webhook{}, err
+		}
+	}
+	if req.IsActive != nil {
+		params.IsActive = *req.IsActive
+	}
+
+	updated, err := a.DBQueries.UpdateWebhook(ctx, params)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("error updating webhook: %w", err)
+	}
+
+	return toJSONWebhook(updated), nil
+}
+
+func (a *APIConfig) deleteWebhook(ctx context.Context, userID, groupID, webhookID uuid.UUID) error {
+	// Only admins can manage webhooks
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return err
+	}
+
+	deleted, err := a.DBQueries.DeleteWebhook(ctx, database.DeleteWebhookParams{
+		ID:      webhookID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+	if deleted == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (a *APIConfig) getWebhookDeliveries(ctx context.Context, userID, groupID, webhookID uuid.UUID, limit, offset int) ([]WebhookDelivery, error) {
+	// Only admins can manage webhooks
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	// Make sure the webhook belongs to this group
+	_, err := a.DBQueries.GetWebhook(ctx, database.GetWebhookParams{
+		ID:      webhookID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("error retrieving webhook: %w", err)
+	}
+
+	deliveries, err := a.DBQueries.GetWebhookDeliveries(ctx, database.GetWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving webhook deliveries: %w", err)
+	}
+
+	jsonDeliveries := make([]WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		jsonDeliveries = append(jsonDeliveries, toJSONWebhookDelivery(delivery))
+	}
+
+	return jsonDeliveries, nil
+}
+
+// replayWebhookDelivery sends the payload of an earlier delivery again as a
+// new delivery, so the original attempt history is kept
+func (a *APIConfig) replayWebhookDelivery(ctx context.Context, userID, groupID, webhookID, deliveryID uuid.UUID) (WebhookDelivery, error) {
+	// Only admins can manage webhooks
+	if err := a.isAdmin(ctx, userID, groupID); err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	_, err := a.DBQueries.GetWebhook(ctx, database.GetWebhookParams{
+		ID:      webhookID,
+		GroupID: groupID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return WebhookDelivery{}, ErrWebhookNotFound
+		}
+		return WebhookDelivery{}, fmt.Errorf("error retrieving webhook: %w", err)
+	}
+
+	original, err := a.DBQueries.GetWebhookDelivery(ctx, database.GetWebhookDeliveryParams{
+		ID:        deliveryID,
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return WebhookDelivery{}, ErrDeliveryNotFound
+		}
+		return WebhookDelivery{}, fmt.Errorf("error retrieving webhook delivery: %w", err)
+	}
+
+	tx, err := a.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+	defer tx.Rollback()
+	qtx := a.DBQueries.WithTx(tx)
+
+	delivery, err := queueWebhookDelivery(ctx, qtx, webhookID, original.Event, original.Payload)
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	return toJSONWebhookDelivery(delivery), nil
+}
+
+// queueWebhookDelivery records a delivery and queues the job that sends it,
+// q may be bound to a transaction
+func queueWebhookDelivery(ctx context.Context, q *database.Queries, webhookID uuid.UUID, event string, payload json.RawMessage) (database.WebhookDelivery, error) {
+	delivery, err := q.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		WebhookID: webhookID,
+		Event:     event,
+		Payload:   payload,
+	})
+	if err != nil {
+		return database.WebhookDelivery{}, fmt.Errorf("error creating webhook delivery: %w", err)
+	}
+
+	payloadJob := jobs.WebhookPayload{DeliveryID: delivery.ID}
+	if err := jobs.Enqueue(ctx, q, jobs.KindDeliverWebhook, payloadJob, time.Now()); err != nil {
+		return database.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// dispatchWebhookEvent queues a delivery for every active webhook in the
+// group subscribed to the event. Failures are logged rather than returned so
+// a broken webhook never blocks the action that triggered it.
+func (a *APIConfig) dispatchWebhookEvent(ctx context.Context, groupID uuid.UUID, event string, data any) {
+	hookIDs, err := a.DBQueries.GetWebhooksForEvent(ctx, database.GetWebhooksForEventParams{
+		GroupID: groupID,
+		Event:   event,
+	})
+	if err != nil {
+		log.Printf("Error retrieving webhooks for %s in group %v: %v", event, groupID, err)
+		return
+	}
+	if len(hookIDs) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(webhookEnvelope{
+		Event:      event,
+		GroupID:    groupID,
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+		Data:       data,
+	})
+	if err != nil {
+		log.Printf("Error encoding %s webhook payload: %v", event, err)
+		return
+	}
+
+	for _, hookID := range hookIDs {
+		if _, err := queueWebhookDelivery(ctx, a.DBQueries, hookID, event, payload); err != nil {
+			log.Printf("Error queueing %s webhook %v: %v", event, hookID, err)
+		}
+	}
+}
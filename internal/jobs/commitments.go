@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/mailer"
+	"github.com/google/uuid"
+)
+
+const (
+	KindQueuePrayerReminders = "queue_prayer_reminders"
+	KindSendPrayerReminder   = "send_prayer_reminder"
+
+	CommitmentDaily  = "daily"
+	CommitmentWeekly = "weekly"
+
+	reminderBatchSize     = 500
+	reminderExcerptLength = 280
+)
+
+// PrayerReminderPayload identifies the commitment to send a reminder for
+type PrayerReminderPayload struct {
+	CommitmentID uuid.UUID `json:"commitment_id"`
+}
+
+// CommitmentInterval returns the time between reminders for a frequency
+func CommitmentInterval(frequency string) (time.Duration, bool) {
+	switch frequency {
+	case CommitmentDaily:
+		return 24 * time.Hour, true
+	case CommitmentWeekly:
+		return 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// RegisterCommitments adds the jobs that remind members of their prayer
+// commitments
+func (r *Runner) RegisterCommitments(m mailer.Mailer) error {
+	r.Register(KindQueuePrayerReminders, r.queuePrayerReminders)
+	r.Register(KindSendPrayerReminder, func(ctx context.Context, payload json.RawMessage) error {
+		return r.sendPrayerReminder(ctx, m, payload)
+	})
+
+	return r.Schedule("queue-prayer-reminders", "*/15 * * * *", KindQueuePrayerReminders)
+}
+
+// queuePrayerReminders ends finished commitments, then queues one reminder
+// job per due commitment and moves it to its next reminder time
+func (r *Runner) queuePrayerReminders(ctx context.Context, _ json.RawMessage) error {
+	ended, err := r.queries.EndFinishedCommitments(ctx)
+	if err != nil {
+		return err
+	}
+	if ended > 0 {
+		log.Printf("Ended %d finished prayer commitments", ended)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := r.queries.WithTx(tx)
+
+	due, err := qtx.ClaimDueCommitments(ctx, reminderBatchSize)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, commitment := range due {
+		interval, ok := CommitmentInterval(commitment.Frequency)
+		if !ok {
+			log.Printf("Skipping commitment %v with unknown frequency %q", commitment.ID, commitment.Frequency)
+			continue
+		}
+
+		payload := PrayerReminderPayload{CommitmentID: commitment.ID}
+		if err := Enqueue(ctx, qtx, KindSendPrayerReminder, payload, now); err != nil {
+			return err
+		}
+
+		// Skip reminders missed while the server was down rather than sending a burst
+		next := commitment.NextReminderAt.Add(interval)
+		for !next.After(now) {
+			next = next.Add(interval)
+		}
+		err := qtx.AdvanceCommitmentReminder(ctx, database.AdvanceCommitmentReminderParams{
+			ID:             commitment.ID,
+			NextReminderAt: next,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if len(due) > 0 {
+		log.Printf("Queued %d prayer reminders", len(due))
+	}
+	return nil
+}
+
+func (r *Runner) sendPrayerReminder(ctx context.Context, m mailer.Mailer, payload json.RawMessage) error {
+	var p PrayerReminderPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("send prayer reminder: error decoding payload: %w", err)
+	}
+
+	reminder, err := r.queries.GetCommitmentReminder(ctx, p.CommitmentID)
+	if err != nil {
+		// The request or member was purged since the reminder was queued
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	// Ended between being queued and sent, or the member lost access to the
+	// request before the next sweep caught it
+	if reminder.EndedAt.Valid || !reminder.EndsAt.After(time.Now()) || !reminder.CanView {
+		return nil
+	}
+
+	author := "a member"
+	if reminder.AuthorUsername != "" && !reminder.IsAnonymous {
+		author = reminder.AuthorUsername
+	}
+	excerpt := reminder.Content
+	if runes := []rune(excerpt); len(runes) > reminderExcerptLength {
+		excerpt = string(runes[:reminderExcerptLength]) + "..."
+	}
+
+	subject := fmt.Sprintf("Reminder to pray for %s's request in %s", author, reminder.GroupName)
+	body := fmt.Sprintf("Hello %s,\n\nYou committed to pray %s for this request from %s in %q:\n\n%s\n\n"+
+		"Your commitment runs until %s. You can end it at any time from the request on Prayer Pals.\n",
+		reminder.Username, reminder.Frequency, author, reminder.GroupName, excerpt,
+		reminder.EndsAt.Format("January 2, 2006"))
+
+	if err := m.Send(ctx, reminder.Email, subject, body); err != nil {
+		return err
+	}
+
+	log.Printf("Sent prayer reminder for commitment %v", reminder.ID)
+	return nil
+}
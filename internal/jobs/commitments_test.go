@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+// recordingMailer keeps the recipients of every message instead of sending
+type recordingMailer struct {
+	sent []string
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, _, _ string) error {
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+func TestPrayerReminderSkipsBannedMember(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	r := NewRunner(db)
+	q := r.queries
+
+	var author, member uuid.UUID
+	for _, user := range []struct {
+		id   *uuid.UUID
+		name string
+	}{{&author, "author"}, {&member, "member"}} {
+		created, err := q.CreateUser(ctx, database.CreateUserParams{
+			Username:       user.name,
+			Email:          user.name + "@example.com",
+			HashedPassword: "x",
+		})
+		if err != nil {
+			t.Fatalf("error creating %s: %v", user.name, err)
+		}
+		*user.id = created.ID
+	}
+
+	group, err := q.CreateGroup(ctx, database.CreateGroupParams{
+		Name:       "Reminders",
+		OwnerID:    uuid.NullUUID{UUID: author, Valid: true},
+		InviteCode: "REMIND01",
+	})
+	if err != nil {
+		t.Fatalf("error creating group: %v", err)
+	}
+	for userID, role := range map[uuid.UUID]string{author: "admin", member: "member"} {
+		err := q.AddUserToGroup(ctx, database.AddUserToGroupParams{
+			UserID:  userID,
+			GroupID: group.ID,
+			Role:    role,
+		})
+		if err != nil {
+			t.Fatalf("error adding member: %v", err)
+		}
+	}
+
+	post, err := q.CreatePost(ctx, database.CreatePostParams{
+		UserID:   author,
+		GroupID:  group.ID,
+		Content:  "Please pray for my family",
+		Category: "prayer_request",
+		Audience: "members",
+	})
+	if err != nil {
+		t.Fatalf("error creating post: %v", err)
+	}
+
+	commitment, err := q.UpsertPrayerCommitment(ctx, database.UpsertPrayerCommitmentParams{
+		PostID:         post.ID,
+		UserID:         member,
+		Frequency:      CommitmentDaily,
+		EndsAt:         time.Now().Add(7 * 24 * time.Hour),
+		NextReminderAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("error creating commitment: %v", err)
+	}
+	payload, err := json.Marshal(PrayerReminderPayload{CommitmentID: commitment.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &recordingMailer{}
+	if err := r.sendPrayerReminder(ctx, m, payload); err != nil {
+		t.Fatalf("send before ban: %v", err)
+	}
+	if len(m.sent) != 1 {
+		t.Fatalf("sent %d reminders before ban, want 1", len(m.sent))
+	}
+
+	err = q.BanUser(ctx, database.BanUserParams{
+		UserID:   member,
+		GroupID:  group.ID,
+		BannedBy: uuid.NullUUID{UUID: author, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("error banning member: %v", err)
+	}
+
+	// A reminder queued before the ban is dropped at send time
+	if err := r.sendPrayerReminder(ctx, m, payload); err != nil {
+		t.Fatalf("send after ban: %v", err)
+	}
+	if len(m.sent) != 1 {
+		t.Errorf("sent %d reminders after ban, want none", len(m.sent)-1)
+	}
+
+	// The sweep ends the commitment so no more are queued
+	if err := r.queuePrayerReminders(ctx, nil); err != nil {
+		t.Fatalf("queue reminders: %v", err)
+	}
+
+	var ended bool
+	var reason string
+	err = db.QueryRow(
+		"SELECT ended_at IS NOT NULL, end_reason FROM prayer_commitments WHERE id = $1", commitment.ID,
+	).Scan(&ended, &reason)
+	if err != nil {
+		t.Fatalf("error reading commitment: %v", err)
+	}
+	if !ended || reason != "removed" {
+		t.Errorf("commitment ended = %v with reason %q, want ended with reason removed", ended, reason)
+	}
+
+	var queued int
+	err = db.QueryRow("SELECT COUNT(*) FROM jobs WHERE kind = $1", KindSendPrayerReminder).Scan(&queued)
+	if err != nil {
+		t.Fatalf("error counting reminder jobs: %v", err)
+	}
+	if queued != 0 {
+		t.Errorf("queued %d reminders for a banned member, want none", queued)
+	}
+}
@@ -0,0 +1,181 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCronSpec = errors.New("invalid cron spec")
+
+// Schedule computes the next time a recurring job should run
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// everySchedule runs at a fixed interval, e.g. "@every 10m"
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval).Truncate(time.Second)
+}
+
+// cronSchedule is a standard 5 field cron expression (minute hour day month weekday)
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var (
+	minuteField  = cronField{0, 59}
+	hourField    = cronField{0, 23}
+	domField     = cronField{1, 31}
+	monthField   = cronField{1, 12}
+	weekdayField = cronField{0, 7}
+)
+
+var cronDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	// Interval schedules
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCronSpec, spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+
+	if expanded, ok := cronDescriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q must have 5 fields", ErrInvalidCronSpec, spec)
+	}
+
+	var s cronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[4], weekdayField); err != nil {
+		return nil, err
+	}
+	// Accept 7 as an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+
+	return s, nil
+}
+
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%w: bad step in %q", ErrInvalidCronSpec, part)
+			}
+			step = n
+		}
+
+		start, end := bounds.min, bounds.max
+		if rangePart != "*" {
+			lo, hi, isRange := strings.Cut(rangePart, "-")
+			n, err := strconv.Atoi(lo)
+			if err != nil {
+				return 0, fmt.Errorf("%w: bad value in %q", ErrInvalidCronSpec, part)
+			}
+			start, end = n, n
+			if isRange {
+				if end, err = strconv.Atoi(hi); err != nil {
+					return 0, fmt.Errorf("%w: bad range in %q", ErrInvalidCronSpec, part)
+				}
+			} else if hasStep {
+				end = bounds.max // "5/15" means every 15 starting at 5
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, fmt.Errorf("%w: %q out of range", ErrInvalidCronSpec, part)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	// When both day fields are restricted, either one matching is enough
+	if !s.domStar && !s.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+func (s cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+
+	// Give up after 5 years, which only happens for specs like "0 0 31 2 *"
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseScheduleInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"10-5 * * * *",
+		"5-x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@yearly",
+		"@every nope",
+		"@every 500ms",
+	}
+
+	for _, spec := range specs {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := ParseSchedule(spec); !errors.Is(err, ErrInvalidCronSpec) {
+				t.Errorf("ParseSchedule(%q) error = %v, want ErrInvalidCronSpec", spec, err)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04:05", value)
+		if err != nil {
+			t.Fatalf("bad test time %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	// 2024-01-01 was a Monday
+	tests := []struct {
+		name  string
+		spec  string
+		after string
+		want  string
+	}{
+		{"every minute", "* * * * *", "2024-01-01 10:00:30", "2024-01-01 10:01:00"},
+		{"never the same minute", "* * * * *", "2024-01-01 10:00:00", "2024-01-01 10:01:00"},
+		{"step", "*/15 * * * *", "2024-01-01 10:07:00", "2024-01-01 10:15:00"},
+		{"step wraps the hour", "*/15 * * * *", "2024-01-01 10:45:00", "2024-01-01 11:00:00"},
+		{"hourly", "0 * * * *", "2024-01-01 10:00:30", "2024-01-01 11:00:00"},
+		{"minute past the hour", "10 * * * *", "2024-01-01 10:05:00", "2024-01-01 10:10:00"},
+		{"daily time tomorrow", "30 2 * * *", "2024-01-01 03:00:00", "2024-01-02 02:30:00"},
+		{"weekday", "0 0 * * 1", "2024-01-07 12:00:00", "2024-01-08 00:00:00"},
+		{"sunday as 7", "0 0 * * 7", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"sunday as 0", "0 0 * * 0", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"first of month", "0 0 1 * *", "2024-01-15 00:00:00", "2024-02-01 00:00:00"},
+		{"month", "0 0 1 6 *", "2024-01-15 00:00:00", "2024-06-01 00:00:00"},
+		{"year rollover", "0 0 1 1 *", "2024-06-01 00:00:00", "2025-01-01 00:00:00"},
+		{"day or weekday", "0 0 13 * 5", "2024-01-01 00:00:00", "2024-01-05 00:00:00"},
+		{"day or weekday by day", "0 0 2 * 5", "2024-01-01 00:00:00", "2024-01-02 00:00:00"},
+		{"leap day", "0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"range with step", "5-10/2 * * * *", "2024-01-01 10:06:00", "2024-01-01 10:07:00"},
+		{"start with step", "5/20 * * * *", "2024-01-01 10:30:00", "2024-01-01 10:45:00"},
+		{"list and ranges", "0,30 9-17 * * 1-5", "2024-01-05 17:30:00", "2024-01-08 09:00:00"},
+		{"daily descriptor", "@daily", "2024-01-01 10:00:00", "2024-01-02 00:00:00"},
+		{"weekly descriptor", "@weekly", "2024-01-01 10:00:00", "2024-01-07 00:00:00"},
+		{"monthly descriptor", "@monthly", "2024-01-01 10:00:00", "2024-02-01 00:00:00"},
+		{"hourly descriptor", "@hourly", "2024-01-01 10:59:59", "2024-01-01 11:00:00"},
+		{"interval", "@every 10m", "2024-01-01 10:00:30", "2024-01-01 10:10:30"},
+		{"padded spec", "  */30 * * * *  ", "2024-01-01 10:00:00", "2024-01-01 10:30:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error: %v", tt.spec, err)
+			}
+			got := schedule.Next(at(tt.after))
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) for %q = %s, want %s", tt.after, tt.spec, got, want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextNever(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("ParseSchedule error: %v", err)
+	}
+	if next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("Next() = %s, want the zero time", next)
+	}
+
+	// Schedule refuses specs that never run
+	r := &Runner{}
+	if err := r.Schedule("never", "0 0 31 2 *", "noop"); !errors.Is(err, ErrInvalidCronSpec) {
+		t.Errorf("Schedule() error = %v, want ErrInvalidCronSpec", err)
+	}
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/TheJa750/PrayerPals/internal/mailer"
+	"github.com/google/uuid"
+)
+
+const KindSendInvitation = "send_invitation"
+
+// InvitationPayload identifies the group invitation to email
+type InvitationPayload struct {
+	InvitationID uuid.UUID `json:"invitation_id"`
+}
+
+// RegisterInvitations adds the job that emails group invitations
+func (r *Runner) RegisterInvitations(m mailer.Mailer) {
+	r.Register(KindSendInvitation, func(ctx context.Context, payload json.RawMessage) error {
+		return r.sendInvitation(ctx, m, payload)
+	})
+}
+
+func (r *Runner) sendInvitation(ctx context.Context, m mailer.Mailer, payload json.RawMessage) error {
+	var p InvitationPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("send invitation: error decoding payload: %w", err)
+	}
+
+	invitation, err := r.queries.GetInvitationDetails(ctx, p.InvitationID)
+	if err != nil {
+		// The group was purged since the invitation was queued
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	// Already registered and added, nothing to send
+	if invitation.AcceptedAt.Valid {
+		return nil
+	}
+
+	greeting := "Hello"
+	if invitation.Name != "" {
+		greeting = "Hello " + invitation.Name
+	}
+	inviter := "A group admin"
+	if invitation.InvitedByUsername != "" {
+		inviter = invitation.InvitedByUsername
+	}
+
+	subject := fmt.Sprintf("You're invited to %s on Prayer Pals", invitation.GroupName)
+	body := fmt.Sprintf("%s,\n\n%s has invited you to join the prayer group %q on Prayer Pals.\n\n"+
+		"Sign in or create an account, then accept the invitation with this token:\n\n%s\n\n"+
+		"You can also join with the invite code %s.\n",
+		greeting, inviter, invitation.GroupName, invitation.Token, invitation.InviteCode)
+
+	if err := m.Send(ctx, invitation.Email, subject, body); err != nil {
+		return err
+	}
+
+	log.Printf("Sent invitation %v to %s", invitation.ID, invitation.Email)
+	return nil
+}
@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+const (
+	KindExpireModerations  = "expire_moderations"
+	KindPurgeExpiredTokens = "purge_expired_tokens"
+	KindPurgeDeletedPosts  = "purge_deleted_posts"
+	KindPurgeFinishedJobs  = "purge_finished_jobs"
+	KindPurgeDeletedGroups = "purge_deleted_groups"
+	KindExpirePending      = "expire_pending_actions"
+
+	DefaultPostRetention = 90 * 24 * time.Hour
+	finishedJobRetention = 7 * 24 * time.Hour
+
+	// DeletedGroupRetention is how long an owner can restore a deleted group
+	DeletedGroupRetention = 30 * 24 * time.Hour
+)
+
+// RegisterMaintenance adds the periodic cleanup jobs to the runner
+func (r *Runner) RegisterMaintenance(postRetention time.Duration) error {
+	r.Register(KindExpireModerations, r.expireModerations)
+	r.Register(KindPurgeExpiredTokens, r.purgeExpiredTokens)
+	r.Register(KindPurgeDeletedPosts, func(ctx context.Context, _ json.RawMessage) error {
+		return r.purgeDeletedPosts(ctx, postRetention)
+	})
+	r.Register(KindPurgeFinishedJobs, r.purgeFinishedJobs)
+	r.Register(KindPurgeDeletedGroups, r.purgeDeletedGroups)
+	r.Register(KindExpirePending, r.expirePendingActions)
+
+	schedules := []struct {
+		name, spec, kind string
+	}{
+		{"expire-moderations", "*/5 * * * *", KindExpireModerations},
+		{"purge-expired-tokens", "0 3 * * *", KindPurgeExpiredTokens},
+		{"purge-deleted-posts", "30 3 * * *", KindPurgeDeletedPosts},
+		{"purge-finished-jobs", "0 4 * * *", KindPurgeFinishedJobs},
+		{"purge-deleted-groups", "15 4 * * *", KindPurgeDeletedGroups},
+		{"expire-pending-actions", "*/15 * * * *", KindExpirePending},
+	}
+	for _, s := range schedules {
+		if err := r.Schedule(s.name, s.spec, s.kind); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) expireModerations(ctx context.Context, _ json.RawMessage) error {
+	kicks, err := r.queries.ExpireKicks(ctx)
+	if err != nil {
+		return err
+	}
+
+	mutes, err := r.queries.ExpireMutes(ctx)
+	if err != nil {
+		return err
+	}
+
+	pins, err := r.queries.ExpirePins(ctx)
+	if err != nil {
+		return err
+	}
+
+	if kicks > 0 || mutes > 0 || pins > 0 {
+		log.Printf("Expired %d kicks, %d mutes and %d pins", kicks, mutes, pins)
+	}
+	return nil
+}
+
+func (r *Runner) expirePendingActions(ctx context.Context, _ json.RawMessage) error {
+	count, err := r.queries.ExpirePendingActions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		log.Printf("Expired %d unapproved pending actions", count)
+	}
+	return nil
+}
+
+func (r *Runner) purgeExpiredTokens(ctx context.Context, _ json.RawMessage) error {
+	count, err := r.queries.DeleteExpiredTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Purged %d expired or revoked refresh tokens", count)
+	return nil
+}
+
+func (r *Runner) purgeDeletedPosts(ctx context.Context, retention time.Duration) error {
+	count, err := r.queries.PurgeDeletedPosts(ctx, sql.NullTime{
+		Time:  time.Now().Add(-retention),
+		Valid: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Purged %d posts deleted more than %v ago", count, retention)
+	return nil
+}
+
+func (r *Runner) purgeFinishedJobs(ctx context.Context, _ json.RawMessage) error {
+	count, err := r.queries.DeleteFinishedJobs(ctx, sql.NullTime{
+		Time:  time.Now().Add(-finishedJobRetention),
+		Valid: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Purged %d finished jobs", count)
+	return nil
+}
+
+// purgeDeletedGroups permanently removes groups past their restore window,
+// cascading to memberships, posts and comments
+func (r *Runner) purgeDeletedGroups(ctx context.Context, _ json.RawMessage) error {
+	count, err := r.queries.PurgeDeletedGroups(ctx, sql.NullTime{
+		Time:  time.Now().Add(-DeletedGroupRetention),
+		Valid: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Purged %d groups deleted more than %v ago", count, DeletedGroupRetention)
+	return nil
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+)
+
+const (
+	KindPublishScheduledPosts = "publish_scheduled_posts"
+	KindArchiveStalePosts     = "archive_stale_posts"
+)
+
+// PublishedFunc is told about scheduled posts once they appear in the feed
+type PublishedFunc func(ctx context.Context, posts []database.Post)
+
+// RegisterPublishing adds the jobs that publish scheduled posts and archive
+// posts in groups with auto-archive turned on
+func (r *Runner) RegisterPublishing(onPublished PublishedFunc) error {
+	r.Register(KindPublishScheduledPosts, func(ctx context.Context, _ json.RawMessage) error {
+		return r.publishScheduledPosts(ctx, onPublished)
+	})
+	r.Register(KindArchiveStalePosts, r.archiveStalePosts)
+
+	if err := r.Schedule("publish-scheduled-posts", "* * * * *", KindPublishScheduledPosts); err != nil {
+		return err
+	}
+	return r.Schedule("archive-stale-posts", "10 * * * *", KindArchiveStalePosts)
+}
+
+func (r *Runner) publishScheduledPosts(ctx context.Context, onPublished PublishedFunc) error {
+	posts, err := r.queries.PublishDuePosts(ctx)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		return nil
+	}
+
+	log.Printf("Published %d scheduled posts", len(posts))
+	if onPublished != nil {
+		onPublished(ctx, posts)
+	}
+	return nil
+}
+
+func (r *Runner) archiveStalePosts(ctx context.Context, _ json.RawMessage) error {
+	count, err := r.queries.ArchiveStalePosts(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		log.Printf("Archived %d posts without recent activity", count)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+)
+
+var ErrUnknownJobKind = errors.New("no handler registered for job kind")
+
+const (
+	defaultMaxAttempts = 5
+	pollInterval       = 5 * time.Second
+	claimBatchSize     = 10
+	jobTimeout         = 5 * time.Minute
+	staleLockTimeout   = 2 * jobTimeout
+	baseRetryDelay     = 30 * time.Second
+	maxRetryDelay      = time.Hour
+)
+
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+type scheduledJob struct {
+	name     string
+	kind     string
+	spec     string
+	schedule Schedule
+}
+
+// Runner claims jobs from the jobs table and runs them in-process.
+// Claims use SKIP LOCKED so several server replicas can share one queue.
+type Runner struct {
+	db        *sql.DB
+	queries   *database.Queries
+	workerID  string
+	handlers  map[string]HandlerFunc
+	schedules []scheduledJob
+	stopped   chan struct{} // Closed once the poll loop has exited
+}
+
+func NewRunner(db *sql.DB) *Runner {
+	hostname, _ := os.Hostname()
+
+	return &Runner{
+		db:       db,
+		queries:  database.New(db),
+		workerID: fmt.Sprintf("%s-%d-%04d", hostname, os.Getpid(), rand.Intn(10000)),
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+func (r *Runner) Register(kind string, handler HandlerFunc) {
+	r.handlers[kind] = handler
+}
+
+// Schedule enqueues a job of the given kind whenever the cron spec is due.
+// Must be called before Start.
+func (r *Runner) Schedule(name, spec, kind string) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("%w: %q never runs", ErrInvalidCronSpec, spec)
+	}
+
+	r.schedules = append(r.schedules, scheduledJob{
+		name:     name,
+		kind:     kind,
+		spec:     spec,
+		schedule: schedule,
+	})
+	return nil
+}
+
+// Enqueue adds a job to the queue. Passing a transaction-bound Queries
+// makes the job part of the caller's transaction.
+func Enqueue(ctx context.Context, queries *database.Queries, kind string, payload any, runAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("enqueue %s: error encoding payload: %w", kind, err)
+	}
+
+	_, err = queries.EnqueueJob(ctx, database.EnqueueJobParams{
+		Kind:        kind,
+		Payload:     data,
+		RunAt:       runAt,
+		MaxAttempts: defaultMaxAttempts,
+	})
+	if err != nil {
+		return fmt.Errorf("enqueue %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+func (r *Runner) Enqueue(ctx context.Context, kind string, payload any, runAt time.Time) error {
+	return Enqueue(ctx, r.queries, kind, payload, runAt)
+}
+
+// Start registers schedules and polls for work until ctx is cancelled
+func (r *Runner) Start(ctx context.Context) error {
+	for _, s := range r.schedules {
+		err := r.queries.UpsertJobSchedule(ctx, database.UpsertJobScheduleParams{
+			Name:      s.name,
+			Kind:      s.kind,
+			CronSpec:  s.spec,
+			NextRunAt: s.schedule.Next(time.Now()),
+		})
+		if err != nil {
+			return fmt.Errorf("start: error registering schedule %s: %w", s.name, err)
+		}
+	}
+
+	log.Printf("Job runner %s started with %d schedules", r.workerID, len(r.schedules))
+
+	r.stopped = make(chan struct{})
+	go func() {
+		defer close(r.stopped)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			r.poll(ctx)
+
+			select {
+			case <-ctx.Done():
+				log.Printf("Job runner %s stopped", r.workerID)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Wait blocks until the runner has stopped after its context was cancelled,
+// including any jobs it was running at the time
+func (r *Runner) Wait() {
+	if r.stopped != nil {
+		<-r.stopped
+	}
+}
+
+func (r *Runner) poll(ctx context.Context) {
+	// Release jobs held by replicas that died mid-run
+	requeued, err := r.queries.RequeueStaleJobs(ctx, sql.NullTime{
+		Time:  time.Now().Add(-staleLockTimeout),
+		Valid: true,
+	})
+	if err != nil {
+		log.Printf("Error requeueing stale jobs: %v", err)
+	} else if requeued > 0 {
+		log.Printf("Requeued %d stale jobs", requeued)
+	}
+
+	if err := r.enqueueDueSchedules(ctx); err != nil {
+		log.Printf("Error enqueueing scheduled jobs: %v", err)
+	}
+
+	jobs, err := r.queries.ClaimJobs(ctx, database.ClaimJobsParams{
+		LockedBy: r.workerID,
+		Limit:    claimBatchSize,
+	})
+	if err != nil {
+		log.Printf("Error claiming jobs: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job database.Job) {
+			defer wg.Done()
+			r.runJob(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (r *Runner) enqueueDueSchedules(ctx context.Context) error {
+	if len(r.schedules) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := r.queries.WithTx(tx)
+
+	// Rows stay locked until commit, so only one replica enqueues each run
+	due, err := qtx.ClaimDueSchedules(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range due {
+		schedule, err := ParseSchedule(row.CronSpec)
+		if err != nil {
+			log.Printf("Skipping schedule %s: %v", row.Name, err)
+			continue
+		}
+
+		if err := Enqueue(ctx, qtx, row.Kind, struct{}{}, time.Now()); err != nil {
+			return err
+		}
+
+		err = qtx.AdvanceJobSchedule(ctx, database.AdvanceJobScheduleParams{
+			Name:      row.Name,
+			NextRunAt: schedule.Next(time.Now()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Runner) runJob(ctx context.Context, job database.Job) {
+	jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+
+	handler, ok := r.handlers[job.Kind]
+	var err error
+	if !ok {
+		err = fmt.Errorf("%w: %s", ErrUnknownJobKind, job.Kind)
+	} else {
+		err = runHandler(jobCtx, handler, job.Payload)
+	}
+
+	// Record the outcome even when shutdown cancelled the job, so it is
+	// retried instead of waiting out the stale lock timeout
+	ctx = context.WithoutCancel(ctx)
+
+	if err == nil {
+		if err := r.queries.CompleteJob(ctx, job.ID); err != nil {
+			log.Printf("Error completing job %v (%s): %v", job.ID, job.Kind, err)
+		}
+		return
+	}
+
+	// Out of attempts, leave the job in the table for inspection
+	if job.Attempts >= job.MaxAttempts {
+		log.Printf("Job %v (%s) failed permanently after %d attempts: %v", job.ID, job.Kind, job.Attempts, err)
+		if err := r.queries.FailJob(ctx, database.FailJobParams{
+			ID:        job.ID,
+			LastError: err.Error(),
+		}); err != nil {
+			log.Printf("Error marking job %v failed: %v", job.ID, err)
+		}
+		return
+	}
+
+	delay := retryDelay(job.Attempts)
+	log.Printf("Job %v (%s) failed on attempt %d, retrying in %v: %v", job.ID, job.Kind, job.Attempts, delay, err)
+	if err := r.queries.RetryJob(ctx, database.RetryJobParams{
+		ID:        job.ID,
+		RunAt:     time.Now().Add(delay),
+		LastError: err.Error(),
+	}); err != nil {
+		log.Printf("Error rescheduling job %v: %v", job.ID, err)
+	}
+}
+
+// runHandler turns a panicking handler into a failed attempt
+func runHandler(ctx context.Context, handler HandlerFunc, payload json.RawMessage) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+// retryDelay doubles from 30s per attempt, capped at an hour, with up to 20% jitter
+func retryDelay(attempts int32) time.Duration {
+	delay := baseRetryDelay
+	for i := int32(1); i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(delay / 5)))
+	return delay + jitter
+}
@@ -0,0 +1,247 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/testdb"
+	"github.com/google/uuid"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		base     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			delay := retryDelay(tt.attempts)
+			if delay < tt.base || delay >= tt.base+tt.base/5 {
+				t.Errorf("retryDelay(%d) = %v, want [%v, %v)", tt.attempts, delay, tt.base, tt.base+tt.base/5)
+			}
+		}
+	}
+}
+
+type jobRow struct {
+	status    string
+	attempts  int32
+	lastError string
+	runAt     time.Time
+}
+
+func getJob(t *testing.T, db *sql.DB, id uuid.UUID) jobRow {
+	t.Helper()
+
+	var row jobRow
+	err := db.QueryRow(
+		"SELECT status, attempts, last_error, run_at FROM jobs WHERE id = $1", id,
+	).Scan(&row.status, &row.attempts, &row.lastError, &row.runAt)
+	if err != nil {
+		t.Fatalf("error reading job %v: %v", id, err)
+	}
+	return row
+}
+
+func enqueueTestJob(t *testing.T, r *Runner, kind string, runAt time.Time, maxAttempts int32) uuid.UUID {
+	t.Helper()
+
+	job, err := r.queries.EnqueueJob(context.Background(), database.EnqueueJobParams{
+		Kind:        kind,
+		Payload:     json.RawMessage(`{}`),
+		RunAt:       runAt,
+		MaxAttempts: maxAttempts,
+	})
+	if err != nil {
+		t.Fatalf("error enqueueing job: %v", err)
+	}
+	return job.ID
+}
+
+func TestRunnerOutcomes(t *testing.T) {
+	db := testdb.Open(t)
+	due := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		handler     HandlerFunc // nil leaves the kind unregistered
+		maxAttempts int32
+		status      string
+		lastError   string
+		retried     bool
+	}{
+		{
+			name:        "success",
+			handler:     func(context.Context, json.RawMessage) error { return nil },
+			maxAttempts: 5,
+			status:      "done",
+		},
+		{
+			name:        "failure is retried",
+			handler:     func(context.Context, json.RawMessage) error { return errors.New("boom") },
+			maxAttempts: 5,
+			status:      "pending",
+			lastError:   "boom",
+			retried:     true,
+		},
+		{
+			name:        "last attempt fails permanently",
+			handler:     func(context.Context, json.RawMessage) error { return errors.New("boom") },
+			maxAttempts: 1,
+			status:      "failed",
+			lastError:   "boom",
+		},
+		{
+			name:        "panic counts as a failure",
+			handler:     func(context.Context, json.RawMessage) error { panic("oops") },
+			maxAttempts: 5,
+			status:      "pending",
+			lastError:   "job panicked: oops",
+			retried:     true,
+		},
+		{
+			name:        "unknown kind",
+			maxAttempts: 5,
+			status:      "pending",
+			lastError:   ErrUnknownJobKind.Error(),
+			retried:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(db)
+			kind := "test_" + strings.ReplaceAll(tt.name, " ", "_")
+			if tt.handler != nil {
+				r.Register(kind, tt.handler)
+			}
+			id := enqueueTestJob(t, r, kind, due, tt.maxAttempts)
+
+			r.poll(context.Background())
+
+			job := getJob(t, db, id)
+			if job.status != tt.status {
+				t.Errorf("status = %q, want %q", job.status, tt.status)
+			}
+			if job.attempts != 1 {
+				t.Errorf("attempts = %d, want 1", job.attempts)
+			}
+			if !strings.Contains(job.lastError, tt.lastError) {
+				t.Errorf("last_error = %q, want it to contain %q", job.lastError, tt.lastError)
+			}
+			if tt.retried && !job.runAt.After(time.Now().Add(baseRetryDelay/2)) {
+				t.Errorf("run_at = %v, want it pushed back by the retry delay", job.runAt)
+			}
+		})
+	}
+}
+
+func TestRunnerRecordsCancelledJobs(t *testing.T) {
+	db := testdb.Open(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Shutting down mid-job should leave it queued for a retry, not running
+	r := NewRunner(db)
+	r.Register("test_cancelled", func(jobCtx context.Context, _ json.RawMessage) error {
+		cancel()
+		<-jobCtx.Done()
+		return jobCtx.Err()
+	})
+	id := enqueueTestJob(t, r, "test_cancelled", time.Now().Add(-time.Minute), 5)
+
+	r.poll(ctx)
+
+	job := getJob(t, db, id)
+	if job.status != "pending" || job.attempts != 1 {
+		t.Errorf("job = %+v, want pending after 1 attempt", job)
+	}
+}
+
+func TestClaimJobs(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	r := NewRunner(db)
+
+	for range 3 {
+		enqueueTestJob(t, r, "test_claim", time.Now().Add(-time.Minute), 5)
+	}
+	future := enqueueTestJob(t, r, "test_claim", time.Now().Add(time.Hour), 5)
+
+	// Hold the first claim open, a second worker must skip those rows
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	first, err := r.queries.WithTx(tx).ClaimJobs(ctx, database.ClaimJobsParams{LockedBy: "worker-a", Limit: 2})
+	if err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	second, err := r.queries.ClaimJobs(ctx, database.ClaimJobsParams{LockedBy: "worker-b", Limit: 10})
+	if err != nil {
+		t.Fatalf("second claim: %v", err)
+	}
+
+	if len(first) != 2 || len(second) != 1 {
+		t.Fatalf("claimed %d and %d jobs, want 2 and 1", len(first), len(second))
+	}
+	claimed := map[uuid.UUID]bool{}
+	for _, job := range append(first, second...) {
+		if claimed[job.ID] {
+			t.Errorf("job %v claimed twice", job.ID)
+		}
+		claimed[job.ID] = true
+		if job.ID == future {
+			t.Errorf("job scheduled in the future was claimed")
+		}
+		if job.Status != "running" || job.Attempts != 1 {
+			t.Errorf("claimed job %v is %s after %d attempts, want running after 1", job.ID, job.Status, job.Attempts)
+		}
+	}
+}
+
+func TestRequeueStaleJobs(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+
+	r := NewRunner(db)
+	ran := false
+	r.Register("test_stale", func(context.Context, json.RawMessage) error {
+		ran = true
+		return nil
+	})
+	id := enqueueTestJob(t, r, "test_stale", time.Now().Add(-time.Hour), 5)
+
+	// A replica died while holding the job
+	_, err := db.Exec(
+		"UPDATE jobs SET status = 'running', attempts = 1, locked_by = 'dead', locked_at = $2 WHERE id = $1",
+		id, time.Now().Add(-2*staleLockTimeout),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.poll(ctx)
+
+	if job := getJob(t, db, id); !ran || job.status != "done" || job.attempts != 2 {
+		t.Errorf("ran = %v, job = %+v, want the stale job run to completion on attempt 2", ran, job)
+	}
+}
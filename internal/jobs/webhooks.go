@@ -0,0 +1,171 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/TheJa750/PrayerPals/internal/database"
+	"github.com/TheJa750/PrayerPals/internal/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	KindDeliverWebhook = "deliver_webhook"
+
+	webhookTimeout      = 10 * time.Second
+	maxWebhookRespBytes = 1024
+)
+
+// WebhookPayload identifies the delivery to send
+type WebhookPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// SignWebhook returns the signature header value for a payload sent at ts.
+// Receivers recompute HMAC-SHA256 over "<timestamp>.<body>" with their secret.
+func SignWebhook(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix + "."))
+	mac.Write(body)
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// errBlockedAddress is returned when a delivery resolves to an address
+// webhooks aren't allowed to reach
+var errBlockedAddress = errors.New("webhook address is not publicly routable")
+
+// webhookDialControl runs after DNS resolution, so it sees the address that is
+// actually dialled and can't be dodged with a hostname or DNS rebinding.
+// Loopback is only let through when allowLocal is set for development.
+func webhookDialControl(allowLocal bool) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return errBlockedAddress
+		}
+		if allowLocal && ip.IsLoopback() {
+			return nil
+		}
+		if !validation.IsPublicIP(ip) {
+			return fmt.Errorf("%w: %s", errBlockedAddress, ip)
+		}
+		return nil
+	}
+}
+
+// RegisterWebhooks adds the job that delivers webhook events. allowLocal lets
+// deliveries reach loopback receivers and should only be set in development.
+func (r *Runner) RegisterWebhooks(allowLocal bool) {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: webhookDialControl(allowLocal),
+	}
+	client := &http.Client{
+		Timeout: webhookTimeout,
+		// No proxy, the dial check has to see the receiver's own address
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+		},
+		// Redirects could bounce a delivery to an address that failed validation
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	r.Register(KindDeliverWebhook, func(ctx context.Context, payload json.RawMessage) error {
+		return r.deliverWebhook(ctx, client, payload)
+	})
+}
+
+func (r *Runner) deliverWebhook(ctx context.Context, client *http.Client, payload json.RawMessage) error {
+	var p WebhookPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("deliver webhook: error decoding payload: %w", err)
+	}
+
+	delivery, err := r.queries.GetDeliveryForSend(ctx, p.DeliveryID)
+	if err != nil {
+		// The webhook was deleted since the event was queued
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if !delivery.IsActive {
+		return r.queries.RecordWebhookAttempt(ctx, database.RecordWebhookAttemptParams{
+			ID:        delivery.ID,
+			Status:    "failed",
+			LastError: "webhook is disabled",
+		})
+	}
+
+	statusCode, body, sendErr := sendWebhook(ctx, client, delivery)
+
+	record := database.RecordWebhookAttemptParams{
+		ID:           delivery.ID,
+		Status:       "succeeded",
+		ResponseBody: body,
+	}
+	if statusCode != 0 {
+		record.ResponseStatus = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	}
+	if sendErr != nil {
+		record.LastError = sendErr.Error()
+		record.Status = "retrying"
+		if delivery.Attempts+1 >= defaultMaxAttempts {
+			record.Status = "failed"
+		}
+	}
+
+	if err := r.queries.RecordWebhookAttempt(ctx, record); err != nil {
+		return err
+	}
+
+	// Returning the error hands the retry and backoff to the runner
+	return sendErr
+}
+
+func sendWebhook(ctx context.Context, client *http.Client, delivery database.GetDeliveryForSendRow) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PrayerPals-Webhooks/1.0")
+	req.Header.Set("X-PrayerPals-Event", delivery.Event)
+	req.Header.Set("X-PrayerPals-Delivery", delivery.ID.String())
+	req.Header.Set("X-PrayerPals-Signature", SignWebhook(delivery.Secret, time.Now(), delivery.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookRespBytes))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, string(respBody), nil
+}
@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignWebhook(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"post.created"}`)
+
+	got := SignWebhook("secret", ts, body)
+
+	// Receivers recompute the HMAC over "<timestamp>.<body>"
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+	if got != want {
+		t.Fatalf("SignWebhook() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		ts     time.Time
+		body   []byte
+	}{
+		{"different secret", "other", ts, body},
+		{"different timestamp", "secret", ts.Add(time.Second), body},
+		{"different body", "secret", ts, []byte(`{"event":"post.deleted"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if SignWebhook(tt.secret, tt.ts, tt.body) == got {
+				t.Errorf("signature didn't change")
+			}
+		})
+	}
+
+	if !strings.HasPrefix(got, "t="+strconv.FormatInt(ts.Unix(), 10)+",") {
+		t.Errorf("signature %q doesn't lead with the timestamp", got)
+	}
+}
+
+func TestWebhookDialControl(t *testing.T) {
+	tests := []struct {
+		name       string
+		address    string
+		allowLocal bool
+		allowed    bool
+	}{
+		{"public v4", "93.184.216.34:443", false, true},
+		{"public v6", "[2606:2800:220:1:248:1893:25c8:1946]:443", false, true},
+		{"loopback", "127.0.0.1:8080", false, false},
+		{"loopback v6", "[::1]:8080", false, false},
+		{"private", "10.0.0.5:443", false, false},
+		{"metadata", "169.254.169.254:80", false, false},
+		{"unspecified", "0.0.0.0:443", false, false},
+		{"dev loopback", "127.0.0.1:8080", true, true},
+		{"dev private", "192.168.1.10:443", true, false},
+		{"unresolved host", "example.com:443", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhookDialControl(tt.allowLocal)("tcp", tt.address, nil)
+			if tt.allowed && err != nil {
+				t.Errorf("dial to %s blocked: %v", tt.address, err)
+			}
+			if !tt.allowed && !errors.Is(err, errBlockedAddress) {
+				t.Errorf("dial to %s = %v, want errBlockedAddress", tt.address, err)
+			}
+		})
+	}
+}
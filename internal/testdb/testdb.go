@@ -0,0 +1,129 @@
+// Package testdb gives tests a migrated Postgres database. Tests using it
+// are skipped unless TEST_DB_URL points at a server they may create
+// schemas on.
+package testdb
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq" // Import the PostgreSQL driver
+)
+
+// Open creates an empty schema, runs every migration in sql/schema against
+// it and drops it again when the test finishes
+func Open(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("error connecting to test database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatalf("error naming test schema: %v", err)
+	}
+	schema := "test_" + hex.EncodeToString(suffix)
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("error creating test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Logf("error dropping test schema %s: %v", schema, err)
+		}
+	})
+
+	// Every pooled connection has to resolve tables in the test schema
+	schemaURL, err := withSearchPath(dbURL, schema)
+	if err != nil {
+		t.Fatalf("error building test database URL: %v", err)
+	}
+	db, err := sql.Open("postgres", schemaURL)
+	if err != nil {
+		t.Fatalf("error connecting to test schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("error migrating test schema: %v", err)
+	}
+
+	return db
+}
+
+func withSearchPath(dbURL, schema string) (string, error) {
+	if !strings.HasPrefix(dbURL, "postgres://") && !strings.HasPrefix(dbURL, "postgresql://") {
+		return dbURL + " search_path=" + schema, nil
+	}
+
+	parsed, err := url.Parse(dbURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	query.Set("search_path", schema)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// migrate runs the Up section of each goose migration in order. The driver
+// accepts several statements per Exec, so function bodies need no splitting.
+func migrate(db *sql.DB) error {
+	dir, err := schemaDir()
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		if _, err := db.Exec(up); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return nil
+}
+
+// schemaDir finds sql/schema by walking up from the test's package directory
+func schemaDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.Join(dir, "sql", "schema"), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("sql/schema not found above the test directory")
+		}
+		dir = parent
+	}
+}
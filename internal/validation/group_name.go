@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type GroupNameValidationResult struct {
+	IsValid bool     `json:"is_valid"`
+	Errors  []string `json:"errors"`
+}
+
+func ValidateGroupName(name string) GroupNameValidationResult {
+	var errors []string
+
+	// Trim whitespace
+	name = strings.TrimSpace(name)
+
+	// Check if empty
+	if name == "" {
+		errors = append(errors, "Group name is required")
+		return GroupNameValidationResult{IsValid: false, Errors: errors}
+	}
+
+	// Maximum 100 characters
+	if utf8.RuneCountInString(name) > 100 {
+		errors = append(errors, "Group name must be no more than 100 characters long")
+	}
+
+	// No line breaks or other control characters
+	for _, char := range name {
+		if char < 32 || char == 127 {
+			errors = append(errors, "Group name cannot contain control characters")
+			break
+		}
+	}
+
+	return GroupNameValidationResult{
+		IsValid: len(errors) == 0,
+		Errors:  errors,
+	}
+}
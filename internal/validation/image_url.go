@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"net/url"
+	"strings"
+)
+
+type ImageURLValidationResult struct {
+	IsValid bool     `json:"is_valid"`
+	Errors  []string `json:"errors"`
+}
+
+func ValidateImageURL(imageURL string) ImageURLValidationResult {
+	var errors []string
+
+	// Trim whitespace
+	imageURL = strings.TrimSpace(imageURL)
+
+	// Check length
+	if len(imageURL) > 2048 {
+		errors = append(errors, "Image URL cannot exceed 2048 characters")
+		return ImageURLValidationResult{IsValid: false, Errors: errors}
+	}
+
+	// Must be an absolute http(s) URL
+	parsed, err := url.Parse(imageURL)
+	if err != nil || parsed.Host == "" {
+		errors = append(errors, "Invalid image URL format")
+		return ImageURLValidationResult{IsValid: false, Errors: errors}
+	}
+
+	// Only allow https, http is only accepted for local development
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && parsed.Hostname() == "localhost") {
+		errors = append(errors, "Image URL must use https")
+	}
+
+	return ImageURLValidationResult{
+		IsValid: len(errors) == 0,
+		Errors:  errors,
+	}
+}
@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+type WebhookURLValidationResult struct {
+	IsValid bool     `json:"is_valid"`
+	Errors  []string `json:"errors"`
+}
+
+// ValidateWebhookURL checks a receiver URL before it is stored. Local
+// receivers over plain http are only accepted when allowLocal is set, which
+// is meant for development.
+func ValidateWebhookURL(webhookURL string, allowLocal bool) WebhookURLValidationResult {
+	var errors []string
+
+	// Trim whitespace
+	webhookURL = strings.TrimSpace(webhookURL)
+
+	// Check length
+	if len(webhookURL) > 2048 {
+		errors = append(errors, "Webhook URL cannot exceed 2048 characters")
+		return WebhookURLValidationResult{IsValid: false, Errors: errors}
+	}
+
+	// Must be an absolute http(s) URL
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Host == "" {
+		errors = append(errors, "Invalid webhook URL format")
+		return WebhookURLValidationResult{IsValid: false, Errors: errors}
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	ip := net.ParseIP(host)
+	isLocal := host == "localhost" || strings.HasSuffix(host, ".localhost") || (ip != nil && ip.IsLoopback())
+
+	// Only allow https, http is only accepted for local receivers in development
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && isLocal && allowLocal) {
+		errors = append(errors, "Webhook URL must use https")
+	}
+
+	// Don't let webhooks reach into private networks. Hostnames are checked
+	// again against the resolved address when the delivery dials out.
+	if isLocal && !allowLocal {
+		errors = append(errors, "Webhook URL cannot point to a private address")
+	} else if ip != nil && !isLocal && !IsPublicIP(ip) {
+		errors = append(errors, "Webhook URL cannot point to a private address")
+	}
+
+	if parsed.User != nil {
+		errors = append(errors, "Webhook URL cannot contain credentials")
+	}
+
+	return WebhookURLValidationResult{
+		IsValid: len(errors) == 0,
+		Errors:  errors,
+	}
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which the net package
+// doesn't treat as private
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether ip is a routable address a webhook may be sent to
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && (ip4[0] == 0 || sharedAddressSpace.Contains(ip4)) {
+		return false
+	}
+	return true
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
+	"github.com/TheJa750/PrayerPals/internal/activity"
 	"github.com/TheJa750/PrayerPals/internal/database"
 	"github.com/TheJa750/PrayerPals/internal/handlers"
+	"github.com/TheJa750/PrayerPals/internal/jobs"
+	"github.com/TheJa750/PrayerPals/internal/mailer"
 	"github.com/TheJa750/PrayerPals/internal/middleware"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -16,6 +24,9 @@ import (
 	_ "github.com/lib/pq" // Import the PostgreSQL driver
 )
 
+// How long in-flight requests get to finish after a shutdown signal
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	godotenv.Load()
 	dbURL := os.Getenv("DB_URL")
@@ -32,11 +43,27 @@ func main() {
 		Handler:           middleware.CorsMiddleware(middleware.LoggingMiddleware(router)),
 		ReadHeaderTimeout: 10 * time.Second,
 	}
-	log.Println("Starting server on :8080")
+
+	// Interrupt or SIGTERM stops the server and the background jobs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Member last seen times are written in batches. The tracker is stopped
+	// after the server so activity from draining requests is still flushed.
+	trackerCtx, stopTracker := context.WithCancel(context.Background())
+	defer stopTracker()
+	tracker := activity.NewTracker(db)
+	tracker.Start(trackerCtx, activity.DefaultFlushInterval)
 
 	cfg := handlers.APIConfig{
-		DBQueries: database.New(db),
-		JWTSecret: os.Getenv("JWT_SECRET"),
+		DB:                 db,
+		DBQueries:          database.New(db),
+		JWTSecret:          os.Getenv("JWT_SECRET"),
+		Activity:           tracker,
+		AllowLocalWebhooks: os.Getenv("PLATFORM") == "dev",
+	}
+	if depth, err := strconv.Atoi(os.Getenv("MAX_REPLY_DEPTH")); err == nil && depth > 0 {
+		cfg.MaxReplyDepth = depth
 	}
 
 	// File handler
@@ -53,36 +80,138 @@ func main() {
 	// User Account Handlers
 	router.HandleFunc("/api/users", cfg.CreateUserHandler).Methods("POST")       // Expecting JSON body for username/email/password
 	router.HandleFunc("/api/users/update", cfg.UpdateUserHandler).Methods("PUT") // Expecting JSON body for username/password (1 only)
-	router.HandleFunc("/api/login", cfg.LoginUserHandler).Methods("POST")        // Expecting JSON body for email/password
+	router.HandleFunc("/api/users/privacy", cfg.GetPrivacySettingsHandler).Methods("GET")
+	router.HandleFunc("/api/users/privacy", cfg.UpdatePrivacySettingsHandler).Methods("PATCH") // Expecting JSON body with any of email_visibility/hide_from_directory
+	router.HandleFunc("/api/login", cfg.LoginUserHandler).Methods("POST")                      // Expecting JSON body for email/password
 	router.HandleFunc("/api/refresh", cfg.RefreshJWTHandler).Methods("POST")
 	router.HandleFunc("/api/logout", cfg.LogoutUserHandler).Methods("POST")
+	router.HandleFunc("/api/me/commitments", cfg.GetMyCommitmentsHandler).Methods("GET") // Active prayer commitments across all groups
 
 	// User Functions Handlers
 	router.HandleFunc("/api/groups/invite/{invite_code}/join", cfg.JoinGroupHandler).Methods("POST")
+	router.HandleFunc("/api/invitations/{token}/accept", cfg.AcceptInvitationHandler).Methods("POST")
 	router.HandleFunc("/api/groups/{group_id}/leave", cfg.LeaveGroupHandler).Methods("DELETE")
 	router.HandleFunc("/api/groups", cfg.GetGroupsForFeed).Methods("GET")
+	router.HandleFunc("/api/groups/discover", cfg.DiscoverGroupsHandler).Methods("GET")  // Expecting query parameters ?q=&limit=20&offset=0, must be registered before /api/groups/{group_id}
+	router.HandleFunc("/api/groups/deleted", cfg.GetDeletedGroupsHandler).Methods("GET") // Groups the user owns that can still be restored, must be registered before /api/groups/{group_id}
 
 	// Group Handlers
 	router.HandleFunc("/api/groups", cfg.CreateGroupHandler).Methods("POST")                                     // Expecting JSON body for name/description
 	router.HandleFunc("/api/groups/{group_id}", cfg.GetGroupInfoHandler).Methods("GET")                          // Expecting group_id in URL
 	router.HandleFunc("/api/groups/{group_id}/members/{user_id}/promote", cfg.PromoteUserHandler).Methods("PUT") // Expecting JSON body for new role
-	router.HandleFunc("/api/groups/{group_id}/posts", cfg.GetPostFeedHandler).Methods("GET")                     // Expecting query parameters ?limit=10&offset=0
+	router.HandleFunc("/api/groups/{group_id}/posts", cfg.GetPostFeedHandler).Methods("GET")                     // Expecting query parameters ?limit=10&offset=0 and optional &category=&status=
 	router.HandleFunc("/api/groups/{group_id}/posts/count", cfg.GetPostCountHandler).Methods("GET")              // Expecting group_id in URL
-	router.HandleFunc("/api/groups/{group_id}", cfg.DeleteGroupHandler).Methods("DELETE")
-	router.HandleFunc("/api/groups/{group_id}/members/{user_id}/moderate", cfg.ModerateUserHandler).Methods("PUT") // Expecting JSON body for action and reason
+	router.HandleFunc("/api/groups/{group_id}/posts/archived", cfg.GetArchivedPostsHandler).Methods("GET")       // Expecting query parameters ?limit=10&offset=0 and optional &category=&status=
+	router.HandleFunc("/api/groups/{group_id}/posts/scheduled", cfg.GetScheduledPostsHandler).Methods("GET")     // Admins only, posts waiting for their publish_at
+	router.HandleFunc("/api/groups/{group_id}", cfg.DeleteGroupHandler).Methods("DELETE")                        // Expecting JSON body with confirm_name matching the group name
+	router.HandleFunc("/api/groups/{group_id}/restore", cfg.RestoreGroupHandler).Methods("POST")
+	router.HandleFunc("/api/groups/{group_id}/members/{user_id}/moderate", cfg.ModerateUserHandler).Methods("PUT") // Expecting JSON body for action, reason and optional duration
 	router.HandleFunc("/api/groups/invite/{invite_code}", cfg.GroupFromInviteCodeHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/members/import", cfg.ImportMembersHandler).Methods("POST")      // Expecting CSV body of name,email,role and optional ?commit=true
 	router.HandleFunc("/api/groups/{group_id}/members", cfg.GetGroupMembersHandler).Methods("GET")            // Expecting group_id in URL
 	router.HandleFunc("/api/groups/{group_id}/members/{user_id}", cfg.GetUserGroupRoleHandler).Methods("GET") // Expecting group_id and user_id in URL
 	router.HandleFunc("/api/groups/{group_id}/invite-code", cfg.ChangeInviteCodeHandler).Methods("PUT")       // Expecting group_id in URL and new invite code in JSON body
 	router.HandleFunc("/api/groups/{group_id}/rules", cfg.ChangeGroupRulesHandler).Methods("PUT")             // Expecting group_id in URL and new rules in JSON body
+	router.HandleFunc("/api/groups/{group_id}/rules/versions", cfg.GetRulesHistoryHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/rules/diff", cfg.GetRulesDiffHandler).Methods("GET")   // Expecting optional query parameters ?from=&to= (rules versions)
+	router.HandleFunc("/api/groups/{group_id}/rules/accept", cfg.AcceptRulesHandler).Methods("POST") // Expecting JSON body with the accepted version
+	router.HandleFunc("/api/groups/{group_id}/rules/acceptances", cfg.GetRulesAcceptancesHandler).Methods("GET")
 	router.HandleFunc("/api/groups/{group_id}/description", cfg.ChangeGroupDescriptionHandler).Methods("PUT") // Expecting group_id in URL and new description in JSON body
+	router.HandleFunc("/api/groups/{group_id}/settings", cfg.UpdateGroupSettingsHandler).Methods("PATCH")     // Expecting JSON body with any of name/description/avatar_url/rules/post_policy/join_mode/listed/require_second_approval/require_rules_acceptance/edit_window_minutes
+	router.HandleFunc("/api/groups/{group_id}/join-requests", cfg.GetJoinRequestsHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/join-requests/{user_id}", cfg.ReviewJoinRequestHandler).Methods("PUT") // Expecting JSON body for action (approve/deny)
+	router.HandleFunc("/api/groups/{group_id}/pending-actions", cfg.GetPendingActionsHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/moderation-log", cfg.GetModerationLogHandler).Methods("GET") // Expecting query parameters ?limit=50&offset=0
+	router.HandleFunc("/api/groups/{group_id}/stats", cfg.GetGroupStatsHandler).Methods("GET")             // Expecting query parameters ?weeks=12&format=json|csv
+	router.HandleFunc("/api/groups/{group_id}/pending-actions/{action_id}/approve", cfg.ApprovePendingActionHandler).Methods("POST")
+	router.HandleFunc("/api/groups/{group_id}/pending-actions/{action_id}", cfg.CancelPendingActionHandler).Methods("DELETE")
 
 	// Post Handlers
-	router.HandleFunc("/api/groups/{group_id}/posts", cfg.CreatePostHandler).Methods("POST") // Expecting JSON body for post content
+	router.HandleFunc("/api/groups/{group_id}/posts", cfg.CreatePostHandler).Methods("POST")               // Expecting JSON body for post content
+	router.HandleFunc("/api/groups/{group_id}/posts/pinned", cfg.GetPinnedPostsHandler).Methods("GET")     // Must be registered before /api/groups/{group_id}/posts/{post_id}
+	router.HandleFunc("/api/groups/{group_id}/posts/answered", cfg.GetAnsweredPostsHandler).Methods("GET") // Expecting query parameters ?limit=10&offset=0
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}", cfg.EditPostHandler).Methods("PUT")        // Expecting JSON body for the new content
 	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}", cfg.DeletePostHandler).Methods("DELETE")
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/history", cfg.GetPostHistoryHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/status", cfg.SetPostStatusHandler).Methods("PUT") // Expecting JSON body for status and optional note
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/pin", cfg.PinPostHandler).Methods("PUT")          // Expecting JSON body for order and optional until
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/pin", cfg.UnpinPostHandler).Methods("DELETE")
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/prayers", cfg.RecordPrayerHandler).Methods("POST")
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/prayers", cfg.WithdrawPrayerHandler).Methods("DELETE")
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/prayers", cfg.GetPrayerRosterHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/commitments", cfg.CommitToPrayHandler).Methods("PUT") // Expecting JSON body for frequency and until
+	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/commitments", cfg.CancelCommitmentHandler).Methods("DELETE")
 	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/comments", cfg.CreateCommentHandler).Methods("POST") // Expecting JSON body for comment content
 	router.HandleFunc("/api/groups/{group_id}/posts/{post_id}/comments", cfg.GetCommentsForPostHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/categories", cfg.GetGroupCategoriesHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/categories", cfg.CreateGroupCategoryHandler).Methods("POST") // Expecting JSON body for name
+	router.HandleFunc("/api/groups/{group_id}/categories/{category}", cfg.DeleteGroupCategoryHandler).Methods("DELETE")
 	router.HandleFunc("/api/groups/{group_id}/members/{user_id}/remove-content", cfg.RemoveUserContentHandler).Methods("PUT") // Expecting group_id and user_id in URL
 
-	log.Fatal(svr.ListenAndServe())
+	// Webhook Handlers
+	router.HandleFunc("/api/groups/{group_id}/webhooks", cfg.CreateWebhookHandler).Methods("POST") // Expecting JSON body for url and events
+	router.HandleFunc("/api/groups/{group_id}/webhooks", cfg.GetWebhooksHandler).Methods("GET")
+	router.HandleFunc("/api/groups/{group_id}/webhooks/{webhook_id}", cfg.UpdateWebhookHandler).Methods("PATCH") // Expecting JSON body with any of url/events/is_active
+	router.HandleFunc("/api/groups/{group_id}/webhooks/{webhook_id}", cfg.DeleteWebhookHandler).Methods("DELETE")
+	router.HandleFunc("/api/groups/{group_id}/webhooks/{webhook_id}/deliveries", cfg.GetWebhookDeliveriesHandler).Methods("GET") // Expecting query parameters ?limit=50&offset=0
+	router.HandleFunc("/api/groups/{group_id}/webhooks/{webhook_id}/deliveries/{delivery_id}/replay", cfg.ReplayWebhookDeliveryHandler).Methods("POST")
+
+	// Organization Handlers
+	router.HandleFunc("/api/orgs", cfg.CreateOrganizationHandler).Methods("POST") // Expecting JSON body for name/description
+	router.HandleFunc("/api/orgs", cfg.GetOrganizationsHandler).Methods("GET")
+	router.HandleFunc("/api/orgs/{org_id}", cfg.GetOrganizationHandler).Methods("GET")
+	router.HandleFunc("/api/orgs/{org_id}", cfg.UpdateOrganizationHandler).Methods("PATCH") // Expecting JSON body with any of name/description/default_rules/default_join_mode/default_post_policy
+	router.HandleFunc("/api/orgs/{org_id}/admins", cfg.GetOrganizationAdminsHandler).Methods("GET")
+	router.HandleFunc("/api/orgs/{org_id}/admins", cfg.AddOrganizationAdminHandler).Methods("POST") // Expecting JSON body for email
+	router.HandleFunc("/api/orgs/{org_id}/admins/{user_id}", cfg.RemoveOrganizationAdminHandler).Methods("DELETE")
+	router.HandleFunc("/api/orgs/{org_id}/groups", cfg.CreateOrganizationGroupHandler).Methods("POST") // Expecting JSON body for name/description
+	router.HandleFunc("/api/orgs/{org_id}/groups", cfg.GetOrganizationGroupsHandler).Methods("GET")
+	router.HandleFunc("/api/orgs/{org_id}/groups/{group_id}", cfg.AttachGroupHandler).Methods("PUT")
+	router.HandleFunc("/api/orgs/{org_id}/groups/{group_id}", cfg.DetachGroupHandler).Methods("DELETE")
+	router.HandleFunc("/api/orgs/{org_id}/membership", cfg.GetOrganizationMembershipHandler).Methods("GET")
+
+	// Background jobs
+	runner := jobs.NewRunner(db)
+	postRetention := jobs.DefaultPostRetention
+	if days, err := strconv.Atoi(os.Getenv("POST_RETENTION_DAYS")); err == nil && days > 0 {
+		postRetention = time.Duration(days) * 24 * time.Hour
+	}
+	if err := runner.RegisterMaintenance(postRetention); err != nil {
+		log.Fatalf("Error registering maintenance jobs: %v", err)
+	}
+	mail := mailer.FromEnv()
+	runner.RegisterInvitations(mail)
+	runner.RegisterWebhooks(cfg.AllowLocalWebhooks)
+	if err := runner.RegisterCommitments(mail); err != nil {
+		log.Fatalf("Error registering prayer commitment jobs: %v", err)
+	}
+	if err := runner.RegisterPublishing(cfg.PostsPublished); err != nil {
+		log.Fatalf("Error registering publishing jobs: %v", err)
+	}
+	if err := runner.Start(ctx); err != nil {
+		log.Fatalf("Error starting job runner: %v", err)
+	}
+
+	go func() {
+		log.Println("Starting server on :8080")
+		if err := svr.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error running server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("Shutting down server")
+
+	// Let in-flight requests finish before the background work winds down
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := svr.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	runner.Wait()
+	stopTracker()
+	tracker.Wait()
+
+	log.Println("Server stopped")
 }
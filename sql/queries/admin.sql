@@ -1,30 +1,55 @@
 -- name: GetKickBanStatus :one
-SELECT is_banned, is_kicked, kicked_until
+SELECT is_banned, is_kicked, kicked_until, is_muted, muted_until
 FROM users_groups
 WHERE user_id = $1 AND group_id = $2;
 
 -- name: BanUser :exec
 UPDATE users_groups
-SET is_banned = TRUE, kicked_until = NULL, modded_reason = $3,
-    modded_at = NOW(), modded_by = $4
+SET is_banned = TRUE, kicked_until = NULL, ban_reason = $3,
+    banned_at = NOW(), banned_by = $4
 WHERE user_id = $1 AND group_id = $2;
 
 -- name: UnbanUser :exec
+-- Also clears any kick so the user is fully restored, mutes are kept
 UPDATE users_groups
-SET is_banned = FALSE, kicked_until = NULL, modded_reason = '',
-    modded_at = NOW(), modded_by = $3
+SET is_banned = FALSE, ban_reason = '', banned_at = NULL, banned_by = NULL,
+    is_kicked = FALSE, kicked_until = NULL, kick_reason = '', kicked_at = NULL, kicked_by = NULL
 WHERE user_id = $1 AND group_id = $2;
 
 -- name: KickUser :exec
 UPDATE users_groups
-SET is_kicked = TRUE, kicked_until = NOW() + INTERVAL '7 days', modded_reason = $3,
-    modded_at = NOW(), modded_by = $4
+SET is_kicked = TRUE, kicked_until = $3, kick_reason = $4,
+    kicked_at = NOW(), kicked_by = $5
+WHERE user_id = $1 AND group_id = $2;
+
+-- name: MuteUser :exec
+UPDATE users_groups
+SET is_muted = TRUE, muted_until = $3, mute_reason = $4,
+    muted_at = NOW(), muted_by = $5
+WHERE user_id = $1 AND group_id = $2;
+
+-- name: UnmuteUser :exec
+UPDATE users_groups
+SET is_muted = FALSE, muted_until = NULL, mute_reason = '',
+    muted_at = NULL, muted_by = NULL
+WHERE user_id = $1 AND group_id = $2;
+
+-- name: ResetMuteStatus :exec
+UPDATE users_groups
+SET is_muted = FALSE, muted_until = NULL,
+    mute_reason = mute_reason || ' - Mute has expired'
+WHERE user_id = $1 AND group_id = $2;
+
+-- name: UnkickUser :exec
+UPDATE users_groups
+SET is_kicked = FALSE, kicked_until = NULL, kick_reason = '',
+    kicked_at = NULL, kicked_by = NULL
 WHERE user_id = $1 AND group_id = $2;
 
 -- name: ResetKickStatus :exec
 UPDATE users_groups
-SET is_kicked = FALSE, kicked_until = NULL, modded_at = NOW(),
-    modded_reason = modded_reason || ' - Kick has expired'
+SET is_kicked = FALSE, kicked_until = NULL,
+    kick_reason = kick_reason || ' - Kick has expired'
 WHERE user_id = $1 AND group_id = $2;
 
 -- name: RemovePostsByUser :exec
@@ -45,4 +70,89 @@ WHERE id = $1;
 -- name: UpdateGroupRules :exec
 UPDATE groups
 SET rules_info = $2
+WHERE id = $1;
+
+-- name: GetBannedMembers :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    users_groups.role,
+    users_groups.ban_reason AS reason,
+    users_groups.banned_at AS moderated_at,
+    users_groups.banned_by AS moderated_by,
+    COALESCE(moderators.username, '')::TEXT AS moderator_username,
+    users_groups.kicked_until
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN users AS moderators ON moderators.id = users_groups.banned_by
+WHERE users_groups.group_id = $1
+AND users_groups.is_banned
+ORDER BY users_groups.banned_at DESC;
+
+-- name: GetKickedMembers :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    users_groups.role,
+    users_groups.kick_reason AS reason,
+    users_groups.kicked_at AS moderated_at,
+    users_groups.kicked_by AS moderated_by,
+    COALESCE(moderators.username, '')::TEXT AS moderator_username,
+    users_groups.kicked_until
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN users AS moderators ON moderators.id = users_groups.kicked_by
+WHERE users_groups.group_id = $1
+AND users_groups.is_kicked
+AND users_groups.kicked_until > NOW()
+AND NOT users_groups.is_banned
+ORDER BY users_groups.kicked_until ASC;
+
+-- name: GetMutedMembers :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    users_groups.role,
+    users_groups.mute_reason AS reason,
+    users_groups.muted_at AS moderated_at,
+    users_groups.muted_by AS moderated_by,
+    COALESCE(moderators.username, '')::TEXT AS moderator_username,
+    users_groups.muted_until
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN users AS moderators ON moderators.id = users_groups.muted_by
+WHERE users_groups.group_id = $1
+AND users_groups.is_muted
+AND users_groups.muted_until > NOW()
+AND NOT users_groups.is_banned
+ORDER BY users_groups.muted_until ASC;
+
+-- name: ExpireKicks :execrows
+UPDATE users_groups
+SET is_kicked = FALSE, kicked_until = NULL,
+    kick_reason = kick_reason || ' - Kick has expired'
+WHERE is_kicked AND kicked_until <= NOW();
+
+-- name: ExpireMutes :execrows
+UPDATE users_groups
+SET is_muted = FALSE, muted_until = NULL,
+    mute_reason = mute_reason || ' - Mute has expired'
+WHERE is_muted AND muted_until <= NOW();
+
+-- name: UpdateGroupSettings :one
+UPDATE groups
+SET name = $2, description = $3, avatar_url = $4, rules_info = $5,
+    post_policy = $6, join_mode = $7, is_listed = $8,
+    require_second_approval = $9, require_rules_acceptance = $10,
+    edit_window_minutes = $11, allow_anonymous = $12,
+    auto_archive_days = $13, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DisableSecondApproval :exec
+UPDATE groups
+SET require_second_approval = FALSE, updated_at = NOW()
 WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,36 @@
+-- name: GetGroupCategories :many
+SELECT name, created_at
+FROM group_categories
+WHERE group_id = $1
+ORDER BY name ASC;
+
+-- name: GroupCategoryExists :one
+SELECT EXISTS (
+    SELECT 1 FROM group_categories
+    WHERE group_id = $1 AND name = $2
+);
+
+-- name: CreateGroupCategory :execrows
+INSERT INTO group_categories (group_id, name, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteGroupCategory :execrows
+DELETE FROM group_categories
+WHERE group_id = $1 AND name = $2;
+
+-- name: ResetPostCategory :exec
+UPDATE posts
+SET category = 'prayer_request'
+WHERE group_id = $1 AND category = $2;
+
+-- name: GetPostCountsByCategory :many
+SELECT posts.category, COUNT(*) AS post_count
+FROM posts
+WHERE posts.group_id = sqlc.arg(group_id)
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND post_visible_to(posts.id, sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader))
+GROUP BY posts.category
+ORDER BY category ASC;
\ No newline at end of file
@@ -0,0 +1,145 @@
+-- name: UpsertPrayerCommitment :one
+-- Committing again replaces the frequency and end date of the active commitment
+INSERT INTO prayer_commitments (post_id, user_id, frequency, ends_at, next_reminder_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (post_id, user_id) WHERE ended_at IS NULL DO UPDATE
+SET frequency = EXCLUDED.frequency,
+    ends_at = EXCLUDED.ends_at,
+    next_reminder_at = EXCLUDED.next_reminder_at
+RETURNING *;
+
+-- name: CancelPrayerCommitment :execrows
+UPDATE prayer_commitments
+SET ended_at = NOW(), end_reason = 'cancelled'
+WHERE post_id = $1 AND user_id = $2 AND ended_at IS NULL;
+
+-- name: EndCommitmentsForPost :execrows
+UPDATE prayer_commitments
+SET ended_at = NOW(), end_reason = $2
+WHERE post_id = $1 AND ended_at IS NULL;
+
+-- name: EndFinishedCommitments :execrows
+-- Catches requests answered or deleted through any path, expired end dates,
+-- members who have left the group or been banned or kicked from it and
+-- members no longer in a restricted request's audience
+UPDATE prayer_commitments
+SET ended_at = NOW(),
+    end_reason = CASE
+        WHEN posts.is_deleted OR groups.deleted_at IS NOT NULL THEN 'deleted'
+        WHEN posts.status = 'answered' THEN 'answered'
+        WHEN prayer_commitments.ends_at <= NOW() THEN 'expired'
+        WHEN NOT EXISTS (
+            SELECT 1 FROM users_groups
+            WHERE users_groups.user_id = prayer_commitments.user_id
+            AND users_groups.group_id = posts.group_id
+        ) THEN 'left_group'
+        WHEN NOT EXISTS (
+            SELECT 1 FROM users_groups
+            WHERE users_groups.user_id = prayer_commitments.user_id
+            AND users_groups.group_id = posts.group_id
+            AND NOT users_groups.is_banned
+            AND (NOT users_groups.is_kicked OR users_groups.kicked_until <= NOW())
+        ) THEN 'removed'
+        ELSE 'no_access'
+    END
+FROM posts
+JOIN groups ON groups.id = posts.group_id
+WHERE posts.id = prayer_commitments.post_id
+AND prayer_commitments.ended_at IS NULL
+AND (
+    posts.is_deleted
+    OR groups.deleted_at IS NOT NULL
+    OR posts.status = 'answered'
+    OR prayer_commitments.ends_at <= NOW()
+    OR NOT EXISTS (
+        SELECT 1 FROM users_groups
+        WHERE users_groups.user_id = prayer_commitments.user_id
+        AND users_groups.group_id = posts.group_id
+        AND NOT users_groups.is_banned
+        AND (NOT users_groups.is_kicked OR users_groups.kicked_until <= NOW())
+    )
+    OR NOT post_visible_to(posts.id, prayer_commitments.user_id, is_group_leader(prayer_commitments.user_id, posts.group_id))
+);
+
+-- name: ClaimDueCommitments :many
+SELECT id, frequency, next_reminder_at
+FROM prayer_commitments
+WHERE ended_at IS NULL AND next_reminder_at <= NOW()
+ORDER BY next_reminder_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceCommitmentReminder :exec
+UPDATE prayer_commitments
+SET next_reminder_at = $2, last_reminded_at = NOW()
+WHERE id = $1;
+
+-- name: GetCommitmentReminder :one
+-- can_view is rechecked at send time, the member may have lost access since
+-- the reminder was queued
+SELECT
+    prayer_commitments.id,
+    prayer_commitments.frequency,
+    prayer_commitments.ends_at,
+    prayer_commitments.ended_at,
+    users.email,
+    users.username,
+    posts.content,
+    posts.user_id AS author_id,
+    posts.is_anonymous,
+    groups.name AS group_name,
+    COALESCE(authors.username, '')::TEXT AS author_username,
+    (
+        NOT posts.is_deleted
+        AND posts.status <> 'answered'
+        AND groups.deleted_at IS NULL
+        AND EXISTS (
+            SELECT 1 FROM users_groups
+            WHERE users_groups.user_id = prayer_commitments.user_id
+            AND users_groups.group_id = posts.group_id
+            AND NOT users_groups.is_banned
+            AND (NOT users_groups.is_kicked OR users_groups.kicked_until <= NOW())
+        )
+        AND post_visible_to(posts.id, prayer_commitments.user_id, is_group_leader(prayer_commitments.user_id, posts.group_id))
+    )::BOOLEAN AS can_view
+FROM prayer_commitments
+JOIN users ON users.id = prayer_commitments.user_id
+JOIN posts ON posts.id = prayer_commitments.post_id
+JOIN groups ON groups.id = posts.group_id
+LEFT JOIN users AS authors ON authors.id = posts.user_id
+WHERE prayer_commitments.id = $1;
+
+-- name: GetActiveCommitmentsForUser :many
+SELECT
+    prayer_commitments.id,
+    prayer_commitments.post_id,
+    prayer_commitments.frequency,
+    prayer_commitments.ends_at,
+    prayer_commitments.next_reminder_at,
+    prayer_commitments.last_reminded_at,
+    prayer_commitments.created_at,
+    posts.group_id,
+    posts.content,
+    posts.user_id AS author_id,
+    posts.is_anonymous,
+    groups.name AS group_name,
+    COALESCE(authors.username, '')::TEXT AS author_username
+FROM prayer_commitments
+JOIN posts ON posts.id = prayer_commitments.post_id
+JOIN groups ON groups.id = posts.group_id
+LEFT JOIN users AS authors ON authors.id = posts.user_id
+WHERE prayer_commitments.user_id = $1
+AND prayer_commitments.ended_at IS NULL
+AND prayer_commitments.ends_at > NOW()
+AND posts.is_deleted = FALSE
+AND posts.status <> 'answered'
+AND groups.deleted_at IS NULL
+AND EXISTS (
+    SELECT 1 FROM users_groups
+    WHERE users_groups.user_id = prayer_commitments.user_id
+    AND users_groups.group_id = posts.group_id
+    AND NOT users_groups.is_banned
+    AND (NOT users_groups.is_kicked OR users_groups.kicked_until <= NOW())
+)
+AND post_visible_to(posts.id, prayer_commitments.user_id, is_group_leader(prayer_commitments.user_id, posts.group_id))
+ORDER BY prayer_commitments.next_reminder_at;
\ No newline at end of file
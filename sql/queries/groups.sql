@@ -9,8 +9,8 @@ FROM groups
 WHERE id = $1;
 
 -- name: AddUserToGroup :exec
-INSERT INTO users_groups (user_id, group_id, role)
-VALUES ($1, $2, $3)
+INSERT INTO users_groups (user_id, group_id, role, joined_at)
+VALUES ($1, $2, $3, NOW())
 ON CONFLICT DO NOTHING;
 
 -- name: RemoveUserFromGroup :exec
@@ -21,21 +21,50 @@ WHERE user_id = $1 AND group_id = $2;
 DELETE FROM groups
 WHERE id = $1;
 
+-- name: SoftDeleteGroup :execrows
+UPDATE groups
+SET deleted_at = NOW(), deleted_by = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreGroup :execrows
+UPDATE groups
+SET deleted_at = NULL, deleted_by = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at > $2;
+
+-- name: GetDeletedGroupsForOwner :many
+-- Groups without an owner can be restored by their leaders
+SELECT id, name, description, deleted_at
+FROM groups
+WHERE (owner_id = $1 OR (owner_id IS NULL AND is_group_leader($1, id)))
+AND deleted_at > $2
+ORDER BY deleted_at DESC;
+
+-- name: IsGroupLeader :one
+-- Unlike GetUserGroupRole this still answers for deleted groups
+SELECT is_group_leader(sqlc.arg(user_id)::UUID, sqlc.arg(group_id)::UUID)::BOOLEAN AS is_leader;
+
+-- name: PurgeDeletedGroups :execrows
+DELETE FROM groups
+WHERE deleted_at < $1;
+
 -- name: ResetGroups :exec
 TRUNCATE TABLE groups CASCADE;
 
 -- name: GetGroupMembersIDs :many
-SELECT user_id
+SELECT users_groups.user_id
 FROM users_groups
-WHERE group_id = $1;
+JOIN groups ON groups.id = users_groups.group_id
+WHERE users_groups.group_id = $1
+AND groups.deleted_at IS NULL;
 
 -- name: GetGroupsForUser :many
 SELECT groups.id, groups.name, groups.description, groups.owner_id
 FROM users_groups
 JOIN groups ON users_groups.group_id = groups.id
 WHERE users_groups.user_id = $1
+AND groups.deleted_at IS NULL
 AND users_groups.is_banned = false
-AND users_groups.is_kicked = false;
+AND (users_groups.is_kicked = false OR users_groups.kicked_until <= NOW());
 
 -- name: GetGroupSpecialRoles :many
 SELECT user_id, role
@@ -44,16 +73,103 @@ WHERE group_id = $1 AND role != 'member';
 
 -- name: GetGroupByInviteCode :one
 SELECT * FROM groups
-WHERE invite_code = $1;
+WHERE invite_code = $1 AND deleted_at IS NULL;
 
 -- name: GetActiveMembers :many
 SELECT
     users.id,
     users.username,
     users.email,
-    users_groups.role
+    users.email_visibility,
+    users.hide_from_directory,
+    users_groups.role,
+    users_groups.joined_at,
+    users_groups.last_seen_at
 FROM users_groups
 JOIN users ON users.id = users_groups.user_id
 WHERE users_groups.group_id = $1
 AND NOT users_groups.is_banned
-AND NOT users_groups.is_kicked;
\ No newline at end of file
+AND (NOT users_groups.is_kicked OR users_groups.kicked_until <= NOW());
+
+-- name: CreateJoinRequest :exec
+INSERT INTO group_join_requests (group_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetJoinRequests :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    group_join_requests.created_at
+FROM group_join_requests
+JOIN users ON users.id = group_join_requests.user_id
+WHERE group_join_requests.group_id = $1
+ORDER BY group_join_requests.created_at ASC;
+
+-- name: DeleteJoinRequest :execrows
+DELETE FROM group_join_requests
+WHERE group_id = $1 AND user_id = $2;
+
+-- name: ListListedGroups :many
+SELECT
+    groups.id,
+    groups.name,
+    groups.description,
+    groups.avatar_url,
+    groups.invite_code,
+    groups.join_mode,
+    (
+        SELECT COUNT(*)
+        FROM users_groups
+        WHERE users_groups.group_id = groups.id
+        AND NOT users_groups.is_banned
+        AND NOT users_groups.is_kicked
+    ) AS member_count
+FROM groups
+WHERE groups.is_listed
+AND groups.deleted_at IS NULL
+ORDER BY member_count DESC, groups.name ASC
+LIMIT $1 OFFSET $2;
+
+-- name: SearchListedGroups :many
+SELECT
+    groups.id,
+    groups.name,
+    groups.description,
+    groups.avatar_url,
+    groups.invite_code,
+    groups.join_mode,
+    (
+        SELECT COUNT(*)
+        FROM users_groups
+        WHERE users_groups.group_id = groups.id
+        AND NOT users_groups.is_banned
+        AND NOT users_groups.is_kicked
+    ) AS member_count
+FROM groups
+WHERE groups.is_listed
+AND groups.deleted_at IS NULL
+AND to_tsvector('english', groups.name || ' ' || COALESCE(groups.description, ''))
+    @@ websearch_to_tsquery('english', sqlc.arg(query)::TEXT)
+ORDER BY
+    ts_rank(
+        to_tsvector('english', groups.name || ' ' || COALESCE(groups.description, '')),
+        websearch_to_tsquery('english', sqlc.arg(query)::TEXT)
+    ) DESC,
+    member_count DESC,
+    groups.name ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: TouchMemberships :exec
+-- Batched last-activity update, GREATEST ignores the NULL of a first visit
+UPDATE users_groups
+SET last_seen_at = GREATEST(users_groups.last_seen_at, seen.seen_at)
+FROM (
+    SELECT
+        unnest(sqlc.arg(user_ids)::UUID[]) AS user_id,
+        unnest(sqlc.arg(group_ids)::UUID[]) AS group_id,
+        to_timestamp(unnest(sqlc.arg(seen_unix)::BIGINT[])) AS seen_at
+) AS seen
+WHERE users_groups.user_id = seen.user_id
+AND users_groups.group_id = seen.group_id;
\ No newline at end of file
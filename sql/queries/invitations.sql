@@ -0,0 +1,45 @@
+-- name: CreateGroupInvitation :one
+-- Inviting the same email again issues a new token
+INSERT INTO group_invitations (group_id, email, name, role, invited_by, token)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (group_id, email)
+DO UPDATE SET
+    name = EXCLUDED.name,
+    role = EXCLUDED.role,
+    invited_by = EXCLUDED.invited_by,
+    token = EXCLUDED.token,
+    created_at = NOW(),
+    accepted_at = NULL
+RETURNING *;
+
+-- name: GetInvitationDetails :one
+SELECT
+    group_invitations.id,
+    group_invitations.email,
+    group_invitations.name,
+    group_invitations.accepted_at,
+    group_invitations.token,
+    groups.name AS group_name,
+    groups.invite_code,
+    COALESCE(users.username, '')::TEXT AS invited_by_username
+FROM group_invitations
+JOIN groups ON groups.id = group_invitations.group_id
+LEFT JOIN users ON users.id = group_invitations.invited_by
+WHERE group_invitations.id = $1;
+
+-- name: GetPendingInvitationByToken :one
+SELECT
+    group_invitations.*,
+    groups.name AS group_name
+FROM group_invitations
+JOIN groups ON groups.id = group_invitations.group_id
+WHERE group_invitations.token = $1
+AND group_invitations.accepted_at IS NULL
+AND groups.deleted_at IS NULL;
+
+-- name: MarkInvitationAccepted :execrows
+-- Only succeeds once, so a token can't be used twice
+UPDATE group_invitations
+SET accepted_at = NOW()
+WHERE id = $1
+AND accepted_at IS NULL;
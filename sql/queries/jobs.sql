@@ -0,0 +1,65 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (kind, payload, run_at, max_attempts)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ClaimJobs :many
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1, locked_at = NOW(),
+    locked_by = $1, updated_at = NOW()
+WHERE id IN (
+    SELECT id
+    FROM jobs
+    WHERE status = 'pending' AND run_at <= NOW()
+    ORDER BY run_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'done', locked_at = NULL, last_error = '', updated_at = NOW()
+WHERE id = $1;
+
+-- name: RetryJob :exec
+UPDATE jobs
+SET status = 'pending', run_at = $2, last_error = $3, locked_at = NULL,
+    locked_by = '', updated_at = NOW()
+WHERE id = $1;
+
+-- name: FailJob :exec
+UPDATE jobs
+SET status = 'failed', last_error = $2, locked_at = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RequeueStaleJobs :execrows
+UPDATE jobs
+SET status = 'pending', locked_at = NULL, locked_by = '', updated_at = NOW()
+WHERE status = 'running' AND locked_at < $1;
+
+-- name: DeleteFinishedJobs :execrows
+DELETE FROM jobs
+WHERE status IN ('done', 'failed') AND updated_at < $1;
+
+-- name: UpsertJobSchedule :exec
+INSERT INTO job_schedules (name, kind, cron_spec, next_run_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (name) DO UPDATE
+SET kind = EXCLUDED.kind,
+    cron_spec = EXCLUDED.cron_spec,
+    next_run_at = CASE
+        WHEN job_schedules.cron_spec = EXCLUDED.cron_spec THEN job_schedules.next_run_at
+        ELSE EXCLUDED.next_run_at
+    END;
+
+-- name: ClaimDueSchedules :many
+SELECT *
+FROM job_schedules
+WHERE next_run_at <= NOW()
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceJobSchedule :exec
+UPDATE job_schedules
+SET next_run_at = $2, last_run_at = NOW()
+WHERE name = $1;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- name: LogModeration :exec
+INSERT INTO moderation_log (group_id, actor_id, action, target_user_id, target_post_id, reason)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetModerationLog :many
+SELECT
+    moderation_log.id,
+    moderation_log.action,
+    moderation_log.actor_id,
+    COALESCE(actors.username, '')::TEXT AS actor_username,
+    moderation_log.target_user_id,
+    COALESCE(targets.username, '')::TEXT AS target_username,
+    moderation_log.target_post_id,
+    moderation_log.reason,
+    moderation_log.created_at
+FROM moderation_log
+LEFT JOIN users AS actors ON actors.id = moderation_log.actor_id
+LEFT JOIN users AS targets ON targets.id = moderation_log.target_user_id
+WHERE moderation_log.group_id = $1
+ORDER BY moderation_log.created_at DESC
+LIMIT $2 OFFSET $3;
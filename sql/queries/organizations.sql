@@ -0,0 +1,106 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (name, description, owner_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetOrganizationByID :one
+SELECT *
+FROM organizations
+WHERE id = $1;
+
+-- name: UpdateOrganization :one
+UPDATE organizations
+SET name = $2, description = $3, default_rules = $4, default_join_mode = $5,
+    default_post_policy = $6, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetOrganizationsForUser :many
+SELECT organizations.*
+FROM organization_members
+JOIN organizations ON organizations.id = organization_members.organization_id
+WHERE organization_members.user_id = $1
+ORDER BY organizations.name ASC;
+
+-- name: AddOrganizationMember :exec
+INSERT INTO organization_members (organization_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, user_id) DO UPDATE
+SET role = EXCLUDED.role;
+
+-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: GetOrganizationRole :one
+SELECT role
+FROM organization_members
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: GetOrganizationAdmins :many
+SELECT
+    users.id,
+    users.username,
+    users.email,
+    organization_members.role
+FROM organization_members
+JOIN users ON users.id = organization_members.user_id
+WHERE organization_members.organization_id = $1
+AND organization_members.role = 'admin'
+ORDER BY users.username ASC;
+
+-- name: IsOrgAdminForGroup :one
+SELECT EXISTS (
+    SELECT 1
+    FROM groups
+    JOIN organization_members
+        ON organization_members.organization_id = groups.organization_id
+    WHERE groups.id = $1
+    AND groups.deleted_at IS NULL
+    AND organization_members.user_id = $2
+    AND organization_members.role = 'admin'
+) AS is_org_admin;
+
+-- name: CreateOrganizationGroup :one
+INSERT INTO groups (name, description, owner_id, invite_code, organization_id,
+    rules_info, join_mode, post_policy)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: SetGroupOrganization :exec
+UPDATE groups
+SET organization_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetOrganizationGroups :many
+SELECT
+    groups.id,
+    groups.name,
+    groups.description,
+    groups.owner_id,
+    groups.join_mode,
+    groups.post_policy,
+    (
+        SELECT COUNT(*)
+        FROM users_groups
+        WHERE users_groups.group_id = groups.id
+        AND NOT users_groups.is_banned
+        AND NOT users_groups.is_kicked
+    ) AS member_count
+FROM groups
+WHERE groups.organization_id = $1
+AND groups.deleted_at IS NULL
+ORDER BY groups.name ASC;
+
+-- name: GetOrganizationMembershipSummary :one
+SELECT
+    COUNT(DISTINCT groups.id) AS group_count,
+    COUNT(users_groups.user_id) AS membership_count,
+    COUNT(DISTINCT users_groups.user_id) AS unique_member_count
+FROM groups
+LEFT JOIN users_groups
+    ON users_groups.group_id = groups.id
+    AND NOT users_groups.is_banned
+    AND NOT users_groups.is_kicked
+WHERE groups.organization_id = $1
+AND groups.deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- name: CreatePendingAction :one
+INSERT INTO pending_actions (group_id, action, target_user_id, requested_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetPendingActions :many
+SELECT
+    pending_actions.*,
+    requesters.username AS requested_by_username,
+    COALESCE(targets.username, '')::TEXT AS target_username
+FROM pending_actions
+JOIN users AS requesters ON requesters.id = pending_actions.requested_by
+LEFT JOIN users AS targets ON targets.id = pending_actions.target_user_id
+WHERE pending_actions.group_id = $1
+AND pending_actions.status = 'pending'
+AND pending_actions.expires_at > NOW()
+ORDER BY pending_actions.created_at ASC;
+
+-- name: GetPendingAction :one
+SELECT *
+FROM pending_actions
+WHERE id = $1 AND group_id = $2;
+
+-- name: ResolvePendingAction :one
+UPDATE pending_actions
+SET status = $3, resolved_by = $4, resolved_at = NOW()
+WHERE id = $1 AND group_id = $2
+AND status = 'pending' AND expires_at > NOW()
+RETURNING *;
+
+-- name: ExpirePendingActions :execrows
+UPDATE pending_actions
+SET status = 'expired', resolved_at = NOW()
+WHERE status = 'pending' AND expires_at <= NOW();
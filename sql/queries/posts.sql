@@ -1,11 +1,11 @@
 -- name: CreatePost :one
-INSERT INTO posts (user_id, group_id, content)
-VALUES ($1, $2, $3)
+INSERT INTO posts (user_id, group_id, content, category, is_anonymous, audience, publish_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: CreateComment :one
-INSERT INTO posts (user_id, group_id, content, parent_post_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO posts (user_id, group_id, content, parent_post_id, is_anonymous)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetPostByID :one
@@ -15,6 +15,16 @@ SELECT
     posts.user_id,
     posts.group_id,
     posts.created_at,
+    posts.edited_at,
+    posts.parent_post_id,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    posts.publish_at,
+    posts.archived_at,
     users.username
 FROM posts
 LEFT JOIN users ON posts.user_id = users.id
@@ -26,9 +36,17 @@ SELECT * FROM posts
 WHERE group_id = $1
 ORDER BY created_at DESC;
 
+-- name: GetPostContentForUpdate :one
+-- Locks the post so concurrent edits record their revisions one after another
+SELECT content
+FROM posts
+WHERE id = $1
+AND is_deleted = FALSE
+FOR UPDATE;
+
 -- name: UpdatePost :one
 UPDATE posts
-SET content = $1, updated_at = CURRENT_TIMESTAMP
+SET content = $1, edited_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
 WHERE id = $2
 AND is_deleted = FALSE
 RETURNING *;
@@ -51,45 +69,380 @@ SELECT
     posts.user_id,
     posts.group_id,
     posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    posts.archived_at,
     users.username,
-    COUNT(comments.id) AS comment_count
+    (posts.pinned_at IS NOT NULL AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW()))::BOOLEAN AS is_pinned,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count,
+    (
+        SELECT COUNT(DISTINCT prayer_responses.user_id)
+        FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+    )::BIGINT AS prayed_count,
+    EXISTS (
+        SELECT 1 FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+        AND prayer_responses.user_id = sqlc.arg(viewer_id)
+    )::BOOLEAN AS prayed_by_me
 FROM posts
 LEFT JOIN users ON posts.user_id = users.id
-LEFT JOIN posts AS comments
-    ON posts.id = comments.parent_post_id
-    AND comments.is_deleted = FALSE
-WHERE posts.group_id = $1
+WHERE posts.group_id = sqlc.arg(group_id)
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND (posts.archived_at IS NOT NULL) = sqlc.arg(archived)::BOOLEAN
+AND (posts.category = sqlc.arg(category) OR sqlc.arg(category) = '')
+AND (posts.status = sqlc.arg(status) OR sqlc.arg(status) = '')
+AND (
+    sqlc.narg(before_time)::TIMESTAMPTZ IS NULL
+    OR (posts.created_at, posts.id) < (sqlc.narg(before_time)::TIMESTAMPTZ, sqlc.narg(before_id)::UUID)
+)
+AND post_visible_to(posts.id, sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader))
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForFeedNewer :many
+-- Walks the feed towards newer posts from a cursor, oldest first
+SELECT
+    posts.id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    posts.archived_at,
+    users.username,
+    (posts.pinned_at IS NOT NULL AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW()))::BOOLEAN AS is_pinned,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count,
+    (
+        SELECT COUNT(DISTINCT prayer_responses.user_id)
+        FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+    )::BIGINT AS prayed_count,
+    EXISTS (
+        SELECT 1 FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+        AND prayer_responses.user_id = sqlc.arg(viewer_id)
+    )::BOOLEAN AS prayed_by_me
+FROM posts
+LEFT JOIN users ON posts.user_id = users.id
+WHERE posts.group_id = sqlc.arg(group_id)
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND (posts.archived_at IS NOT NULL) = sqlc.arg(archived)::BOOLEAN
+AND (posts.category = sqlc.arg(category) OR sqlc.arg(category) = '')
+AND (posts.status = sqlc.arg(status) OR sqlc.arg(status) = '')
+AND (posts.created_at, posts.id) > (sqlc.arg(after_time)::TIMESTAMPTZ, sqlc.arg(after_id)::UUID)
+AND post_visible_to(posts.id, sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader))
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetPinnedPosts :many
+SELECT
+    posts.id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.status,
+    posts.answered_at,
+    posts.answer_note,
+    posts.pinned_at,
+    posts.pinned_until,
+    posts.pin_order,
+    posts.is_anonymous,
+    posts.audience,
+    users.username,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count
+FROM posts
+LEFT JOIN users ON posts.user_id = users.id
+WHERE posts.group_id = sqlc.arg(group_id)
 AND posts.parent_post_id IS NULL
 AND posts.is_deleted = FALSE
-GROUP BY posts.id, posts.content, posts.user_id, posts.group_id, posts.created_at, users.username
-ORDER BY posts.created_at DESC
-LIMIT $2 OFFSET $3;
+AND posts.publish_at IS NULL
+AND posts.pinned_at IS NOT NULL
+AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW())
+AND post_visible_to(posts.id, sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader))
+ORDER BY posts.pin_order ASC, posts.pinned_at DESC;
+
+-- name: PinPost :execrows
+UPDATE posts
+SET pinned_at = NOW(), pinned_until = $3, pinned_by = $4, pin_order = $5
+WHERE id = $1 AND group_id = $2
+AND parent_post_id IS NULL
+AND is_deleted = FALSE
+AND publish_at IS NULL;
+
+-- name: UnpinPost :execrows
+UPDATE posts
+SET pinned_at = NULL, pinned_until = NULL, pinned_by = NULL, pin_order = 0
+WHERE id = $1 AND group_id = $2
+AND pinned_at IS NOT NULL;
+
+-- name: ExpirePins :execrows
+UPDATE posts
+SET pinned_at = NULL, pinned_until = NULL, pinned_by = NULL, pin_order = 0
+WHERE pinned_until <= NOW();
 
 -- name: ResetPosts :exec
 TRUNCATE TABLE posts CASCADE;
 
 -- name: DeleteCommentsFromPost :exec
+-- Replies to comments are removed along with the rest of the thread
+WITH RECURSIVE thread AS (
+    SELECT posts.id FROM posts
+    WHERE posts.parent_post_id = $1
+    UNION ALL
+    SELECT replies.id FROM posts AS replies
+    JOIN thread ON replies.parent_post_id = thread.id
+)
 UPDATE posts
 SET updated_at = CURRENT_TIMESTAMP, is_deleted = TRUE
-WHERE parent_post_id = $1;
+WHERE posts.id IN (SELECT thread.id FROM thread);
 
 -- name: GetCommentsByPostID :many
+-- Walks the reply tree under a post. Deepest replies come first so each one
+-- can be attached to its parent in a single pass.
+WITH RECURSIVE top_level AS (
+    -- Pages cover top-level comments, replies are always included
+    SELECT posts.id
+    FROM posts
+    WHERE posts.parent_post_id = sqlc.arg(post_id)::UUID
+    AND posts.is_deleted = FALSE
+    AND (
+        sqlc.narg(cursor_time)::TIMESTAMPTZ IS NULL
+        OR (
+            sqlc.arg(newer)::BOOLEAN
+            AND (posts.created_at, posts.id) > (sqlc.narg(cursor_time)::TIMESTAMPTZ, sqlc.narg(cursor_id)::UUID)
+        )
+        OR (
+            NOT sqlc.arg(newer)::BOOLEAN
+            AND (posts.created_at, posts.id) < (sqlc.narg(cursor_time)::TIMESTAMPTZ, sqlc.narg(cursor_id)::UUID)
+        )
+    )
+    ORDER BY
+        CASE WHEN sqlc.arg(newer)::BOOLEAN THEN posts.created_at END ASC,
+        CASE WHEN sqlc.arg(newer)::BOOLEAN THEN posts.id END ASC,
+        posts.created_at DESC,
+        posts.id DESC
+    LIMIT sqlc.narg('limit') OFFSET sqlc.arg('offset')
+),
+thread AS (
+    SELECT top_level.id, 1 AS depth
+    FROM top_level
+    UNION ALL
+    SELECT replies.id, thread.depth + 1
+    FROM posts AS replies
+    JOIN thread ON replies.parent_post_id = thread.id
+    WHERE replies.is_deleted = FALSE
+)
 SELECT
     posts.id,
+    posts.parent_post_id,
     posts.content,
     posts.user_id,
     posts.group_id,
     posts.created_at,
+    posts.edited_at,
+    posts.is_anonymous,
+    thread.depth::INTEGER AS depth,
+    (
+        SELECT COUNT(*) FROM posts AS replies
+        WHERE replies.parent_post_id = posts.id
+        AND replies.is_deleted = FALSE
+    )::BIGINT AS reply_count,
     users.username
-FROM posts
+FROM thread
+JOIN posts ON posts.id = thread.id
 LEFT JOIN users ON posts.user_id = users.id
-WHERE posts.parent_post_id = $1
-AND posts.is_deleted = FALSE
-ORDER BY posts.created_at DESC;
+WHERE post_visible_to(sqlc.arg(post_id)::UUID, sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader))
+ORDER BY thread.depth DESC, posts.created_at DESC, posts.id DESC;
+
+-- name: GetPostDepth :one
+-- Top-level posts are depth 0, comments on them depth 1 and so on
+WITH RECURSIVE ancestors AS (
+    SELECT posts.id, posts.parent_post_id, 0 AS depth
+    FROM posts
+    WHERE posts.id = $1
+    UNION ALL
+    SELECT parents.id, parents.parent_post_id, ancestors.depth + 1
+    FROM posts AS parents
+    JOIN ancestors ON parents.id = ancestors.parent_post_id
+)
+SELECT MAX(ancestors.depth)::INTEGER AS depth
+FROM ancestors;
 
 -- name: GetPostCountByGroupID :one
 SELECT COUNT(*) AS post_count
 FROM posts
-WHERE group_id = $1
+WHERE posts.group_id = sqlc.arg(group_id)
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND post_visible_to(posts.id, sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader));
+
+-- name: PurgeDeletedPosts :execrows
+DELETE FROM posts
+WHERE is_deleted = TRUE
+AND updated_at < $1;
+
+-- name: CreatePostRevision :exec
+INSERT INTO post_revisions (post_id, content, edited_by)
+VALUES ($1, $2, $3);
+
+-- name: GetPostRevisions :many
+SELECT
+    post_revisions.id,
+    post_revisions.content,
+    post_revisions.edited_by,
+    COALESCE(users.username, '')::TEXT AS editor,
+    post_revisions.created_at
+FROM post_revisions
+LEFT JOIN users ON users.id = post_revisions.edited_by
+WHERE post_revisions.post_id = $1
+ORDER BY post_revisions.created_at ASC;
+
+-- name: SetPostStatus :one
+-- answered_at keeps the first time a request was answered if the note is updated
+UPDATE posts
+SET status = sqlc.arg(status),
+    answer_note = sqlc.arg(answer_note),
+    answered_at = CASE
+        WHEN sqlc.arg(status) = 'answered' THEN COALESCE(answered_at, NOW())
+        ELSE NULL
+    END,
+    status_changed_at = NOW(),
+    status_changed_by = sqlc.arg(changed_by)
+WHERE id = sqlc.arg(id)
+AND group_id = sqlc.arg(group_id)
 AND parent_post_id IS NULL
-AND is_deleted = FALSE;
\ No newline at end of file
+AND is_deleted = FALSE
+RETURNING *;
+
+-- name: GetAnsweredPosts :many
+SELECT
+    posts.id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.answered_at,
+    posts.answer_note,
+    posts.is_anonymous,
+    posts.audience,
+    users.username,
+    (
+        SELECT COUNT(*) FROM comment_thread(posts.id)
+    )::BIGINT AS comment_count
+FROM posts
+LEFT JOIN users ON posts.user_id = users.id
+WHERE posts.group_id = sqlc.arg(group_id)
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.status = 'answered'
+AND posts.publish_at IS NULL
+AND post_visible_to(posts.id, sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader))
+ORDER BY posts.answered_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: AddPostAudienceMembers :execrows
+-- Only current members of the group are added, callers compare the row count
+INSERT INTO post_audience (post_id, user_id)
+SELECT sqlc.arg(post_id), users_groups.user_id
+FROM users_groups
+WHERE users_groups.group_id = sqlc.arg(group_id)
+AND users_groups.user_id = ANY(sqlc.arg(user_ids)::UUID[]);
+
+-- name: IsPostVisibleTo :one
+SELECT post_visible_to(sqlc.arg(post_id), sqlc.arg(viewer_id), sqlc.arg(viewer_is_leader))::BOOLEAN AS visible;
+
+-- name: GetPostAudience :many
+SELECT user_id
+FROM post_audience
+WHERE post_id = $1
+ORDER BY user_id;
+
+-- name: GetScheduledPosts :many
+SELECT
+    posts.id,
+    posts.content,
+    posts.user_id,
+    posts.group_id,
+    posts.created_at,
+    posts.edited_at,
+    posts.category,
+    posts.is_anonymous,
+    posts.audience,
+    posts.publish_at,
+    users.username
+FROM posts
+LEFT JOIN users ON posts.user_id = users.id
+WHERE posts.group_id = $1
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NOT NULL
+ORDER BY posts.publish_at ASC;
+
+-- name: PublishDuePosts :many
+-- created_at moves to the moment of publishing so the post lands at the top
+-- of the feed. Using publish_at would back-date late runs behind cursors
+-- clients already hold, and they would never see the post.
+UPDATE posts
+SET created_at = NOW(), publish_at = NULL, updated_at = NOW()
+WHERE publish_at <= NOW()
+AND is_deleted = FALSE
+RETURNING *;
+
+-- name: ArchiveStalePosts :execrows
+-- A post is stale once nothing has happened on it for the group's auto_archive_days
+UPDATE posts
+SET archived_at = NOW()
+FROM groups
+WHERE groups.id = posts.group_id
+AND groups.auto_archive_days > 0
+AND posts.parent_post_id IS NULL
+AND posts.is_deleted = FALSE
+AND posts.publish_at IS NULL
+AND posts.archived_at IS NULL
+AND NOT (posts.pinned_at IS NOT NULL AND (posts.pinned_until IS NULL OR posts.pinned_until > NOW()))
+AND GREATEST(
+    posts.created_at,
+    posts.edited_at,
+    posts.status_changed_at,
+    (
+        SELECT MAX(thread.created_at) FROM comment_thread(posts.id) AS thread
+    ),
+    (
+        SELECT MAX(prayer_responses.created_at) FROM prayer_responses
+        WHERE prayer_responses.post_id = posts.id
+    )
+) < NOW() - make_interval(days => groups.auto_archive_days);
+
+-- name: UnarchivePost :exec
+UPDATE posts
+SET archived_at = NULL
+WHERE id = $1
+AND archived_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- name: RecordPrayerResponse :execrows
+-- One response per user per post each day, praying again tomorrow adds a row
+INSERT INTO prayer_responses (post_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: WithdrawPrayerResponses :execrows
+DELETE FROM prayer_responses
+WHERE post_id = $1 AND user_id = $2;
+
+-- name: GetPrayerSummary :one
+SELECT
+    COUNT(DISTINCT user_id)::BIGINT AS prayed_count,
+    COUNT(*)::BIGINT AS prayer_count,
+    COALESCE(BOOL_OR(user_id = sqlc.arg(viewer_id)), FALSE)::BOOLEAN AS prayed_by_me
+FROM prayer_responses
+WHERE post_id = sqlc.arg(post_id);
+
+-- name: GetPrayerRoster :many
+SELECT
+    prayer_responses.user_id,
+    COALESCE(users.username, '')::TEXT AS username,
+    COUNT(*)::BIGINT AS times_prayed,
+    MAX(prayer_responses.created_at)::TIMESTAMPTZ AS last_prayed_at
+FROM prayer_responses
+LEFT JOIN users ON users.id = prayer_responses.user_id
+WHERE prayer_responses.post_id = $1
+GROUP BY prayer_responses.user_id, users.username
+ORDER BY last_prayed_at DESC;
\ No newline at end of file
@@ -11,4 +11,9 @@ AND revoked_at IS NULL;
 -- name: RevokeUserToken :exec
 UPDATE refresh_tokens
 SET revoked_at = NOW()
-WHERE token = $1;
\ No newline at end of file
+WHERE token = $1;
+
+-- name: DeleteExpiredTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at <= NOW()
+OR revoked_at IS NOT NULL;
\ No newline at end of file
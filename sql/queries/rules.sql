@@ -0,0 +1,62 @@
+-- name: LockGroupRules :exec
+-- Serializes rules saves for a group so each one gets the next version number
+SELECT id FROM groups
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CreateRulesVersion :one
+INSERT INTO group_rules_versions (group_id, version, rules, created_by)
+SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3
+FROM group_rules_versions
+WHERE group_id = $1
+RETURNING *;
+
+-- name: GetRulesVersions :many
+SELECT
+    group_rules_versions.id,
+    group_rules_versions.version,
+    group_rules_versions.rules,
+    group_rules_versions.created_at,
+    COALESCE(users.username, '')::TEXT AS created_by_username
+FROM group_rules_versions
+LEFT JOIN users ON users.id = group_rules_versions.created_by
+WHERE group_rules_versions.group_id = $1
+ORDER BY group_rules_versions.version DESC;
+
+-- name: GetRulesVersion :one
+SELECT *
+FROM group_rules_versions
+WHERE group_id = $1 AND version = $2;
+
+-- name: GetLatestRulesVersion :one
+SELECT *
+FROM group_rules_versions
+WHERE group_id = $1
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: AcceptRules :exec
+INSERT INTO group_rules_acceptances (group_id, user_id, version)
+VALUES ($1, $2, $3)
+ON CONFLICT (group_id, user_id)
+DO UPDATE SET version = EXCLUDED.version, accepted_at = NOW();
+
+-- name: GetAcceptedRulesVersion :one
+SELECT version
+FROM group_rules_acceptances
+WHERE group_id = $1 AND user_id = $2;
+
+-- name: GetRulesAcceptances :many
+SELECT
+    users.id,
+    users.username,
+    COALESCE(group_rules_acceptances.version, 0)::INTEGER AS accepted_version,
+    group_rules_acceptances.accepted_at
+FROM users_groups
+JOIN users ON users.id = users_groups.user_id
+LEFT JOIN group_rules_acceptances
+    ON group_rules_acceptances.group_id = users_groups.group_id
+    AND group_rules_acceptances.user_id = users_groups.user_id
+WHERE users_groups.group_id = $1
+AND NOT users_groups.is_banned
+ORDER BY users.username ASC;
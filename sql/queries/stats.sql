@@ -0,0 +1,82 @@
+-- name: GetGroupWeeklyActivity :many
+WITH weeks AS (
+    SELECT generate_series(
+        date_trunc('week', sqlc.arg(since)::TIMESTAMPTZ),
+        date_trunc('week', NOW()),
+        INTERVAL '1 week'
+    ) AS week_start
+),
+activity AS (
+    SELECT
+        date_trunc('week', posts.created_at) AS week_start,
+        COUNT(*) FILTER (WHERE posts.parent_post_id IS NULL) AS posts,
+        COUNT(*) FILTER (WHERE posts.parent_post_id IS NOT NULL) AS comments,
+        COUNT(DISTINCT posts.user_id) AS active_members
+    FROM posts
+    WHERE posts.group_id = sqlc.arg(group_id)
+    AND posts.is_deleted = FALSE
+    AND posts.publish_at IS NULL
+    AND posts.created_at >= date_trunc('week', sqlc.arg(since)::TIMESTAMPTZ)
+    GROUP BY 1
+),
+joins AS (
+    SELECT date_trunc('week', users_groups.joined_at) AS week_start, COUNT(*) AS new_joins
+    FROM users_groups
+    WHERE users_groups.group_id = sqlc.arg(group_id)
+    AND users_groups.joined_at >= date_trunc('week', sqlc.arg(since)::TIMESTAMPTZ)
+    GROUP BY 1
+)
+SELECT
+    weeks.week_start::TIMESTAMPTZ AS week_start,
+    COALESCE(activity.posts, 0)::BIGINT AS posts,
+    COALESCE(activity.comments, 0)::BIGINT AS comments,
+    COALESCE(activity.active_members, 0)::BIGINT AS active_members,
+    COALESCE(joins.new_joins, 0)::BIGINT AS new_joins
+FROM weeks
+LEFT JOIN activity ON activity.week_start = weeks.week_start
+LEFT JOIN joins ON joins.week_start = weeks.week_start
+ORDER BY weeks.week_start ASC;
+
+-- name: GetGroupMemberActivity :one
+SELECT
+    COUNT(*)::BIGINT AS members,
+    COUNT(*) FILTER (WHERE EXISTS (
+        SELECT 1 FROM posts
+        WHERE posts.group_id = users_groups.group_id
+        AND posts.user_id = users_groups.user_id
+        AND posts.is_deleted = FALSE
+        AND posts.created_at >= sqlc.arg(active_since)::TIMESTAMPTZ
+    ))::BIGINT AS active_members
+FROM users_groups
+WHERE users_groups.group_id = sqlc.arg(group_id)
+AND NOT users_groups.is_banned
+AND (NOT users_groups.is_kicked OR users_groups.kicked_until <= NOW());
+
+-- name: GetGroupResponseStats :one
+-- Time to first comment only counts comments from someone other than the author
+WITH requests AS (
+    SELECT
+        posts.id,
+        posts.status,
+        (
+            SELECT MIN(comments.created_at)
+            FROM posts AS comments
+            WHERE comments.parent_post_id = posts.id
+            AND comments.user_id <> posts.user_id
+            AND comments.is_deleted = FALSE
+        ) - posts.created_at AS time_to_first_comment
+    FROM posts
+    WHERE posts.group_id = sqlc.arg(group_id)
+    AND posts.parent_post_id IS NULL
+    AND posts.is_deleted = FALSE
+    AND posts.publish_at IS NULL
+    AND posts.created_at >= date_trunc('week', sqlc.arg(since)::TIMESTAMPTZ)
+)
+SELECT
+    COUNT(*)::BIGINT AS requests,
+    COUNT(*) FILTER (WHERE status = 'answered')::BIGINT AS answered,
+    COALESCE(
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM time_to_first_comment)),
+        0
+    )::FLOAT8 AS median_seconds_to_first_comment
+FROM requests;
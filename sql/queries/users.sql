@@ -36,11 +36,23 @@ SET username = $1
 WHERE id = $2;
 
 -- name: GetUserGroupRole :one
-SELECT role
+SELECT users_groups.role
 FROM users_groups
-WHERE user_id = $1 AND group_id = $2;
+JOIN groups ON groups.id = users_groups.group_id
+WHERE users_groups.user_id = $1 AND users_groups.group_id = $2
+AND groups.deleted_at IS NULL;
 
 -- name: AdjustUserGroupRole :exec
 UPDATE users_groups
 SET role = $1
-WHERE user_id = $2 AND group_id = $3;
\ No newline at end of file
+WHERE user_id = $2 AND group_id = $3;
+
+-- name: GetPrivacySettings :one
+SELECT email_visibility, hide_from_directory
+FROM users
+WHERE id = $1;
+
+-- name: UpdatePrivacySettings :exec
+UPDATE users
+SET email_visibility = $2, hide_from_directory = $3, updated_at = NOW()
+WHERE id = $1;
\ No newline at end of file
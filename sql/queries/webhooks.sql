@@ -0,0 +1,72 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (group_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebhooks :many
+SELECT *
+FROM webhooks
+WHERE group_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetWebhook :one
+SELECT *
+FROM webhooks
+WHERE id = $1 AND group_id = $2;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks
+SET url = $3, events = $4, is_active = $5, updated_at = NOW()
+WHERE id = $1 AND group_id = $2
+RETURNING *;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND group_id = $2;
+
+-- name: GetWebhooksForEvent :many
+SELECT id
+FROM webhooks
+WHERE group_id = $1
+AND is_active
+AND sqlc.arg(event)::TEXT = ANY(events);
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetWebhookDelivery :one
+SELECT *
+FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2;
+
+-- name: GetDeliveryForSend :one
+SELECT
+    webhook_deliveries.id,
+    webhook_deliveries.event,
+    webhook_deliveries.payload,
+    webhook_deliveries.attempts,
+    webhooks.url,
+    webhooks.secret,
+    webhooks.is_active
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+WHERE webhook_deliveries.id = $1;
+
+-- name: RecordWebhookAttempt :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    response_body = $4,
+    last_error = $5,
+    delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END
+WHERE id = $1;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE users_groups
+ADD COLUMN is_muted BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN muted_until TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE users_groups
+DROP COLUMN is_muted,
+DROP COLUMN muted_until;
\ No newline at end of file
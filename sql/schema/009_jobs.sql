@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    locked_by TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX jobs_pending_run_at_idx ON jobs (run_at) WHERE status = 'pending';
+
+CREATE TABLE job_schedules (
+    name TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    cron_spec TEXT NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+-- Comments on purged posts are removed along with them
+ALTER TABLE posts
+DROP CONSTRAINT posts_parent_post_id_fkey,
+ADD CONSTRAINT posts_parent_post_id_fkey
+    FOREIGN KEY (parent_post_id) REFERENCES posts(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE posts
+DROP CONSTRAINT posts_parent_post_id_fkey,
+ADD CONSTRAINT posts_parent_post_id_fkey
+    FOREIGN KEY (parent_post_id) REFERENCES posts(id);
+
+DROP TABLE job_schedules;
+DROP TABLE jobs;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE groups
+ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '',
+ADD COLUMN post_policy TEXT NOT NULL DEFAULT 'everyone',
+ADD COLUMN join_mode TEXT NOT NULL DEFAULT 'open';
+
+CREATE TABLE group_join_requests (
+    group_id UUID REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE group_join_requests;
+
+ALTER TABLE groups
+DROP COLUMN avatar_url,
+DROP COLUMN post_policy,
+DROP COLUMN join_mode;
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE groups
+ADD COLUMN is_listed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX groups_listed_search_idx ON groups
+USING GIN (to_tsvector('english', name || ' ' || COALESCE(description, '')))
+WHERE is_listed;
+
+-- +goose Down
+DROP INDEX groups_listed_search_idx;
+
+ALTER TABLE groups
+DROP COLUMN is_listed;
\ No newline at end of file
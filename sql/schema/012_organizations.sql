@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    default_rules TEXT NOT NULL DEFAULT '',
+    default_join_mode TEXT NOT NULL DEFAULT 'open',
+    default_post_policy TEXT NOT NULL DEFAULT 'everyone',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE organization_members (
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'admin',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+ALTER TABLE groups
+ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX groups_organization_id_idx ON groups (organization_id);
+
+-- +goose Down
+ALTER TABLE groups
+DROP COLUMN organization_id;
+
+DROP TABLE organization_members;
+DROP TABLE organizations;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE groups
+ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+ADD COLUMN deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX groups_deleted_at_idx ON groups (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX groups_deleted_at_idx;
+
+ALTER TABLE groups
+DROP COLUMN deleted_by,
+DROP COLUMN deleted_at;
\ No newline at end of file
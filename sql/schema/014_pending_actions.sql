@@ -0,0 +1,30 @@
+-- +goose Up
+ALTER TABLE groups
+ADD COLUMN require_second_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE pending_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+-- Only one open request per action and target
+CREATE UNIQUE INDEX pending_actions_open_idx ON pending_actions (
+    group_id,
+    action,
+    COALESCE(target_user_id, '00000000-0000-0000-0000-000000000000'::UUID)
+)
+WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE pending_actions;
+
+ALTER TABLE groups
+DROP COLUMN require_second_approval;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN email_visibility TEXT NOT NULL DEFAULT 'members',
+ADD COLUMN hide_from_directory BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN hide_from_directory,
+DROP COLUMN email_visibility;
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- +goose Up
+ALTER TABLE groups
+ADD COLUMN require_rules_acceptance BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE group_rules_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    rules TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(group_id, version)
+);
+
+-- Latest rules version each member has accepted
+CREATE TABLE group_rules_acceptances (
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+-- Existing rules become version 1
+INSERT INTO group_rules_versions (group_id, version, rules, created_by)
+SELECT id, 1, rules_info, owner_id
+FROM groups
+WHERE rules_info <> '';
+
+-- +goose Down
+DROP TABLE group_rules_acceptances;
+DROP TABLE group_rules_versions;
+
+ALTER TABLE groups
+DROP COLUMN require_rules_acceptance;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN pinned_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+ADD COLUMN pinned_until TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+ADD COLUMN pinned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN pin_order INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX posts_pinned_idx ON posts (group_id, pin_order)
+WHERE pinned_at IS NOT NULL;
+
+CREATE TABLE moderation_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    target_post_id UUID REFERENCES posts(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX moderation_log_group_idx ON moderation_log (group_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE moderation_log;
+
+DROP INDEX posts_pinned_idx;
+
+ALTER TABLE posts
+DROP COLUMN pin_order,
+DROP COLUMN pinned_by,
+DROP COLUMN pinned_until,
+DROP COLUMN pinned_at;
\ No newline at end of file
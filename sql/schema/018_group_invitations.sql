@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE group_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'member',
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    UNIQUE(group_id, email)
+);
+
+CREATE INDEX group_invitations_email_idx ON group_invitations (email)
+WHERE accepted_at IS NULL;
+
+-- +goose Down
+DROP TABLE group_invitations;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Existing memberships have no known join date, so the default is only set
+-- after the column exists to leave their rows NULL without rewriting them
+ALTER TABLE users_groups
+ADD COLUMN joined_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE users_groups
+ALTER COLUMN joined_at SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX posts_group_created_idx ON posts (group_id, created_at);
+CREATE INDEX posts_parent_created_idx ON posts (parent_post_id, created_at)
+WHERE parent_post_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX posts_parent_created_idx;
+DROP INDEX posts_group_created_idx;
+
+ALTER TABLE users_groups
+DROP COLUMN joined_at;
\ No newline at end of file
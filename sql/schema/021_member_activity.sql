@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE users_groups
+ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- Start from each member's latest post or comment
+UPDATE users_groups
+SET last_seen_at = (
+    SELECT MAX(posts.created_at)
+    FROM posts
+    WHERE posts.user_id = users_groups.user_id
+    AND posts.group_id = users_groups.group_id
+);
+
+CREATE INDEX users_groups_last_seen_idx ON users_groups (group_id, last_seen_at);
+
+-- +goose Down
+DROP INDEX users_groups_last_seen_idx;
+
+ALTER TABLE users_groups
+DROP COLUMN last_seen_at;
\ No newline at end of file
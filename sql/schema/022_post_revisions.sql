@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE groups
+ADD COLUMN edit_window_minutes INTEGER NOT NULL DEFAULT 1440;
+
+ALTER TABLE posts
+ADD COLUMN edited_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+CREATE TABLE post_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX post_revisions_post_idx ON post_revisions (post_id, created_at);
+
+-- +goose Down
+DROP TABLE post_revisions;
+
+ALTER TABLE posts
+DROP COLUMN edited_at;
+
+ALTER TABLE groups
+DROP COLUMN edit_window_minutes;
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN category TEXT NOT NULL DEFAULT 'prayer_request';
+
+CREATE INDEX posts_group_category_idx ON posts (group_id, category)
+WHERE parent_post_id IS NULL;
+
+CREATE TABLE group_categories (
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, name)
+);
+
+-- +goose Down
+DROP TABLE group_categories;
+
+DROP INDEX posts_group_category_idx;
+
+ALTER TABLE posts
+DROP COLUMN category;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN status TEXT NOT NULL DEFAULT 'open',
+ADD COLUMN status_changed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+ADD COLUMN status_changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN answered_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+ADD COLUMN answer_note TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX posts_group_status_idx ON posts (group_id, status)
+WHERE parent_post_id IS NULL;
+
+-- +goose Down
+DROP INDEX posts_group_status_idx;
+
+ALTER TABLE posts
+DROP COLUMN answer_note,
+DROP COLUMN answered_at,
+DROP COLUMN status_changed_by,
+DROP COLUMN status_changed_at,
+DROP COLUMN status;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE prayer_responses (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    prayed_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id, prayed_on)
+);
+
+-- +goose Down
+DROP TABLE prayer_responses;
\ No newline at end of file
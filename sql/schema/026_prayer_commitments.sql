@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE prayer_commitments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    frequency TEXT NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_reminder_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_reminded_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    end_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One active commitment per member and request, ended ones are kept as history
+CREATE UNIQUE INDEX prayer_commitments_active_idx ON prayer_commitments (post_id, user_id) WHERE ended_at IS NULL;
+CREATE INDEX prayer_commitments_due_idx ON prayer_commitments (next_reminder_at) WHERE ended_at IS NULL;
+CREATE INDEX prayer_commitments_user_idx ON prayer_commitments (user_id) WHERE ended_at IS NULL;
+
+-- +goose Down
+DROP TABLE prayer_commitments;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE groups ADD COLUMN allow_anonymous BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE posts ADD COLUMN is_anonymous BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN is_anonymous;
+ALTER TABLE groups DROP COLUMN allow_anonymous;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+-- 'members' is everyone in the group, 'leaders' is group admins only and
+-- 'custom' is the members listed in post_audience. Authors and admins always
+-- see their posts.
+ALTER TABLE posts ADD COLUMN audience TEXT NOT NULL DEFAULT 'members';
+
+CREATE TABLE post_audience (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, user_id)
+);
+
+CREATE INDEX post_audience_user_idx ON post_audience (user_id);
+
+-- +goose Down
+DROP TABLE post_audience;
+ALTER TABLE posts DROP COLUMN audience;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+-- publish_at is set while a post is waiting to be published and cleared once it is
+ALTER TABLE posts ADD COLUMN publish_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+ALTER TABLE posts ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- 0 turns automatic archiving off
+ALTER TABLE groups ADD COLUMN auto_archive_days INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX posts_publish_due_idx ON posts (publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX posts_group_archived_idx ON posts (group_id, archived_at) WHERE parent_post_id IS NULL;
+
+-- +goose Down
+DROP INDEX posts_group_archived_idx;
+DROP INDEX posts_publish_due_idx;
+ALTER TABLE groups DROP COLUMN auto_archive_days;
+ALTER TABLE posts DROP COLUMN archived_at;
+ALTER TABLE posts DROP COLUMN publish_at;
\ No newline at end of file
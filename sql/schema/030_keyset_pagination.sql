@@ -0,0 +1,10 @@
+-- +goose Up
+-- Keyset pagination walks (created_at, id), newest first
+CREATE INDEX posts_feed_keyset_idx ON posts (group_id, created_at DESC, id DESC)
+WHERE parent_post_id IS NULL AND is_deleted = FALSE;
+CREATE INDEX posts_comments_keyset_idx ON posts (parent_post_id, created_at DESC, id DESC)
+WHERE parent_post_id IS NOT NULL AND is_deleted = FALSE;
+
+-- +goose Down
+DROP INDEX posts_comments_keyset_idx;
+DROP INDEX posts_feed_keyset_idx;
\ No newline at end of file
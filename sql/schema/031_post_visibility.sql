@@ -0,0 +1,57 @@
+-- +goose Up
+-- Leaders are group admins and admins of the group's organization
+-- +goose StatementBegin
+CREATE FUNCTION is_group_leader(leader_user_id UUID, leader_group_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM users_groups
+        WHERE users_groups.user_id = leader_user_id
+        AND users_groups.group_id = leader_group_id
+        AND users_groups.role = 'admin'
+    ) OR EXISTS (
+        SELECT 1 FROM organization_members
+        JOIN groups ON groups.organization_id = organization_members.organization_id
+        WHERE groups.id = leader_group_id
+        AND organization_members.user_id = leader_user_id
+        AND organization_members.role = 'admin'
+    )
+$$;
+-- +goose StatementEnd
+
+-- Every read path checks posts against this so the audience rules live in
+-- one place. Pass the top-level post for comments. Authors and leaders see
+-- everything, others only published posts whose audience includes them.
+-- Group membership is checked by the caller.
+-- +goose StatementBegin
+CREATE FUNCTION post_visible_to(visible_post_id UUID, visible_viewer_id UUID, visible_is_leader BOOLEAN)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT COALESCE((
+        SELECT visible_is_leader
+            OR posts.user_id = visible_viewer_id
+            OR (
+                posts.publish_at IS NULL
+                AND (
+                    posts.audience = 'members'
+                    OR (
+                        posts.audience = 'custom'
+                        AND EXISTS (
+                            SELECT 1 FROM post_audience
+                            WHERE post_audience.post_id = posts.id
+                            AND post_audience.user_id = visible_viewer_id
+                        )
+                    )
+                )
+            )
+        FROM posts
+        WHERE posts.id = visible_post_id
+    ), FALSE)
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION post_visible_to(UUID, UUID, BOOLEAN);
+DROP FUNCTION is_group_leader(UUID, UUID);
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Invitations are accepted with the token from the email rather than by
+-- registering the invited address, which nobody verifies
+ALTER TABLE group_invitations ADD COLUMN token TEXT;
+UPDATE group_invitations SET token = replace(gen_random_uuid()::TEXT || gen_random_uuid()::TEXT, '-', '');
+ALTER TABLE group_invitations ALTER COLUMN token SET NOT NULL;
+CREATE UNIQUE INDEX group_invitations_token_idx ON group_invitations (token);
+
+-- Imported members can only be invited as members
+UPDATE group_invitations SET role = 'member' WHERE role <> 'member';
+
+-- +goose Down
+DROP INDEX group_invitations_token_idx;
+ALTER TABLE group_invitations DROP COLUMN token;
\ No newline at end of file
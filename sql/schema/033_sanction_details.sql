@@ -0,0 +1,41 @@
+-- +goose Up
+-- Kicks, mutes and bans each keep their own reason and moderator so lifting
+-- one doesn't wipe the details of another that is still in force
+ALTER TABLE users_groups
+ADD COLUMN kick_reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN kicked_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+ADD COLUMN kicked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN mute_reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN muted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+ADD COLUMN muted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- The shared columns can't say which sanction they describe, so copy them
+-- to every sanction currently in force
+UPDATE users_groups
+SET kick_reason = modded_reason, kicked_at = modded_at, kicked_by = modded_by
+WHERE is_kicked;
+
+UPDATE users_groups
+SET mute_reason = modded_reason, muted_at = modded_at, muted_by = modded_by
+WHERE is_muted;
+
+ALTER TABLE users_groups RENAME COLUMN modded_reason TO ban_reason;
+ALTER TABLE users_groups RENAME COLUMN modded_at TO banned_at;
+ALTER TABLE users_groups RENAME COLUMN modded_by TO banned_by;
+
+UPDATE users_groups
+SET ban_reason = '', banned_at = NULL, banned_by = NULL
+WHERE NOT is_banned;
+
+-- +goose Down
+ALTER TABLE users_groups RENAME COLUMN ban_reason TO modded_reason;
+ALTER TABLE users_groups RENAME COLUMN banned_at TO modded_at;
+ALTER TABLE users_groups RENAME COLUMN banned_by TO modded_by;
+
+ALTER TABLE users_groups
+DROP COLUMN kick_reason,
+DROP COLUMN kicked_at,
+DROP COLUMN kicked_by,
+DROP COLUMN mute_reason,
+DROP COLUMN muted_at,
+DROP COLUMN muted_by;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Every comment and nested reply under a post that is still visible. A
+-- deleted comment hides its replies too, matching how threads are shown.
+-- +goose StatementBegin
+CREATE FUNCTION comment_thread(thread_root_id UUID)
+RETURNS TABLE (id UUID, created_at TIMESTAMP WITH TIME ZONE)
+LANGUAGE sql STABLE
+AS $$
+    WITH RECURSIVE thread AS (
+        SELECT posts.id, posts.created_at
+        FROM posts
+        WHERE posts.parent_post_id = thread_root_id
+        AND posts.is_deleted = FALSE
+        UNION ALL
+        SELECT replies.id, replies.created_at
+        FROM posts AS replies
+        JOIN thread ON replies.parent_post_id = thread.id
+        WHERE replies.is_deleted = FALSE
+    )
+    SELECT thread.id, thread.created_at FROM thread
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION comment_thread(UUID);
\ No newline at end of file
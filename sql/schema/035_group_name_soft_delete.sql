@@ -0,0 +1,14 @@
+-- +goose Up
+-- Deleted groups no longer reserve their name during the restore window,
+-- restoring one checks the name is still free
+ALTER TABLE groups
+DROP CONSTRAINT groups_name_owner_id_key;
+
+CREATE UNIQUE INDEX groups_name_owner_id_key ON groups (name, owner_id)
+WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX groups_name_owner_id_key;
+
+ALTER TABLE groups
+ADD CONSTRAINT groups_name_owner_id_key UNIQUE (name, owner_id);
\ No newline at end of file